@@ -0,0 +1,167 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/irfansharif/solver/internal"
+)
+
+// ResourceUsage captures the resources consumed by a solve so far, as reported
+// through WithResourceAccounting.
+type ResourceUsage struct {
+	// WallTime is the time elapsed since the solve started.
+	WallTime time.Duration
+	// Solutions is the number of solutions found so far.
+	Solutions int64
+	// Memory is the resident memory of the process, in bytes, solver
+	// included. It's zero where it can't be determined (outside of Linux).
+	Memory uint64
+}
+
+// WithResourceAccounting configures the solver to report its resource usage
+// to the given function, every interval and whenever a solution is found. If
+// the function returns false, the search is stopped; the result is that of
+// the search so far (like with WithTimeout). If the interval is zero, usage is
+// only reported when solutions are found.
+//
+// The function is never invoked concurrently, and never once the solve is
+// complete. Memory is reported for the process as a whole; the memory used by
+// the solver can't be attributed to individual solves, so with concurrent
+// solves, quotas on it are shared. Deterministic time isn't reported at all:
+// the solver doesn't expose it mid-search. To enforce quotas on it, use
+// WithDeterministicTimeLimit instead.
+func WithResourceAccounting(interval time.Duration, f func(ResourceUsage) bool) Option {
+	return func(o *options, s internal.SolveWrapper) {
+		a := &accountant{interval: interval, f: f}
+		a.callback = &solutionCallback{f: a.onSolution}
+		a.callback.hook = internal.NewDirectorSolutionCallback(a.callback)
+		s.AddSolutionCallback(a.callback.hook)
+		o.accountant = a
+	}
+}
+
+// WithMemoryLimit configures the solver to stop once it uses more than the
+// given amount of memory, in megabytes. The limit applies to the process as a
+// whole, not just the solve; the solver can't tell them apart.
+func WithMemoryLimit(mb int64) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.params.MaxMemoryInMb = &mb
+	}
+}
+
+// accountant reports resource usage during a solve; see
+// WithResourceAccounting.
+type accountant struct {
+	interval time.Duration
+	f        func(ResourceUsage) bool
+	callback *solutionCallback
+
+	started time.Time
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	mu struct {
+		sync.Mutex
+
+		solver    internal.SolveWrapper
+		solutions int64
+	}
+}
+
+// start is called by the solve process with the underlying solver before
+// solving. The returned function is to be called once solving is done, before
+// the underlying solver is freed.
+func (a *accountant) start(s internal.SolveWrapper) (stop func()) {
+	a.started = time.Now()
+	a.done = make(chan struct{})
+	a.mu.solver = s
+	if a.interval > 0 {
+		a.wg.Add(1)
+		go a.poll()
+	}
+
+	return func() {
+		close(a.done)
+		a.wg.Wait()
+
+		a.mu.Lock()
+		a.mu.solver = nil
+		a.mu.Unlock()
+		internal.DeleteDirectorSolutionCallback(a.callback.hook)
+	}
+}
+
+func (a *accountant) poll() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-ticker.C:
+			a.report()
+		}
+	}
+}
+
+func (a *accountant) onSolution(Result) {
+	a.mu.Lock()
+	a.mu.solutions++
+	a.mu.Unlock()
+	a.report()
+}
+
+// report reports the current usage, stopping the search if asked to.
+func (a *accountant) report() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mu.solver == nil {
+		return // the solve is complete
+	}
+	usage := ResourceUsage{
+		WallTime:  time.Since(a.started),
+		Solutions: a.mu.solutions,
+		Memory:    residentMemory(),
+	}
+	if !a.f(usage) {
+		a.mu.solver.StopSearch()
+	}
+}
+
+// residentMemory returns the resident memory of the process, in bytes, or zero
+// if it can't be determined.
+func residentMemory() uint64 {
+	statm, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
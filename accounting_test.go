@@ -0,0 +1,70 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAccounting(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewAllDifferentConstraint(x, y))
+
+	var usages []ResourceUsage
+	result := model.Solve(WithResourceAccounting(time.Hour, func(u ResourceUsage) bool {
+		usages = append(usages, u)
+		return true
+	}))
+	require.True(t, result.Optimal())
+	require.Len(t, usages, 1) // reported once, for the only solution found
+	require.Equal(t, int64(1), usages[0].Solutions)
+	require.True(t, usages[0].WallTime >= 0)
+	if runtime.GOOS == "linux" {
+		require.NotZero(t, usages[0].Memory)
+	}
+}
+
+func TestResidentMemory(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		require.Zero(t, residentMemory())
+		return
+	}
+	require.NotZero(t, residentMemory())
+}
+
+func TestResourceAccountingQuota(t *testing.T) {
+	model := NewModel("")
+	var vars []IntVar
+	for i := 0; i < 20; i++ {
+		vars = append(vars, model.NewIntVar(0, 100, ""))
+	}
+	model.AddConstraints(NewAllDifferentConstraint(vars...))
+	model.Maximize(Sum(vars...))
+
+	// Stop at the first solution found.
+	result := model.Solve(
+		WithParallelism(1),
+		WithResourceAccounting(0, func(u ResourceUsage) bool {
+			return u.Solutions < 1
+		}),
+	)
+	require.True(t, result.solved())
+}
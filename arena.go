@@ -0,0 +1,87 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "github.com/irfansharif/solver/pb"
+
+// defaultArenaSlabSize is the number of protos allocated at a time by an arena.
+const defaultArenaSlabSize = 1 << 10
+
+// protoArena batch-allocates the constraint protos that make up a model. It
+// trades individual allocations for fewer, larger ones, reducing GC pressure
+// when building models with millions of constraints. Since the protos are
+// retained by the model anyway, we don't lose out on much by having them
+// share the lifetime of the slab they were allocated from.
+//
+// A nil arena is valid to use; it simply allocates protos individually.
+type protoArena struct {
+	slabSize int
+
+	constraints      []pb.ConstraintProto
+	integerArguments []pb.IntegerArgumentProto
+}
+
+// WithArena configures the model to batch-allocate the protos for constraints
+// over its variables, allocating slabs of the given size at a time. If the
+// size provided is <= 0, a default is used.
+func WithArena(slabSize int) ModelOption {
+	return func(m *Model) {
+		if slabSize <= 0 {
+			slabSize = defaultArenaSlabSize
+		}
+		m.arena = &protoArena{slabSize: slabSize}
+	}
+}
+
+// constraintProto returns a new, zero-valued constraint proto.
+func (a *protoArena) constraintProto() *pb.ConstraintProto {
+	if a == nil {
+		return &pb.ConstraintProto{}
+	}
+	if len(a.constraints) == 0 {
+		a.constraints = make([]pb.ConstraintProto, a.slabSize)
+	}
+	p := &a.constraints[0]
+	a.constraints = a.constraints[1:]
+	return p
+}
+
+// integerArgumentProto returns a new integer argument proto, with the given
+// target and variables.
+func (a *protoArena) integerArgumentProto(target IntVar, vars ...IntVar) *pb.IntegerArgumentProto {
+	var p *pb.IntegerArgumentProto
+	if a == nil {
+		p = &pb.IntegerArgumentProto{}
+	} else {
+		if len(a.integerArguments) == 0 {
+			a.integerArguments = make([]pb.IntegerArgumentProto, a.slabSize)
+		}
+		p = &a.integerArguments[0]
+		a.integerArguments = a.integerArguments[1:]
+	}
+
+	p.Target = target.index()
+	p.Vars = intVarList(vars).indexes()
+	return p
+}
+
+// arenaFor returns the arena of the model the given variables were
+// instantiated in, if any.
+func arenaFor(vars ...IntVar) *protoArena {
+	if m := modelFor(vars...); m != nil {
+		return m.arena
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArena(t *testing.T) {
+	model := NewModel("", WithArena(4))
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	z := model.NewIntVar(0, 10, "z")
+
+	var cs []Constraint
+	for i := 0; i < 6; i++ {
+		cs = append(cs, NewProductConstraint(x, y, z))
+	}
+	model.AddConstraints(cs...)
+
+	// The first four constraints were allocated from the same slab, the
+	// remaining from another.
+	require.Len(t, model.arena.constraints, 2)
+	require.Len(t, model.arena.integerArguments, 2)
+	for i, c := range model.pb.GetConstraints() {
+		require.Equal(t, []int32{1, 2}, c.GetIntProd().GetVars(), i)
+		require.Equal(t, int32(0), c.GetIntProd().GetTarget(), i)
+	}
+
+	// Constraints over variables from models without arenas are unaffected.
+	require.Nil(t, arenaFor(NewModel("").NewIntVar(0, 10, "a")))
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// AddAssumptions adds literals the solver assumes to be true. Unlike
+// constraints, assumptions that render the model infeasible can be identified
+// after the fact: see Result.SufficientAssumptionsForInfeasibility.
+func (m *Model) AddAssumptions(literals ...Literal) {
+	m.assertMutable()
+	m.pb.Assumptions = append(m.pb.Assumptions, asIntVars(literals).indexes()...)
+	m.assumptions = append(m.assumptions, literals...)
+}
+
+// ClearAssumptions removes all the model's assumptions, if any.
+func (m *Model) ClearAssumptions() {
+	m.assertMutable()
+	m.pb.Assumptions = nil
+	m.assumptions = nil
+}
+
+// SufficientAssumptionsForInfeasibility returns, for infeasible models, a
+// subset of the model's assumptions (see Model.AddAssumptions) that's
+// sufficient for the model to be infeasible; the model is feasible without at
+// least one of them. It's not necessarily minimal. It's only populated when
+// solving without parallelism.
+func (r Result) SufficientAssumptionsForInfeasibility() []Literal {
+	byIndex := make(map[int32]Literal, len(r.assumptions))
+	for _, l := range r.assumptions {
+		byIndex[l.index()] = l
+	}
+
+	var literals []Literal
+	for _, ref := range r.pb.GetSufficientAssumptionsForInfeasibility() {
+		if l, ok := byIndex[ref]; ok {
+			literals = append(literals, l)
+		}
+	}
+	return literals
+}
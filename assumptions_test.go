@@ -0,0 +1,54 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssumptions(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	big, small, even := model.NewLiteral("big"), model.NewLiteral("small"), model.NewLiteral("even")
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x), NewDomain(8, 10)).OnlyEnforceIf(big),
+		NewLinearConstraint(Sum(x), NewDomain(0, 2)).OnlyEnforceIf(small),
+		NewAllowedAssignmentsConstraint([]IntVar{x}, [][]int64{{0}, {2}, {4}, {6}, {8}, {10}}),
+	)
+	model.AddAssumptions(big, small.Not())
+	model.AddAssumptions(small)
+	require.Equal(t, []int32{big.index(), small.Not().index(), small.index()}, model.pb.GetAssumptions())
+
+	// Results refer back to the assumed literals.
+	core := Result{
+		pb: &pb.CpSolverResponse{
+			Status:                                pb.CpSolverStatus_INFEASIBLE,
+			SufficientAssumptionsForInfeasibility: []int32{small.Not().index(), small.index()},
+		},
+		assumptions: model.assumptions,
+	}
+	require.Equal(t, []Literal{small.Not(), small}, core.SufficientAssumptionsForInfeasibility())
+
+	model.ClearAssumptions()
+	require.Len(t, model.pb.GetAssumptions(), 0)
+	model.AddAssumptions(big, small, even)
+	result := model.Solve()
+	require.True(t, result.Infeasible())
+	// Both of big and small are needed for the model to be infeasible.
+	require.Subset(t, result.SufficientAssumptionsForInfeasibility(), []Literal{big, small})
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"sync"
+
+	"github.com/irfansharif/solver/internal"
+	"github.com/irfansharif/solver/pb"
+)
+
+// SolveHandle is a handle on a model being solved asynchronously; see
+// Model.SolveAsync.
+type SolveHandle struct {
+	done     chan struct{}
+	result   Result
+	callback *solutionCallback
+
+	mu struct {
+		sync.Mutex
+
+		// solver is the underlying solver, set for the duration of the solve.
+		solver internal.SolveWrapper
+		// cancelled is set once Cancel is called.
+		cancelled bool
+		// best is the last (and, for models with objectives, best) solution
+		// found so far, if any.
+		best *Result
+	}
+}
+
+// SolveAsync is like Solve, except it solves the model in a separate goroutine
+// and returns immediately. The returned handle can be used to wait for the
+// result, to look at intermediate solutions, or to cancel the solve. It lets
+// applications manage concurrent solves with their own scheduling and
+// cancellation policies, instead of blocking their own goroutines.
+//
+// What's solved is a snapshot of the model as of the call (see Clone), so the
+// model can continue to be changed while the solve is ongoing; changes made
+// after the call aren't picked up.
+func (m *Model) SolveAsync(os ...Option) *SolveHandle {
+	m.finalize()
+	snapshot, _ := m.clone()
+	h := &SolveHandle{done: make(chan struct{})}
+	os = append(os[:len(os):len(os)], h.track())
+	go func() {
+		defer close(h.done)
+		if h.cancelled() {
+			h.result = Result{pb: &pb.CpSolverResponse{Status: pb.CpSolverStatus_UNKNOWN}}
+			return
+		}
+		h.result = snapshot.solve(snapshot.pb, os...)
+	}()
+	return h
+}
+
+// Wait blocks until the solve is complete, returning its result.
+func (h *SolveHandle) Wait() Result {
+	<-h.done
+	return h.result
+}
+
+// Done returns a channel that's closed once the solve is complete.
+func (h *SolveHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Cancel stops the solve, if still ongoing. It returns immediately; use Wait to
+// retrieve the result, which is that of the search so far: the best solution
+// found (if any) but not proven to be optimal. Canceling a solve that hasn't
+// started yet results in an Unknown() result. It's safe to call multiple
+// times, and after the solve is complete.
+func (h *SolveHandle) Cancel() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.cancelled = true
+	if h.mu.solver != nil {
+		h.mu.solver.StopSearch()
+	}
+}
+
+// BestSoFar returns the last solution found, if any. For models with
+// objectives, solutions improve over time; it's the best one found so far.
+// Once the solve is complete, it returns the final result, if solved.
+func (h *SolveHandle) BestSoFar() (Result, bool) {
+	select {
+	case <-h.done:
+		return h.result, h.result.solved()
+	default:
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.mu.best == nil {
+		return Result{}, false
+	}
+	return *h.mu.best, true
+}
+
+// track returns an option that hooks the handle into the solve process, to
+// record intermediate solutions.
+func (h *SolveHandle) track() Option {
+	return func(o *options, s internal.SolveWrapper) {
+		o.handle = h
+		h.callback = &solutionCallback{f: h.record}
+		h.callback.hook = internal.NewDirectorSolutionCallback(h.callback)
+		s.AddSolutionCallback(h.callback.hook)
+	}
+}
+
+// attach is called by the solve process with the underlying solver before
+// solving, letting the solve be canceled.
+func (h *SolveHandle) attach(s internal.SolveWrapper) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.solver = s
+	if h.mu.cancelled {
+		s.StopSearch()
+	}
+}
+
+// detach is called by the solve process once solving is done, before the
+// underlying solver is freed.
+func (h *SolveHandle) detach() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.solver = nil
+	internal.DeleteDirectorSolutionCallback(h.callback.hook)
+}
+
+func (h *SolveHandle) record(r Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.best = &r
+}
+
+func (h *SolveHandle) cancelled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.mu.cancelled
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveAsync(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 12)))
+	model.Maximize(Sum(x, y))
+
+	handle := model.SolveAsync()
+	result := handle.Wait()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(12), result.ObjectiveValue())
+
+	best, ok := handle.BestSoFar()
+	require.True(t, ok)
+	require.Equal(t, float64(12), best.ObjectiveValue())
+
+	handle.Cancel() // canceling a completed solve is a no-op
+	require.True(t, handle.Wait().Optimal())
+}
+
+func TestSolveAsyncSnapshot(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.Maximize(Sum(x, y))
+
+	// Changes made after the call aren't picked up by the ongoing solve.
+	handle := model.SolveAsync()
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 12)))
+	result := handle.Wait()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(20), result.ObjectiveValue())
+	require.Equal(t, int64(10), result.Value(x))
+
+	require.Equal(t, float64(12), model.Solve().ObjectiveValue())
+}
+
+func TestSolveAsyncCancel(t *testing.T) {
+	model := NewModel("")
+	var vars []IntVar
+	for i := 0; i < 50; i++ {
+		vars = append(vars, model.NewIntVar(0, 100, ""))
+	}
+	model.AddConstraints(NewAllDifferentConstraint(vars...))
+	model.Maximize(Sum(vars...))
+
+	var handles []*SolveHandle
+	for i := 0; i < 4; i++ {
+		handles = append(handles, model.SolveAsync(WithParallelism(1)))
+	}
+	for _, handle := range handles {
+		handle.Cancel()
+	}
+	for _, handle := range handles {
+		result := handle.Wait()
+		require.False(t, result.Invalid())
+		require.False(t, result.Infeasible())
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"io"
+)
+
+// defaultChunkSize is the number of variables/constraints a ModelBuilder adds
+// to the model between progress reports.
+const defaultChunkSize = 1 << 14
+
+// ModelBuilder is used to construct (very) large models incrementally, for
+// example from a database cursor. Unlike building a Model directly, the
+// builder doesn't hold onto the intermediate Go representations of the
+// variables and constraints added to it, only their protos. As a consequence,
+// they won't show up in the model's string representation. Constraints are
+// otherwise added like they would be to the model directly: they're subject
+// to its degenerate policy, construction errors are reported by it, and so
+// on (see WithModelOptions).
+//
+// The model being built should not be modified directly until building is
+// done. It's not safe for concurrent use.
+type ModelBuilder struct {
+	model *Model
+
+	chunkSize int
+	progress  func(BuildProgress)
+	opts      []ModelOption
+
+	// pending is the number of variables/constraints added since progress was
+	// last reported.
+	pending int
+}
+
+// BuildProgress is passed to the progress callback (see WithBuildProgress)
+// every chunk of variables/constraints added to the model.
+type BuildProgress struct {
+	// Variables and Constraints are the number of variables and constraints
+	// added to the model so far.
+	Variables, Constraints int
+}
+
+// BuilderOption is used to configure a ModelBuilder.
+type BuilderOption func(b *ModelBuilder)
+
+// WithChunkSize configures the number of variables/constraints the builder
+// adds to the model between progress reports.
+func WithChunkSize(n int) BuilderOption {
+	return func(b *ModelBuilder) {
+		if n <= 0 {
+			panic("invalid chunk size: expected > 0")
+		}
+		b.chunkSize = n
+	}
+}
+
+// WithBuildProgress configures the builder to invoke the given callback
+// every chunk of variables/constraints added to the model.
+func WithBuildProgress(f func(BuildProgress)) BuilderOption {
+	return func(b *ModelBuilder) {
+		b.progress = f
+	}
+}
+
+// WithModelOptions configures the model being built. It's built without
+// introspection (see WithoutIntrospection) regardless.
+func WithModelOptions(opts ...ModelOption) BuilderOption {
+	return func(b *ModelBuilder) {
+		b.opts = append(b.opts, opts...)
+	}
+}
+
+// ModelSource is a stream of model elements, typically backed by something
+// like a database cursor.
+type ModelSource interface {
+	// Next adds the next batch of variables and/or constraints to the given
+	// builder. It returns io.EOF once the source is exhausted.
+	Next(b *ModelBuilder) error
+}
+
+// NewModelBuilder instantiates a new builder for a model with the given name.
+func NewModelBuilder(name string, opts ...BuilderOption) *ModelBuilder {
+	b := &ModelBuilder{
+		chunkSize: defaultChunkSize,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	b.model = NewModel(name, append([]ModelOption{WithoutIntrospection()}, b.opts...)...)
+	return b
+}
+
+// NewLiteral adds a new literal to the model.
+func (b *ModelBuilder) NewLiteral(name string) Literal {
+	return b.newIntVarInternal(NewDomain(0, 1), true, false, name).(Literal)
+}
+
+// NewConstant adds a new constant to the model.
+func (b *ModelBuilder) NewConstant(c int64, name string) IntVar {
+	return b.newIntVarInternal(NewDomain(c, c), false, true, name)
+}
+
+// NewIntVar adds a new integer variable to the model, one that's constrained
+// to the given inclusive upper/lower bound.
+func (b *ModelBuilder) NewIntVar(lb int64, ub int64, name string) IntVar {
+	return b.NewIntVarFromDomain(NewDomain(lb, ub), name)
+}
+
+// NewIntVarFromDomain adds a new integer variable to the model, one that's
+// constrained to the given domain.
+func (b *ModelBuilder) NewIntVarFromDomain(d Domain, name string) IntVar {
+	return b.newIntVarInternal(d, false, false, name)
+}
+
+// NewInterval adds a new interval to the model, one that's defined using the
+// given start, end and size.
+func (b *ModelBuilder) NewInterval(start, end, size IntVar, name string) Interval {
+	itv := newInterval(start, end, size, int32(len(b.model.pb.GetConstraints())), name)
+	b.AddConstraints(itv)
+	return itv
+}
+
+// AddConstraints adds constraints to the model.
+func (b *ModelBuilder) AddConstraints(cs ...Constraint) {
+	b.model.addConstraintsInternal(cs...)
+	b.pending += len(cs)
+	b.maybeReportProgress()
+}
+
+// ReadFrom adds elements to the model from the given source until it's
+// exhausted.
+func (b *ModelBuilder) ReadFrom(src ModelSource) error {
+	for {
+		if err := src.Next(b); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Model returns the model being built, reporting progress one last time (if
+// there's any left unreported).
+func (b *ModelBuilder) Model() *Model {
+	if b.pending > 0 {
+		b.reportProgress()
+	}
+	return b.model
+}
+
+func (b *ModelBuilder) newIntVarInternal(d Domain, isLiteral, isConst bool, name string) IntVar {
+	iv := b.model.newIntVarFromDomainInternal(d, isLiteral, isConst, name)
+	b.pending++
+	b.maybeReportProgress()
+	return iv
+}
+
+func (b *ModelBuilder) maybeReportProgress() {
+	if b.pending >= b.chunkSize {
+		b.reportProgress()
+	}
+}
+
+func (b *ModelBuilder) reportProgress() {
+	b.pending = 0
+	if b.progress != nil {
+		b.progress(BuildProgress{
+			Variables:   len(b.model.pb.GetVariables()),
+			Constraints: len(b.model.pb.GetConstraints()),
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cursor is a ModelSource that adds a variable and an all-different
+// constraint (relating it to the previous variable) on every call to Next.
+type cursor struct {
+	remaining int
+	prev      IntVar
+}
+
+func (c *cursor) Next(b *ModelBuilder) error {
+	if c.remaining == 0 {
+		return io.EOF
+	}
+	c.remaining--
+
+	v := b.NewIntVar(0, 1, fmt.Sprintf("v%d", c.remaining))
+	if c.prev != nil {
+		b.AddConstraints(NewAllDifferentConstraint(c.prev, v))
+	}
+	c.prev = v
+	return nil
+}
+
+func TestModelBuilder(t *testing.T) {
+	var progress []BuildProgress
+	b := NewModelBuilder("",
+		WithChunkSize(4),
+		WithBuildProgress(func(p BuildProgress) { progress = append(progress, p) }),
+	)
+	require.NoError(t, b.ReadFrom(&cursor{remaining: 10}))
+
+	model := b.Model()
+	require.Len(t, model.pb.GetVariables(), 10)
+	require.Len(t, model.pb.GetConstraints(), 9)
+	require.Equal(t, BuildProgress{Variables: 10, Constraints: 9}, progress[len(progress)-1])
+	require.Greater(t, len(progress), 1)
+
+	for i, c := range model.pb.GetConstraints() {
+		require.Equal(t, []int32{int32(i), int32(i + 1)}, c.GetAllDiff().GetVars())
+	}
+}
+
+func TestModelBuilderAddsThroughModel(t *testing.T) {
+	// Constraints are added like they would be to the model directly: subject
+	// to its degenerate policy, with construction errors reported by it.
+	b := NewModelBuilder("m", WithModelOptions(
+		WithDegeneratePolicy(RejectDegenerate),
+		WithConstructionErrors(),
+	))
+	x, y, z := b.NewIntVar(1, 2, "x"), b.NewIntVar(1, 2, "y"), b.NewIntVar(1, 2, "z")
+	target := b.NewIntVar(0, 10, "target")
+	b.AddConstraints(NewBooleanOrConstraint())
+	b.AddConstraints(NewProductConstraint(target, x, y, z)) // decomposed
+	b.AddConstraints(NewMinDistanceConstraint(x, x, -1))
+
+	model := b.Model()
+	require.EqualError(t, model.Err(), "2 construction errors: "+
+		"model m: degenerate constraint (boolean-or: ); "+
+		"model m: min-distance: negative distance -1")
+	require.Len(t, model.pb.GetVariables(), 5) // including a partial product
+	require.Len(t, model.pb.GetConstraints(), 2)
+	require.Empty(t, model.vars) // nothing's held onto
+}
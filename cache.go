@@ -0,0 +1,178 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/irfansharif/solver/internal"
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fingerprint returns a stable key identifying the model: a hash of its
+// (finalized) proto, encoded deterministically. Identical models built
+// independently, in different processes even, have the same fingerprint.
+func (m *Model) Fingerprint() string {
+	return fingerprint(m.finalized().pb)
+}
+
+// Cache stores results of solving models, returning them when solving
+// identical models (see Model.Fingerprint) with identical parameters instead
+// of solving them again. Only results that are reproducible are stored: optimal ones,
+// infeasible ones, and feasible ones for models without objectives. Solves
+// with solution callbacks (see WithEnumeration), and asynchronous ones (see
+// Model.SolveAsync), bypass the cache. It's safe for concurrent use if the
+// underlying storage is.
+type Cache struct {
+	store CacheStore
+}
+
+// CacheStore is the storage backing a Cache, mapping keys to (serialized)
+// results. Implementations could be backed by a file system or a remote
+// key-value store.
+type CacheStore interface {
+	// Get returns the value stored under the given key, if any.
+	Get(key string) (value []byte, ok bool)
+	// Put stores the given value under the given key.
+	Put(key string, value []byte)
+}
+
+// NewCache instantiates a cache backed by the given storage.
+func NewCache(store CacheStore) *Cache {
+	return &Cache{store: store}
+}
+
+// WithCache configures the solver to look up the result in the given cache
+// before solving, and to store it in the cache after (see Cache). Cached
+// results are marked as such; see Result.Cached.
+func WithCache(c *Cache) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.cache = c
+	}
+}
+
+// Solve is a shorthand for solving the model with the cache (see WithCache).
+func (c *Cache) Solve(m *Model, os ...Option) Result {
+	// Cap the slice to not append into the caller's backing array.
+	return m.Solve(append(os[:len(os):len(os)], WithCache(c))...)
+}
+
+// lookup returns the cache key for solving the given model proto with the
+// given options, populating the response if found in the cache. The key is
+// empty if the solve is to bypass the cache (or if there's no cache; a nil
+// cache is valid to use).
+func (c *Cache) lookup(model *pb.CpModelProto, opts *options, resp *pb.CpSolverResponse) (key string, ok bool) {
+	if c == nil || opts.solution != nil || opts.handle != nil {
+		return "", false
+	}
+
+	params, err := proto.MarshalOptions{Deterministic: true}.Marshal(&opts.params)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(fingerprint(model)))
+	h.Write(params)
+	if opts.canonical {
+		h.Write([]byte("canonical"))
+	}
+	key = hex.EncodeToString(h.Sum(nil))
+
+	value, ok := c.store.Get(key)
+	if !ok {
+		return key, false
+	}
+	if err := proto.Unmarshal(value, resp); err != nil {
+		resp.Reset() // treat corrupted entries as missing
+		return key, false
+	}
+	return key, true
+}
+
+// record stores the given response under the given key, if reproducible.
+func (c *Cache) record(key string, model *pb.CpModelProto, resp *pb.CpSolverResponse) {
+	if c == nil || key == "" {
+		return
+	}
+	switch resp.GetStatus() {
+	case pb.CpSolverStatus_OPTIMAL, pb.CpSolverStatus_INFEASIBLE:
+	case pb.CpSolverStatus_FEASIBLE:
+		if model.GetObjective() != nil {
+			return // we may have hit a limit
+		}
+	default:
+		return
+	}
+	value, err := proto.MarshalOptions{Deterministic: true}.Marshal(resp)
+	if err != nil {
+		return
+	}
+	c.store.Put(key, value)
+}
+
+// MemoryCacheStore is an in-memory CacheStore. It's safe for concurrent use.
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+var _ CacheStore = &MemoryCacheStore{}
+
+// NewMemoryCacheStore instantiates an empty in-memory cache store.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: make(map[string][]byte)}
+}
+
+// Get is part of the CacheStore interface.
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.entries[key]
+	return value, ok
+}
+
+// Put is part of the CacheStore interface.
+func (s *MemoryCacheStore) Put(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = value
+}
+
+// Len returns the number of entries in the store.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// fingerprint returns a hash of the given model proto, encoded
+// deterministically.
+func fingerprint(model *pb.CpModelProto) string {
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(model)
+	if err != nil {
+		panic(err) // only possible for invalid UTF-8 in names, say
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// Cached returns whether the result was retrieved from a cache instead of
+// solving the model; see WithCache.
+func (r Result) Cached() bool {
+	return r.cached
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	build := func(ub int64) *Model {
+		model := NewModel("fingerprint")
+		x := model.NewIntVar(0, ub, "x")
+		model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(2, 4)))
+		model.Maximize(Sum(x))
+		return model
+	}
+	require.Equal(t, build(10).Fingerprint(), build(10).Fingerprint())
+	require.NotEqual(t, build(10).Fingerprint(), build(11).Fingerprint())
+	require.Len(t, build(10).Fingerprint(), 64)
+}
+
+func TestCache(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.Maximize(Sum(x))
+
+	store := NewMemoryCacheStore()
+	cache := NewCache(store)
+	var opts options
+	var resp pb.CpSolverResponse
+	key, ok := cache.lookup(model.pb, &opts, &resp)
+	require.False(t, ok)
+	require.NotEmpty(t, key)
+
+	// Only reproducible results are stored.
+	cache.record(key, model.pb, &pb.CpSolverResponse{Status: pb.CpSolverStatus_FEASIBLE})
+	require.Zero(t, store.Len())
+	cache.record(key, model.pb, &pb.CpSolverResponse{Status: pb.CpSolverStatus_OPTIMAL, Solution: []int64{10}})
+	require.Equal(t, 1, store.Len())
+	_, ok = cache.lookup(model.pb, &opts, &resp)
+	require.True(t, ok)
+	require.Equal(t, []int64{10}, resp.GetSolution())
+
+	// The key depends on the parameters.
+	numWorkers := int32(1)
+	opts.params.NumSearchWorkers = &numWorkers
+	other, ok := cache.lookup(model.pb, &opts, &resp)
+	require.False(t, ok)
+	require.NotEqual(t, key, other)
+	opts.solution = &solutionCallback{}
+	key, _ = cache.lookup(model.pb, &opts, &resp)
+	require.Empty(t, key)
+	key, _ = (*Cache)(nil).lookup(model.pb, &options{}, &resp)
+	require.Empty(t, key)
+
+	cache = NewCache(NewMemoryCacheStore())
+	result := cache.Solve(model)
+	require.True(t, result.Optimal())
+	require.False(t, result.Cached())
+	result = model.Solve(WithCache(cache))
+	require.True(t, result.Optimal())
+	require.True(t, result.Cached())
+	require.Equal(t, int64(10), result.Value(x))
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// WithCallerTracking configures the model to record where (file:line) each of
+// its variables and constraints were added from, for debugging purposes. The
+// locations are included in the model's string representation, in validation
+// and verification errors referring to specific variables or constraints, and
+// in construction errors (see WithConstructionErrors). It makes for more
+// expensive model construction, and is meant to be used when tracing a bad
+// variable or constraint back to the code that generated it.
+//
+// The location recorded is that of the first caller outside this package; for
+// constraints, it's where they were added to the model (not where they were
+// instantiated).
+func WithCallerTracking() ModelOption {
+	return func(m *Model) {
+		m.callers = &callers{
+			vars:        make(map[int32]string),
+			constraints: make(map[int]string),
+		}
+	}
+}
+
+// callers records where a model's variables and constraints were added from;
+// see WithCallerTracking.
+type callers struct {
+	vars        map[int32]string // variable index => location
+	constraints map[int]string   // constraint (proto) index => location
+	added       []string         // location of each of Model.constraints
+}
+
+// packageDir is the directory this package's source files are in; frames from
+// these files (tests aside) are skipped when looking for callers.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// caller returns the location (file:line) of the first caller outside this
+// package.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "<unknown>"
+		}
+	}
+}
+
+// trackVariable records the location of the variable with the given index, if
+// tracking callers.
+func (m *Model) trackVariable(idx int32) {
+	if m.callers != nil {
+		m.callers.vars[idx] = caller()
+	}
+}
+
+// trackConstraints records the location of the constraint protos with indexes
+// in [lo, hi), if tracking callers.
+func (m *Model) trackConstraints(lo, hi int) {
+	if m.callers == nil {
+		return
+	}
+	loc := caller()
+	for idx := lo; idx < hi; idx++ {
+		m.callers.constraints[idx] = loc
+	}
+}
+
+// variableLocation returns " [file:line]" for the variable with the given
+// index, if tracked, for use in error messages. It returns the empty string
+// otherwise.
+func (m *Model) variableLocation(idx int32) string {
+	if m.callers == nil {
+		return ""
+	}
+	if idx < 0 {
+		idx = -idx - 1
+	}
+	if loc, ok := m.callers.vars[idx]; ok {
+		return fmt.Sprintf(" [%s]", loc)
+	}
+	return ""
+}
+
+// constraintLocation is like variableLocation, for the constraint (proto) with
+// the given index.
+func (m *Model) constraintLocation(idx int) string {
+	if m.callers == nil {
+		return ""
+	}
+	if loc, ok := m.callers.constraints[idx]; ok {
+		return fmt.Sprintf(" [%s]", loc)
+	}
+	return ""
+}
+
+// indexReference matches references to variables and constraints by index, as
+// found in the underlying solver's validation errors.
+var indexReference = regexp.MustCompile(`(?i)\b(constraint|var|variable) #(\d+)`)
+
+// annotate rewrites the given message to include the locations of the
+// variables and constraints it refers to by index, if tracking callers.
+func (m *Model) annotate(msg string) string {
+	if m.callers == nil {
+		return msg
+	}
+	return indexReference.ReplaceAllStringFunc(msg, func(ref string) string {
+		match := indexReference.FindStringSubmatch(ref)
+		idx, err := strconv.Atoi(match[2])
+		if err != nil {
+			return ref
+		}
+		if strings.EqualFold(match[1], "constraint") {
+			return ref + m.constraintLocation(idx)
+		}
+		return ref + m.variableLocation(int32(idx))
+	})
+}
+
+// describe rewrites the given message to include the names of the variables,
+// and descriptions of the constraints, it refers to by index. It's how the
+// underlying solver's validation errors are made actionable.
+func (m *Model) describe(msg string) string {
+	var vars []IntVar
+	var constraints map[*pb.ConstraintProto]Constraint
+	return indexReference.ReplaceAllStringFunc(msg, func(ref string) string {
+		match := indexReference.FindStringSubmatch(ref)
+		idx, err := strconv.Atoi(match[2])
+		if err != nil {
+			return ref
+		}
+
+		if vars == nil {
+			vars = m.variables()
+			constraints = make(map[*pb.ConstraintProto]Constraint)
+			for _, c := range m.constraints {
+				for _, ct := range m.protosOf(c) {
+					constraints[ct] = c
+				}
+			}
+			for _, itv := range m.intervals {
+				constraints[itv.protos()[0]] = itv
+			}
+		}
+		if strings.EqualFold(match[1], "constraint") {
+			if idx >= len(m.pb.GetConstraints()) {
+				return ref
+			}
+			ct := m.pb.GetConstraints()[idx]
+			if c, ok := constraints[ct]; ok {
+				if itv, ok := c.(Interval); ok {
+					return fmt.Sprintf("%s (interval %s: %s)", ref, itv.name(), itv.String())
+				}
+				return fmt.Sprintf("%s (%s)", ref, c.String())
+			}
+			return fmt.Sprintf("%s (%s)", ref, loadedDescription(ct, func(r int32) *intVar {
+				idx := r
+				if r < 0 {
+					idx = -r - 1
+				}
+				if int(idx) >= len(vars) {
+					return nil // the solver's complaining about exactly this, say
+				}
+				if r < 0 {
+					return vars[idx].(*intVar).Not().(*intVar)
+				}
+				return vars[idx].(*intVar)
+			}))
+		}
+		if idx >= len(vars) {
+			return ref
+		}
+		return fmt.Sprintf("%s (%s)", ref, vars[idx].name())
+	})
+}
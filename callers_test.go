@@ -0,0 +1,82 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerTracking(t *testing.T) {
+	line := func() int {
+		_, _, line, _ := runtime.Caller(1)
+		return line
+	}
+
+	model := NewModel("", WithCallerTracking())
+	x, l := model.NewIntVar(0, 10, "x"), line()
+	a := model.NewLiteral("a")
+	model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(a))
+	at := line() - 1
+
+	require.Contains(t, model.String(), fmt.Sprintf("x in [0, 10] [callers_test.go:%d]", l))
+	require.Contains(t, model.String(), fmt.Sprintf("linear-constraint: x in [0, 5] if (a) [callers_test.go:%d]", at))
+	require.Equal(t,
+		fmt.Sprintf("Out of bound integer variable 7 in constraint #0 [callers_test.go:%d] : ...; var #0 [callers_test.go:%d] has no domain", at, l),
+		model.annotate("Out of bound integer variable 7 in constraint #0 : ...; var #0 has no domain"),
+	)
+
+	model.pb.Constraints[0].GetLinear().Vars[0] = 7
+	require.EqualError(t, model.CheckReferences(), fmt.Sprintf(
+		"invalid reference: constraint #0 [callers_test.go:%d] (*pb.ConstraintProto_Linear): variable #7 doesn't exist (2 variables)", at))
+
+	model = NewModel("", WithCallerTracking(), WithConstructionErrors())
+	y := model.NewIntVar(0, 10, "y")
+	NewAllowedAssignmentsConstraint([]IntVar{y}, [][]int64{{1, 2}})
+	l = line() - 1
+	require.True(t, strings.HasSuffix(model.Err().Error(), fmt.Sprintf("[callers_test.go:%d]", l)), model.Err())
+
+	// Without tracking, messages are left as is.
+	require.Equal(t, "constraint #0", NewModel("").annotate("constraint #0"))
+}
+
+func TestDescribeReferences(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	a := model.NewLiteral("a")
+	model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(a))
+	model.NewFixedSizeInterval(x, 2, "itv")
+	model.pb.Constraints = append(model.pb.Constraints, NewBooleanOrConstraint(a.Not()).protos()...)
+
+	require.Equal(t,
+		"Out of bound integer variable 7 in constraint #0 (linear-constraint: x in [0, 5] if (a)) : ...; var #1 (a) has no domain",
+		model.describe("Out of bound integer variable 7 in constraint #0 : ...; var #1 has no domain"),
+	)
+	require.Equal(t, "constraint #1 (interval itv: [x, itv.end | itv.size])", model.describe("constraint #1"))
+	require.Equal(t, "constraint #2 (boolor: ~a)", model.describe("constraint #2"))
+	require.Equal(t, "constraint #3; var #9", model.describe("constraint #3; var #9"))
+
+	// Constraints referring to variables that don't exist are described using
+	// their indexes.
+	model.pb.Constraints = append(model.pb.Constraints, &pb.ConstraintProto{
+		Constraint: &pb.ConstraintProto_BoolOr{BoolOr: &pb.BoolArgumentProto{Literals: []int32{1, 7, -9}}},
+	})
+	require.Equal(t, "constraint #3 (boolor: a, #7, #-9)", model.describe("constraint #3"))
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Clone returns an independent copy of the model, one that can be changed
+// without affecting the original (and vice versa). The original's variables
+// still belong to it; see CloneWithMapping for their counterparts in the clone.
+func (m *Model) Clone() *Model {
+	c, _ := m.CloneWithMapping()
+	return c
+}
+
+// CloneWithMapping is like Clone, also returning the mapping of the original's
+// variables, literals and intervals to their counterparts in the clone (see
+// Merged).
+func (m *Model) CloneWithMapping() (*Model, *Merged) {
+	c, mapping := m.clone()
+	c.finalize()
+	return c, mapping
+}
+
+// clone is like CloneWithMapping, except pending deferred constructs are
+// carried over to the clone instead of being emitted into it.
+func (m *Model) clone() (*Model, *Merged) {
+	c := *m
+	c.pb = proto.Clone(m.pb).(*pb.CpModelProto)
+	c.frozen, c.concurrent = false, nil
+	c.handles = nil // bound to the original
+	c.deferred = append([]func(*Model){}, m.deferred...)
+	if m.arena != nil {
+		c.arena = &protoArena{slabSize: m.arena.slabSize}
+	}
+
+	// The variables and intervals we hold onto point to their underlying
+	// protos, and are also updated in place (see SetHorizon); copy them over.
+	// Variables the model doesn't hold onto (instantiated through a
+	// ModelBuilder, say) are reconstructed, so all of them can be mapped.
+	mapping := &Merged{from: m, intervals: make(map[int32]Interval)}
+	for i, v := range m.variables() {
+		iv := *v.(*intVar)
+		iv.pb = c.pb.Variables[i]
+		iv.negation = nil
+		iv.model = &c
+		mapping.vars = append(mapping.vars, &iv)
+	}
+	lookup := func(v IntVar) IntVar {
+		if iv, ok := v.(*intVar); ok && !iv.isNegated() {
+			return mapping.vars[iv.idx]
+		}
+		return v
+	}
+	c.vars = nil
+	for _, v := range m.vars {
+		c.vars = append(c.vars, lookup(v))
+	}
+	c.constants = nil
+	for _, v := range m.constants {
+		c.constants = append(c.constants, lookup(v))
+	}
+	c.literals = nil
+	for _, l := range m.literals {
+		c.literals = append(c.literals, lookup(l).(Literal))
+	}
+	c.intervals = nil
+	for _, itv := range m.intervals {
+		ci := *itv.(*interval)
+		ci.pb = c.pb.Constraints[ci.idx]
+		ci.start, ci.end, ci.size = lookup(ci.start), lookup(ci.end), lookup(ci.size)
+		if ci.enforcement != nil {
+			ci.enforcement = mapping.Literal(ci.enforcement)
+		}
+		c.intervals = append(c.intervals, &ci)
+		mapping.intervals[ci.idx] = &ci
+	}
+
+	// The constraints we hold onto (for printing, say) are rebound to the
+	// clone's protos.
+	protos := make(map[*pb.ConstraintProto]*pb.ConstraintProto, len(m.pb.GetConstraints()))
+	for i, ct := range m.pb.GetConstraints() {
+		protos[ct] = c.pb.Constraints[i]
+	}
+	c.constraints = nil
+	for _, ct := range m.constraints {
+		c.constraints = append(c.constraints, rebindConstraint(ct, protos, mapping))
+	}
+	c.normalized = nil
+	for ct, n := range m.normalized {
+		cn, ok := protos[n]
+		if !ok {
+			continue // removed
+		}
+		if c.normalized == nil {
+			c.normalized = make(map[*pb.ConstraintProto]*pb.ConstraintProto, len(m.normalized))
+		}
+		c.normalized[ct] = cn
+	}
+	literals := func(ls []Literal) []Literal {
+		var res []Literal
+		for _, l := range ls {
+			res = append(res, mapping.Literal(l))
+		}
+		return res
+	}
+	if m.coverage != nil {
+		c.coverage = &coverage{
+			literals: literals(m.coverage.literals),
+			weights:  append([]int64(nil), m.coverage.weights...),
+		}
+	}
+	if m.soft != nil {
+		c.soft = &softConstraints{
+			violations: literals(m.soft.violations),
+			penalties:  append([]int64(nil), m.soft.penalties...),
+		}
+	}
+	c.assumptions = literals(m.assumptions)
+	if m.priorities != nil {
+		c.priorities = make(map[int]int, len(m.priorities))
+		for k, v := range m.priorities {
+			c.priorities[k] = v
+		}
+	}
+	c.errs = append(constructionErrors(nil), m.errs...)
+	if m.callers != nil {
+		c.callers = &callers{
+			vars:        make(map[int32]string, len(m.callers.vars)),
+			constraints: make(map[int]string, len(m.callers.constraints)),
+			added:       append([]string(nil), m.callers.added...),
+		}
+		for k, v := range m.callers.vars {
+			c.callers.vars[k] = v
+		}
+		for k, v := range m.callers.constraints {
+			c.callers.constraints[k] = v
+		}
+	}
+	return &c, mapping
+}
+
+// rebindConstraint returns a copy of the given constraint, one backed by the
+// given protos' counterparts, if any.
+func rebindConstraint(c Constraint, protos map[*pb.ConstraintProto]*pb.ConstraintProto, mapping *Merged) Constraint {
+	switch c := c.(type) {
+	case *constraint:
+		cc := *c
+		if ct, ok := protos[c.pb]; ok {
+			cc.pb = ct
+		}
+		return &cc
+	case constraints:
+		cs := make([]Constraint, len(c.cs))
+		for i := range c.cs {
+			cs[i] = rebindConstraint(c.cs[i], protos, mapping)
+		}
+		c.cs = cs
+		return c
+	case *interval:
+		return mapping.Interval(c)
+	}
+	return c
+}
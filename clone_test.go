@@ -0,0 +1,89 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	base := NewModel("base")
+	x, y := base.NewIntVar(0, 10, "x"), base.NewIntVar(0, 10, "y")
+	base.NewInterval(x, y, base.NewConstant(3, "size"), "itv")
+	base.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 15)))
+	base.Maximize(Sum(x))
+
+	scenario := base.Clone()
+	scenario.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(0, 4)))
+	scenario.SetHorizon(0, 8)
+	require.Len(t, base.pb.GetConstraints(), 2)
+	require.Len(t, scenario.pb.GetConstraints(), 3)
+	require.Equal(t, []int64{0, 10}, base.pb.GetVariables()[x.index()].GetDomain())
+	require.Equal(t, []int64{0, 8}, scenario.pb.GetVariables()[x.index()].GetDomain())
+	require.Contains(t, base.String(), "x in [0, 10]")
+	require.Contains(t, scenario.String(), "x in [0, 8]")
+
+	base.Finalize()
+	require.False(t, base.Clone().Finalized())
+
+	result, err := SolveAndVerify(base)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), result.Value(x))
+	result, err = SolveAndVerify(scenario)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), result.Value(x))
+}
+
+func TestCloneResources(t *testing.T) {
+	base := NewModel("")
+	machine := base.AddDisjunctiveResource("machine")
+	x := base.NewIntVar(0, 10, "x")
+	machine.Register(base.NewFixedSizeInterval(x, 2, "a"), nil)
+	machine.Register(base.NewFixedSizeInterval(x, 3, "b"), nil)
+
+	// Deferred constructs are emitted into the clone, the base is left as is
+	// (and can still be registered against).
+	clone := base.Clone()
+	require.Len(t, base.pb.GetConstraints(), 2)
+	require.Len(t, clone.pb.GetConstraints(), 3)
+	machine.Register(base.NewFixedSizeInterval(x, 4, "c"), nil)
+	base.finalize()
+	require.Len(t, base.pb.GetConstraints(), 4)
+	require.Len(t, clone.pb.GetConstraints(), 3)
+}
+
+func TestCloneWithMapping(t *testing.T) {
+	base := NewModel("")
+	a, b, c := base.NewIntVar(1, 2, "a"), base.NewIntVar(1, 2, "b"), base.NewIntVar(1, 2, "c")
+	target := base.NewIntVar(0, 10, "t")
+	ct := NewLinearConstraint(Sum(a, b), NewDomain(0, 3))
+	base.AddConstraints(ct)
+
+	clone, mapping := base.CloneWithMapping()
+	require.Equal(t, "a", mapping.Var(a).name())
+
+	// Auxiliary variables are instantiated in the clone, not the original.
+	clone.AddConstraints(NewProductConstraint(
+		mapping.Var(target), mapping.Var(a), mapping.Var(b), mapping.Var(c)))
+	require.Len(t, base.pb.GetVariables(), 4)
+	require.Len(t, clone.pb.GetVariables(), 5)
+	require.NoError(t, clone.CheckReferences())
+
+	// The clone's constraints are backed by its own protos.
+	require.Same(t, clone.pb.GetConstraints()[0], clone.constraints[0].protos()[0])
+	require.NotSame(t, base.pb.GetConstraints()[0], clone.constraints[0].protos()[0])
+}
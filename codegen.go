@@ -0,0 +1,398 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// GenerateGo emits self-contained Go source, in the given package, that
+// reconstructs the model using this package's public API. The source defines a
+// single function, NewModel, that returns the reconstructed model.
+//
+// The generated model is equivalent to this one at the level of the
+// underlying protos, though not necessarily in how it's printed; variables
+// with a [0, 1] domain are declared as literals (as are fixed ones referenced
+// as literals), and composite constraints (all-same, for example) are emitted
+// as their constituent parts. Parts of the model that can't be expressed using
+// the public API (symmetry information, say) result in an error.
+func (m *Model) GenerateGo(pkg string) ([]byte, error) {
+	m = m.finalized()
+	g := &generator{
+		model:    m.pb,
+		vars:     make(map[int32]bool),
+		literals: make(map[int32]bool),
+	}
+
+	// Generate constraints first, so we know which variables and intervals are
+	// referenced (and thus need to be declared as named Go variables).
+	var constraints strings.Builder
+	for i, ct := range m.pb.GetConstraints() {
+		stmt, err := g.constraint(int32(i), ct)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %d: %v", i, err)
+		}
+		constraints.WriteString(stmt)
+	}
+	var objective strings.Builder
+	if obj := m.pb.GetObjective(); obj != nil {
+		method, sign := "Minimize", int64(1)
+		if obj.GetScalingFactor() < 0 {
+			// See Model.Maximize.
+			method, sign = "Maximize", -1
+		}
+		coeffs := make([]int64, len(obj.GetCoeffs()))
+		for i, c := range obj.GetCoeffs() {
+			coeffs[i] = sign * c
+		}
+		objective.WriteString(fmt.Sprintf("model.%s(%s)\n",
+			method, g.linearExpr(obj.GetVars(), coeffs, sign*int64(obj.GetOffset()))))
+	}
+	var hints strings.Builder
+	if hint := m.pb.GetSolutionHint(); hint != nil {
+		for i, v := range hint.GetVars() {
+			hints.WriteString(fmt.Sprintf("model.AddHint(%s, %d)\n", g.ref(v), hint.GetValues()[i]))
+		}
+	}
+	var assumptions strings.Builder
+	if lits := m.pb.GetAssumptions(); len(lits) > 0 {
+		assumptions.WriteString(fmt.Sprintf("model.AddAssumptions(%s)\n", g.literalRefs(lits)))
+	}
+	var strategies strings.Builder
+	for i, ds := range m.pb.GetSearchStrategy() {
+		stmt, err := g.decisionStrategy(ds)
+		if err != nil {
+			return nil, fmt.Errorf("decision strategy %d: %v", i, err)
+		}
+		strategies.WriteString(stmt)
+	}
+	if m.pb.GetSymmetry() != nil {
+		return nil, fmt.Errorf("unsupported symmetry information")
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by solver.Model.GenerateGo. DO NOT EDIT.\n\n")
+	b.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	b.WriteString("import \"github.com/irfansharif/solver\"\n\n")
+	b.WriteString(fmt.Sprintf("// NewModel reconstructs the %q model.\n", m.pb.GetName()))
+	b.WriteString("func NewModel() *solver.Model {\n")
+	b.WriteString(fmt.Sprintf("model := solver.NewModel(%q)\n", m.pb.GetName()))
+	for i, v := range m.pb.GetVariables() {
+		decl, err := g.variable(int32(i), v)
+		if err != nil {
+			return nil, fmt.Errorf("variable %d: %v", i, err)
+		}
+		b.WriteString(decl)
+	}
+	b.WriteString(constraints.String())
+	b.WriteString(objective.String())
+	b.WriteString(hints.String())
+	b.WriteString(assumptions.String())
+	b.WriteString(strategies.String())
+	b.WriteString("return model\n}\n")
+
+	return format.Source([]byte(b.String()))
+}
+
+// generator is used to generate Go source for a model.
+type generator struct {
+	model *pb.CpModelProto
+
+	// vars tracks the variables (by index) that are referenced by the
+	// generated source.
+	vars map[int32]bool
+	// literals tracks the variables (by index) that are referenced as
+	// literals, and thus need to be declared as such.
+	literals map[int32]bool
+}
+
+// variable generates the declaration for the given variable.
+func (g *generator) variable(idx int32, v *pb.IntegerVariableProto) (string, error) {
+	var decl string
+	d := v.GetDomain()
+	fixed := len(d) == 2 && d[0] == d[1]
+	switch {
+	case len(d) == 2 && d[0] == 0 && d[1] == 1:
+		decl = fmt.Sprintf("model.NewLiteral(%q)", v.GetName())
+	case g.literals[idx]:
+		// Literals fixed since they were instantiated (see SetDomainChange)
+		// are declared as literals, and then fixed.
+		if !fixed || (d[0] != 0 && d[0] != 1) {
+			return "", fmt.Errorf("referenced as a literal, with domain %s", &domain{intervals: d})
+		}
+		return fmt.Sprintf("v%d := model.NewLiteral(%q)\nsolver.SetDomainChange(v%d, %s)(model)\n",
+			idx, v.GetName(), idx, domainSrc(d)), nil
+	case fixed:
+		decl = fmt.Sprintf("model.NewConstant(%d, %q)", d[0], v.GetName())
+	default:
+		decl = fmt.Sprintf("model.NewIntVarFromDomain(%s, %q)", domainSrc(d), v.GetName())
+	}
+	if !g.vars[idx] {
+		return decl + "\n", nil
+	}
+	return fmt.Sprintf("v%d := %s\n", idx, decl), nil
+}
+
+// constraint generates the statement adding the given constraint to the
+// model.
+func (g *generator) constraint(idx int32, ct *pb.ConstraintProto) (string, error) {
+	if itv := ct.GetInterval(); itv != nil {
+		decl := fmt.Sprintf("model.NewInterval(%s, %s, %s, %q)",
+			g.ref(itv.GetStart()), g.ref(itv.GetEnd()), g.ref(itv.GetSize()), ct.GetName())
+		if lits := ct.GetEnforcementLiteral(); len(lits) > 0 {
+			decl = fmt.Sprintf("%s.OnlyEnforceIf(%s)", decl, g.literalRefs(lits))
+			if g.referenced(idx) {
+				decl = fmt.Sprintf("%s.(solver.Interval)", decl)
+			}
+		}
+		if !g.referenced(idx) {
+			return decl + "\n", nil
+		}
+		return fmt.Sprintf("i%d := %s\n", idx, decl), nil
+	}
+
+	var c string
+	switch ct.GetConstraint().(type) {
+	case *pb.ConstraintProto_AllDiff:
+		c = fmt.Sprintf("solver.NewAllDifferentConstraint(%s)", g.refs(ct.GetAllDiff().GetVars()))
+	case *pb.ConstraintProto_AtMostOne:
+		c = fmt.Sprintf("solver.NewAtMostKConstraint(1, %s)", g.literalRefs(ct.GetAtMostOne().GetLiterals()))
+	case *pb.ConstraintProto_ExactlyOne:
+		c = fmt.Sprintf("solver.NewExactlyKConstraint(1, %s)", g.literalRefs(ct.GetExactlyOne().GetLiterals()))
+	case *pb.ConstraintProto_BoolAnd:
+		c = fmt.Sprintf("solver.NewBooleanAndConstraint(%s)", g.literalRefs(ct.GetBoolAnd().GetLiterals()))
+	case *pb.ConstraintProto_BoolOr:
+		c = fmt.Sprintf("solver.NewBooleanOrConstraint(%s)", g.literalRefs(ct.GetBoolOr().GetLiterals()))
+	case *pb.ConstraintProto_BoolXor:
+		c = fmt.Sprintf("solver.NewBooleanXorConstraint(%s)", g.literalRefs(ct.GetBoolXor().GetLiterals()))
+	case *pb.ConstraintProto_IntDiv:
+		arg := ct.GetIntDiv()
+		c = fmt.Sprintf("solver.NewDivisionConstraint(%s, %s)", g.ref(arg.GetTarget()), g.refs(arg.GetVars()))
+	case *pb.ConstraintProto_IntMod:
+		arg := ct.GetIntMod()
+		c = fmt.Sprintf("solver.NewModuloConstraint(%s, %s)", g.ref(arg.GetTarget()), g.refs(arg.GetVars()))
+	case *pb.ConstraintProto_IntProd:
+		arg := ct.GetIntProd()
+		c = fmt.Sprintf("solver.NewProductConstraint(%s, %s)", g.ref(arg.GetTarget()), g.refs(arg.GetVars()))
+	case *pb.ConstraintProto_LinMax:
+		arg := ct.GetLinMax()
+		c = fmt.Sprintf("solver.NewLinearMaximumConstraint(%s)", g.linearExprs(arg.GetTarget(), arg.GetExprs()))
+	case *pb.ConstraintProto_LinMin:
+		arg := ct.GetLinMin()
+		c = fmt.Sprintf("solver.NewLinearMinimumConstraint(%s)", g.linearExprs(arg.GetTarget(), arg.GetExprs()))
+	case *pb.ConstraintProto_Linear:
+		arg := ct.GetLinear()
+		c = fmt.Sprintf("solver.NewLinearConstraint(%s, %s)",
+			g.linearExpr(arg.GetVars(), arg.GetCoeffs(), 0), domainSrc(arg.GetDomain()))
+	case *pb.ConstraintProto_Element:
+		arg := ct.GetElement()
+		c = fmt.Sprintf("solver.NewElementConstraint(%s, %s, %s)",
+			g.ref(arg.GetTarget()), g.ref(arg.GetIndex()), g.refs(arg.GetVars()))
+	case *pb.ConstraintProto_Table:
+		arg := ct.GetTable()
+		constructor := "NewAllowedAssignmentsConstraint"
+		if arg.GetNegated() {
+			constructor = "NewForbiddenAssignmentsConstraint"
+		}
+		var assignments strings.Builder
+		for i, n := 0, len(arg.GetVars()); n > 0 && i < len(arg.GetValues()); i += n {
+			assignments.WriteString(fmt.Sprintf("{%s},", int64sSrc(arg.GetValues()[i:i+n])))
+		}
+		c = fmt.Sprintf("solver.%s([]solver.IntVar{%s}, [][]int64{%s})",
+			constructor, g.refs(arg.GetVars()), assignments.String())
+	case *pb.ConstraintProto_NoOverlap:
+		c = fmt.Sprintf("solver.NewNonOverlappingConstraint(%s)", g.intervalRefs(ct.GetNoOverlap().GetIntervals()))
+	case *pb.ConstraintProto_NoOverlap_2D:
+		arg := ct.GetNoOverlap_2D()
+		c = fmt.Sprintf("solver.NewNonOverlapping2DConstraint([]solver.Interval{%s}, []solver.Interval{%s}, %t)",
+			g.intervalRefs(arg.GetXIntervals()), g.intervalRefs(arg.GetYIntervals()),
+			arg.GetBoxesWithNullAreaCanOverlap())
+	case *pb.ConstraintProto_Cumulative:
+		arg := ct.GetCumulative()
+		c = fmt.Sprintf("solver.NewCumulativeConstraint(%s, []solver.Interval{%s}, []solver.IntVar{%s})",
+			g.ref(arg.GetCapacity()), g.intervalRefs(arg.GetIntervals()), g.refs(arg.GetDemands()))
+	case *pb.ConstraintProto_Routes:
+		arg := ct.GetRoutes()
+		c = fmt.Sprintf("solver.NewRoutesConstraint([]int{%s}, []int{%s}, []solver.Literal{%s})",
+			int32sSrc(arg.GetTails()), int32sSrc(arg.GetHeads()), g.literalRefs(arg.GetLiterals()))
+	case *pb.ConstraintProto_Automaton:
+		arg := ct.GetAutomaton()
+		var transitions strings.Builder
+		for i := range arg.GetTransitionTail() {
+			transitions.WriteString(fmt.Sprintf("{Tail: %d, Head: %d, Label: %d},",
+				arg.GetTransitionTail()[i], arg.GetTransitionHead()[i], arg.GetTransitionLabel()[i]))
+		}
+		c = fmt.Sprintf("solver.NewAutomatonConstraint([]solver.IntVar{%s}, %d, []int64{%s}, []solver.Transition{%s})",
+			g.refs(arg.GetVars()), arg.GetStartingState(), int64sSrc(arg.GetFinalStates()), transitions.String())
+	default:
+		return "", fmt.Errorf("unsupported constraint type: %T", ct.GetConstraint())
+	}
+
+	if lits := ct.GetEnforcementLiteral(); len(lits) > 0 {
+		c = fmt.Sprintf("%s.OnlyEnforceIf(%s)", c, g.literalRefs(lits))
+	}
+	if name := ct.GetName(); name != "" {
+		c = fmt.Sprintf("%s.WithName(%q)", c, name)
+	}
+	return fmt.Sprintf("model.AddConstraints(%s)\n", c), nil
+}
+
+// decisionStrategy generates the statement adding the given decision strategy
+// to the model (see Model.AddDecisionStrategy).
+func (g *generator) decisionStrategy(ds *pb.DecisionStrategyProto) (string, error) {
+	if len(ds.GetTransformations()) > 0 {
+		return "", fmt.Errorf("unsupported affine transformations")
+	}
+	var variable, value string
+	switch ds.GetVariableSelectionStrategy() {
+	case pb.DecisionStrategyProto_CHOOSE_FIRST:
+		variable = "ChooseFirst"
+	case pb.DecisionStrategyProto_CHOOSE_LOWEST_MIN:
+		variable = "ChooseLowestMin"
+	case pb.DecisionStrategyProto_CHOOSE_HIGHEST_MAX:
+		variable = "ChooseHighestMax"
+	case pb.DecisionStrategyProto_CHOOSE_MIN_DOMAIN_SIZE:
+		variable = "ChooseMinDomainSize"
+	case pb.DecisionStrategyProto_CHOOSE_MAX_DOMAIN_SIZE:
+		variable = "ChooseMaxDomainSize"
+	default:
+		return "", fmt.Errorf("unsupported variable selection strategy: %s", ds.GetVariableSelectionStrategy())
+	}
+	switch ds.GetDomainReductionStrategy() {
+	case pb.DecisionStrategyProto_SELECT_MIN_VALUE:
+		value = "MinValueFirst"
+	case pb.DecisionStrategyProto_SELECT_MAX_VALUE:
+		value = "MaxValueFirst"
+	case pb.DecisionStrategyProto_SELECT_LOWER_HALF:
+		value = "LowerHalfFirst"
+	case pb.DecisionStrategyProto_SELECT_UPPER_HALF:
+		value = "UpperHalfFirst"
+	case pb.DecisionStrategyProto_SELECT_MEDIAN_VALUE:
+		value = "MedianValueFirst"
+	default:
+		return "", fmt.Errorf("unsupported domain reduction strategy: %s", ds.GetDomainReductionStrategy())
+	}
+	return fmt.Sprintf("model.AddDecisionStrategy([]solver.IntVar{%s}, solver.%s, solver.%s)\n",
+		g.refs(ds.GetVariables()), variable, value), nil
+}
+
+// referenced returns whether the interval with the given index is referenced
+// by any constraint.
+func (g *generator) referenced(idx int32) bool {
+	for _, ct := range g.model.GetConstraints() {
+		var intervals []int32
+		switch ct.GetConstraint().(type) {
+		case *pb.ConstraintProto_NoOverlap:
+			intervals = ct.GetNoOverlap().GetIntervals()
+		case *pb.ConstraintProto_NoOverlap_2D:
+			intervals = append(ct.GetNoOverlap_2D().GetXIntervals(), ct.GetNoOverlap_2D().GetYIntervals()...)
+		case *pb.ConstraintProto_Cumulative:
+			intervals = ct.GetCumulative().GetIntervals()
+		}
+		for _, i := range intervals {
+			if i == idx {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ref returns a reference to the variable with the given index, negating it if
+// needed (see Literal.Not).
+func (g *generator) ref(idx int32) string {
+	if idx < 0 {
+		g.vars[-idx-1] = true
+		g.literals[-idx-1] = true
+		return fmt.Sprintf("v%d.Not()", -idx-1)
+	}
+	g.vars[idx] = true
+	return fmt.Sprintf("v%d", idx)
+}
+
+// refs returns a comma-separated list of references to the variables with the
+// given indexes.
+func (g *generator) refs(idxs []int32) string {
+	var refs []string
+	for _, idx := range idxs {
+		refs = append(refs, g.ref(idx))
+	}
+	return strings.Join(refs, ", ")
+}
+
+// literalRefs is like refs, for variables referenced as literals.
+func (g *generator) literalRefs(idxs []int32) string {
+	for _, idx := range idxs {
+		if idx >= 0 {
+			g.literals[idx] = true
+		}
+	}
+	return g.refs(idxs)
+}
+
+// intervalRefs returns a comma-separated list of references to the intervals
+// with the given indexes. Intervals are always declared before the constraints
+// referring to them.
+func (g *generator) intervalRefs(idxs []int32) string {
+	var refs []string
+	for _, idx := range idxs {
+		refs = append(refs, fmt.Sprintf("i%d", idx))
+	}
+	return strings.Join(refs, ", ")
+}
+
+// linearExpr returns the construction of the given linear expression.
+func (g *generator) linearExpr(vars []int32, coeffs []int64, offset int64) string {
+	return fmt.Sprintf("solver.NewLinearExpr([]solver.IntVar{%s}, []int64{%s}, %d)",
+		g.refs(vars), int64sSrc(coeffs), offset)
+}
+
+// linearExprs returns a comma-separated list of constructions of the given
+// linear expressions, starting with the target.
+func (g *generator) linearExprs(target *pb.LinearExpressionProto, exprs []*pb.LinearExpressionProto) string {
+	var res []string
+	for _, e := range append([]*pb.LinearExpressionProto{target}, exprs...) {
+		res = append(res, g.linearExpr(e.GetVars(), e.GetCoeffs(), e.GetOffset()))
+	}
+	return strings.Join(res, ", ")
+}
+
+// domainSrc returns the construction of the domain with the given list of
+// interval boundaries.
+func domainSrc(d []int64) string {
+	return fmt.Sprintf("solver.NewDomain(%s)", int64sSrc(d))
+}
+
+// int32sSrc returns a comma-separated list of the given integers.
+func int32sSrc(is []int32) string {
+	var res []string
+	for _, i := range is {
+		res = append(res, fmt.Sprint(i))
+	}
+	return strings.Join(res, ", ")
+}
+
+// int64sSrc returns a comma-separated list of the given integers.
+func int64sSrc(is []int64) string {
+	var res []string
+	for _, i := range is {
+		res = append(res, fmt.Sprint(i))
+	}
+	return strings.Join(res, ", ")
+}
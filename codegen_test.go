@@ -0,0 +1,137 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGo(t *testing.T) {
+	model := NewModel("test")
+	x := model.NewIntVarFromDomain(NewDomain(0, 2, 5, 6), "x")
+	y := model.NewIntVar(0, 10, "y")
+	_ = model.NewIntVar(0, 10, "unused")
+	c := model.NewConstant(4, "c")
+	a, b := model.NewLiteral("a"), model.NewLiteral("b")
+	i := model.NewInterval(x, y, c, "i")
+	j := model.NewInterval(x, y, c, "j")
+
+	model.AddConstraints(
+		NewAllDifferentConstraint(x, y),
+		NewImplicationConstraint(a, b),
+		NewLinearConstraint(NewLinearExpr([]IntVar{x, y}, []int64{1, 2}, 1), NewDomain(0, 10)).OnlyEnforceIf(a),
+		NewAllowedAssignmentsConstraint([]IntVar{x, y}, [][]int64{{0, 1}, {2, 3}}),
+		NewNonOverlappingConstraint(i, j).WithName("no-overlap"),
+	)
+	model.Maximize(Sum(x, y))
+	model.AddHint(a, 1)
+
+	src, err := model.GenerateGo("repro")
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by solver.Model.GenerateGo. DO NOT EDIT.
+
+package repro
+
+import "github.com/irfansharif/solver"
+
+// NewModel reconstructs the "test" model.
+func NewModel() *solver.Model {
+	model := solver.NewModel("test")
+	v0 := model.NewIntVarFromDomain(solver.NewDomain(0, 2, 5, 6), "x")
+	v1 := model.NewIntVarFromDomain(solver.NewDomain(0, 10), "y")
+	model.NewIntVarFromDomain(solver.NewDomain(0, 10), "unused")
+	v3 := model.NewConstant(4, "c")
+	v4 := model.NewLiteral("a")
+	v5 := model.NewLiteral("b")
+	i0 := model.NewInterval(v0, v1, v3, "i")
+	i1 := model.NewInterval(v0, v1, v3, "j")
+	model.AddConstraints(solver.NewAllDifferentConstraint(v0, v1))
+	model.AddConstraints(solver.NewBooleanOrConstraint(v4.Not(), v5))
+	model.AddConstraints(solver.NewLinearConstraint(solver.NewLinearExpr([]solver.IntVar{v0, v1}, []int64{1, 2}, 0), solver.NewDomain(-1, 9)).OnlyEnforceIf(v4))
+	model.AddConstraints(solver.NewAllowedAssignmentsConstraint([]solver.IntVar{v0, v1}, [][]int64{{0, 1}, {2, 3}}))
+	model.AddConstraints(solver.NewNonOverlappingConstraint(i0, i1).WithName("no-overlap"))
+	model.Maximize(solver.NewLinearExpr([]solver.IntVar{v0, v1}, []int64{1, 1}, 0))
+	model.AddHint(v4, 1)
+	return model
+}
+`, string(src))
+}
+
+func TestGenerateGoRoutes(t *testing.T) {
+	model := NewModel("test")
+	a, b := model.NewLiteral("a"), model.NewLiteral("b")
+	model.AddConstraints(NewRoutesConstraint([]int{0, 1}, []int{1, 0}, []Literal{a, b.Not()}))
+
+	src, err := model.GenerateGo("repro")
+	require.NoError(t, err)
+	require.Contains(t, string(src),
+		"model.AddConstraints(solver.NewRoutesConstraint([]int{0, 1}, []int{1, 0}, []solver.Literal{v0, v1.Not()}))")
+}
+
+func TestGenerateGoAutomaton(t *testing.T) {
+	model := NewModel("test")
+	x, y := model.NewIntVar(0, 1, "x"), model.NewIntVar(0, 1, "y")
+	model.AddConstraints(NewAutomatonConstraint([]IntVar{x, y}, 0, []int64{1}, []Transition{
+		{Tail: 0, Head: 1, Label: 1},
+		{Tail: 1, Head: 1, Label: 0},
+	}))
+
+	src, err := model.GenerateGo("repro")
+	require.NoError(t, err)
+	require.Contains(t, string(src), "model.AddConstraints(solver.NewAutomatonConstraint("+
+		"[]solver.IntVar{v0, v1}, 0, []int64{1}, []solver.Transition{{Tail: 0, Head: 1, Label: 1}, {Tail: 1, Head: 1, Label: 0}}))")
+}
+
+func TestGenerateGoFixedLiterals(t *testing.T) {
+	// Literals fixed since they were instantiated are still declared as
+	// literals, since they're referenced as such.
+	model := NewModel("test")
+	a, b := model.NewLiteral("a"), model.NewLiteral("b")
+	model.AddConstraints(NewBooleanOrConstraint(a.Not(), b))
+	SetDomainChange(a, NewDomain(1, 1))(model)
+
+	src, err := model.GenerateGo("repro")
+	require.NoError(t, err)
+	require.Contains(t, string(src), `v0 := model.NewLiteral("a")
+	solver.SetDomainChange(v0, solver.NewDomain(1, 1))(model)
+	v1 := model.NewLiteral("b")
+	model.AddConstraints(solver.NewBooleanOrConstraint(v0.Not(), v1))`)
+
+	// Literals with non-boolean domains can't be reconstructed.
+	SetDomainChange(b, NewDomain(2, 2))(model)
+	_, err = model.GenerateGo("repro")
+	require.EqualError(t, err, "variable 1: referenced as a literal, with domain [2, 2]")
+}
+
+func TestGenerateGoSearch(t *testing.T) {
+	model := NewModel("test")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	a := model.NewLiteral("a")
+	model.AddAssumptions(a.Not())
+	model.AddDecisionStrategy([]IntVar{y, x}, ChooseMinDomainSize, MaxValueFirst)
+
+	src, err := model.GenerateGo("repro")
+	require.NoError(t, err)
+	require.Contains(t, string(src), `	model.AddAssumptions(v2.Not())
+	model.AddDecisionStrategy([]solver.IntVar{v1, v0}, solver.ChooseMinDomainSize, solver.MaxValueFirst)
+`)
+
+	model.pb.SearchStrategy[0].Transformations = []*pb.DecisionStrategyProto_AffineTransformation{{Index: 0}}
+	_, err = model.GenerateGo("repro")
+	require.EqualError(t, err, "decision strategy 0: unsupported affine transformations")
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "github.com/irfansharif/solver/pb"
+
+// Compact drops the model's variables that aren't referred to anywhere in it,
+// renumbering the remaining ones in place, and returns the number dropped.
+// Linear expressions constructed beforehand are to be reconstructed.
+func (m *Model) Compact() int {
+	m.assertMutable()
+	if !m.introspect() {
+		m.fail("compact: unsupported for models without introspection")
+		return 0
+	}
+
+	usages := m.UsageCounts()
+	keep := make([]bool, len(usages))
+	for i, u := range usages {
+		keep[i] = u.Count > 0
+	}
+	mark := func(refs ...int32) {
+		for _, r := range refs {
+			if r < 0 {
+				r = -r - 1
+			}
+			keep[r] = true
+		}
+	}
+	// Constraints disabled through their handles can be enabled again, so
+	// they're kept up to date too.
+	var masked []*pb.ConstraintProto
+	for _, h := range m.handles {
+		for _, ct := range h.masked {
+			masked = append(masked, ct)
+		}
+	}
+	for _, ct := range masked {
+		refs := referencesOf(ct)
+		mark(refs.variables...)
+		mark(refs.literals...)
+		for _, i := range refs.intervals {
+			irefs := referencesOf(m.pb.GetConstraints()[i])
+			mark(irefs.variables...)
+			mark(irefs.literals...)
+		}
+	}
+	mark(m.pb.GetObjective().GetVars()...)
+	mark(m.pb.GetAssumptions()...)
+	for _, s := range m.pb.GetSearchStrategy() {
+		mark(s.GetVariables()...)
+	}
+
+	renumbered := make([]int32, len(keep)) // old index => new index, or -1 if dropped
+	var variables []*pb.IntegerVariableProto
+	for i, v := range m.pb.GetVariables() {
+		renumbered[i] = -1
+		if keep[i] {
+			renumbered[i] = int32(len(variables))
+			variables = append(variables, v)
+		}
+	}
+	dropped := len(keep) - len(variables)
+	if dropped == 0 {
+		return 0
+	}
+	remap := func(ref int32) int32 {
+		if ref < 0 {
+			return -renumbered[-ref-1] - 1
+		}
+		return renumbered[ref]
+	}
+
+	m.pb.Variables = variables
+	for _, ct := range m.pb.GetConstraints() {
+		remapReferences(ct, remap)
+	}
+	for _, ct := range masked {
+		remapReferences(ct, remap)
+	}
+	if objective := m.pb.GetObjective(); objective != nil {
+		for i, r := range objective.Vars {
+			objective.Vars[i] = remap(r)
+		}
+	}
+	for _, s := range m.pb.GetSearchStrategy() {
+		for i, r := range s.Variables {
+			s.Variables[i] = remap(r)
+		}
+	}
+	for i, r := range m.pb.GetAssumptions() {
+		m.pb.Assumptions[i] = remap(r)
+	}
+	if hint := m.pb.GetSolutionHint(); hint != nil {
+		compacted := &pb.PartialVariableAssignment{}
+		for i, idx := range hint.GetVars() {
+			if renumbered[idx] >= 0 {
+				compacted.Vars = append(compacted.Vars, renumbered[idx])
+				compacted.Values = append(compacted.Values, hint.GetValues()[i])
+			}
+		}
+		m.pb.SolutionHint = compacted
+	}
+
+	// Renumber the variables we hold onto, and stop holding onto the dropped
+	// ones.
+	renumber := func(vs []IntVar) []IntVar {
+		var kept []IntVar
+		for _, v := range vs {
+			iv := v.(*intVar)
+			if renumbered[iv.idx] < 0 {
+				continue
+			}
+			iv.idx = renumbered[iv.idx]
+			if iv.negation != nil {
+				iv.negation.idx = -iv.idx - 1
+			}
+			kept = append(kept, iv)
+		}
+		return kept
+	}
+	m.vars = renumber(m.vars)
+	m.constants = renumber(m.constants)
+	literals := renumber(AsIntVars(m.literals))
+	m.literals = nil
+	for _, l := range literals {
+		m.literals = append(m.literals, l.(Literal))
+	}
+	if m.objective != nil {
+		vars, coeffs, offset := m.objective.Parameters()
+		m.objective = NewLinearExpr(vars, coeffs, offset)
+	}
+	if m.callers != nil {
+		locations := make(map[int32]string, len(m.callers.vars))
+		for idx, loc := range m.callers.vars {
+			if renumbered[idx] >= 0 {
+				locations[renumbered[idx]] = loc
+			}
+		}
+		m.callers.vars = locations
+	}
+	return dropped
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	model := NewModel("", WithCallerTracking())
+	model.NewIntVar(0, 10, "unused")
+	x := model.NewIntVar(0, 10, "x")
+	hinted := model.NewLiteral("hinted")
+	l := model.NewLiteral("l")
+	assumed := model.NewLiteral("assumed")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 10)).OnlyEnforceIf(l.Not()))
+	model.AddHint(hinted, 1)
+	model.AddHint(y, 3)
+	model.AddAssumptions(assumed.Not())
+	model.Maximize(Sum(x).Add(Sum(y)))
+
+	require.Equal(t, 2, model.Compact())
+	require.Equal(t, 0, model.Compact())
+	require.Len(t, model.pb.GetVariables(), 4)
+	require.Equal(t, []IntVar{x, l, assumed, y}, model.variables())
+	require.Equal(t, []int32{0, 3}, model.pb.GetConstraints()[0].GetLinear().GetVars())
+	require.Equal(t, []int32{-2}, model.pb.GetConstraints()[0].GetEnforcementLiteral())
+	require.Equal(t, int32(-2), l.Not().index())
+	require.Equal(t, []int32{-3}, model.pb.GetAssumptions())
+	require.Equal(t, []int32{3}, model.pb.GetSolutionHint().GetVars())
+	require.Equal(t, []int32{0, 3}, model.pb.GetObjective().GetVars())
+	require.NotContains(t, model.String(), "unused")
+	require.NotContains(t, model.String(), "hinted")
+	require.Len(t, model.callers.vars, 4)
+
+	// The compacted model can be built upon using the variables kept.
+	z := model.NewIntVar(0, 10, "z")
+	model.AddConstraints(NewLinearConstraint(Sum(x, z), NewDomain(0, 5)))
+	require.Equal(t, []int32{0, 4}, model.pb.GetConstraints()[1].GetLinear().GetVars())
+	require.NoError(t, model.CheckReferences())
+
+	model = NewModel("", WithoutIntrospection(), WithConstructionErrors())
+	model.Compact()
+	require.Error(t, model.Err())
+}
+
+func TestCompactDisabledConstraints(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	z := model.NewIntVar(0, 10, "z")
+	model.NewIntVar(0, 10, "unused")
+	model.AddConstraints(NewLinearConstraint(Sum(z), NewDomain(0, 5)))
+	handle := model.AddConstraintHandles(NewLinearConstraint(Sum(x, y), NewDomain(1, 2)))[0]
+
+	// Variables referred to by disabled constraints are kept, and the
+	// constraints refer to them once enabled again.
+	handle.Disable()
+	require.Equal(t, 1, model.Compact())
+	handle.Enable()
+	require.Equal(t, []int32{0, 1}, model.pb.GetConstraints()[1].GetLinear().GetVars())
+	require.NoError(t, model.CheckReferences())
+	require.Equal(t, []IntVar{x, y, z}, model.variables())
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "sync"
+
+// ConcurrentModel is a view of a model that's safe for concurrent use, for
+// constructing (huge) models from multiple goroutines, parallel data pipelines
+// say. Adding variables, constraints, hints and assumptions is serialized
+// internally. Constraints themselves can be constructed concurrently using the
+// package-level constructors (NewProductConstraint, say); those that
+// instantiate auxiliary variables do so through this view. Variables are
+// indexed in the order they're added, so models built concurrently aren't
+// deterministic.
+//
+// Things that mutate existing variables, intervals or constraints (enforcing
+// an interval, disabling a constraint) aren't synchronized, and are to be done
+// by the goroutine that added them. The underlying model is to be used
+// directly (to set an objective, to construct constraints using its methods,
+// like NewMinGapConstraint, or to solve it) only once concurrent construction
+// is done.
+type ConcurrentModel struct {
+	m *Model
+
+	mu sync.Mutex
+	// errsMu guards the underlying model's construction errors (see
+	// WithConstructionErrors), which are also recorded when constructing
+	// constraints.
+	errsMu sync.Mutex
+}
+
+// Concurrent returns a view of the model that's safe for concurrent use,
+// the same one every time. Models used concurrently don't batch-allocate
+// constraint protos (see WithArena), since arenas aren't safe for concurrent
+// use. Literals added to models without introspection (see
+// WithoutIntrospection) before the call are to be negated (see Literal.Not)
+// before being used concurrently; the model doesn't hold onto them to do so
+// itself.
+func (m *Model) Concurrent() *ConcurrentModel {
+	m.assertMutable()
+	if m.concurrent == nil {
+		m.arena = nil
+		m.concurrent = &ConcurrentModel{m: m}
+
+		// Literals are negated lazily, which constructing constraints can do
+		// from multiple goroutines; do so upfront for the ones added so far
+		// (see ConcurrentModel.NewLiteral).
+		for _, l := range m.literals {
+			l.Not()
+		}
+		for _, iv := range m.vars {
+			if iv.(*intVar).isLiteral {
+				iv.(*intVar).Not()
+			}
+		}
+	}
+	return m.concurrent
+}
+
+// Model returns the underlying model.
+func (c *ConcurrentModel) Model() *Model {
+	return c.m
+}
+
+// NewLiteral is like Model.NewLiteral.
+func (c *ConcurrentModel) NewLiteral(name string) Literal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := c.m.NewLiteral(name)
+	// Literals are negated lazily, which constructing constraints can do from
+	// multiple goroutines; do so upfront.
+	l.Not()
+	return l
+}
+
+// NewConstant is like Model.NewConstant.
+func (c *ConcurrentModel) NewConstant(v int64, name string) IntVar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewConstant(v, name)
+}
+
+// NewIntVar is like Model.NewIntVar.
+func (c *ConcurrentModel) NewIntVar(lb int64, ub int64, name string) IntVar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewIntVar(lb, ub, name)
+}
+
+// NewIntVarFromDomain is like Model.NewIntVarFromDomain.
+func (c *ConcurrentModel) NewIntVarFromDomain(d Domain, name string) IntVar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewIntVarFromDomain(d, name)
+}
+
+// NewInterval is like Model.NewInterval.
+func (c *ConcurrentModel) NewInterval(start, end, size IntVar, name string) Interval {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewInterval(start, end, size, name)
+}
+
+// NewFixedSizeInterval is like Model.NewFixedSizeInterval.
+func (c *ConcurrentModel) NewFixedSizeInterval(start IntVar, size int64, name string) Interval {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewFixedSizeInterval(start, size, name)
+}
+
+// NewFixedInterval is like Model.NewFixedInterval.
+func (c *ConcurrentModel) NewFixedInterval(start, end int64, name string) Interval {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NewFixedInterval(start, end, name)
+}
+
+// AddConstraints is like Model.AddConstraints.
+func (c *ConcurrentModel) AddConstraints(cs ...Constraint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.AddConstraints(cs...)
+}
+
+// AddHint is like Model.AddHint.
+func (c *ConcurrentModel) AddHint(iv IntVar, value int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.AddHint(iv, value)
+}
+
+// AddAssumptions is like Model.AddAssumptions.
+func (c *ConcurrentModel) AddAssumptions(literals ...Literal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.AddAssumptions(literals...)
+}
+
+// newAuxLiteral instantiates an auxiliary literal on behalf of a constraint
+// being constructed. Constraints can be constructed concurrently when the
+// model is used through a ConcurrentModel, so it's instantiated through it.
+func (m *Model) newAuxLiteral(name string) Literal {
+	if m.concurrent != nil {
+		return m.concurrent.NewLiteral(name)
+	}
+	return m.NewLiteral(name)
+}
+
+// newAuxIntVar is like newAuxLiteral, for integer variables.
+func (m *Model) newAuxIntVar(lb, ub int64, name string) IntVar {
+	if m.concurrent != nil {
+		return m.concurrent.NewIntVar(lb, ub, name)
+	}
+	return m.NewIntVar(lb, ub, name)
+}
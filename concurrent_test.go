@@ -0,0 +1,118 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentModel(t *testing.T) {
+	model := NewModel("", WithArena(0), WithConstructionErrors())
+	c := model.Concurrent()
+	require.Equal(t, c, model.Concurrent())
+	require.Equal(t, model, c.Model())
+	require.Nil(t, model.arena)
+
+	const workers, perWorker = 8, 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				x := c.NewIntVar(0, 10, fmt.Sprintf("x%d.%d", w, i))
+				l := c.NewLiteral(fmt.Sprintf("l%d.%d", w, i))
+				c.AddConstraints(
+					NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(l.Not()),
+					NewMinDistanceConstraint(x, x, -1), // invalid
+				)
+				c.NewFixedSizeInterval(x, 2, "")
+				c.AddHint(x, int64(i%10))
+				c.AddAssumptions(l)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	require.Len(t, model.literals, workers*perWorker)
+	require.Len(t, model.pb.GetSolutionHint().GetVars(), workers*perWorker)
+	require.Len(t, model.pb.GetAssumptions(), workers*perWorker)
+	require.Len(t, model.intervals, workers*perWorker)
+	require.Len(t, model.errs, workers*perWorker)
+	for _, l := range model.literals {
+		require.Equal(t, "l", l.name()[:1])
+	}
+}
+
+func TestConcurrentModelAuxiliaryVariables(t *testing.T) {
+	model := NewModel("")
+	c := model.Concurrent()
+
+	const workers, perWorker = 4, 50
+	x, y, z := c.NewIntVar(1, 2, "x"), c.NewIntVar(1, 2, "y"), c.NewIntVar(1, 2, "z")
+	targets := make([]IntVar, workers)
+	for w := range targets {
+		targets[w] = c.NewIntVar(0, 100, "")
+	}
+
+	// Constraints that instantiate auxiliary variables are constructed
+	// concurrently, only being added to the model afterwards.
+	cs := make([][]Constraint, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				cs[w] = append(cs[w],
+					NewProductConstraint(targets[w], x, y, z),                          // instantiates a partial product
+					NewHammingDistanceConstraint(targets[w], []IntVar{x}, []IntVar{y}), // instantiates a literal
+				)
+			}
+		}(w)
+	}
+	wg.Wait()
+	for w := range cs {
+		c.AddConstraints(cs[w]...)
+	}
+
+	require.Len(t, model.pb.GetVariables(), 3+workers+2*workers*perWorker)
+	require.Len(t, model.vars, 3+workers+workers*perWorker)
+	require.Len(t, model.literals, workers*perWorker)
+}
+
+func TestConcurrentModelExistingLiterals(t *testing.T) {
+	// Literals added before the model is used concurrently are negated
+	// upfront, so negating them concurrently doesn't race.
+	model := NewModel("")
+	l := model.NewLiteral("l")
+	c := model.Concurrent()
+	require.NotNil(t, l.(*intVar).negation)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.AddConstraints(NewBooleanOrConstraint(l.Not()))
+		}()
+	}
+	wg.Wait()
+	require.Len(t, model.pb.GetConstraints(), 4)
+}
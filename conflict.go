@@ -0,0 +1,78 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// AddConflictGraph ensures that no two literals joined by an edge are true at
+// the same time, as used to model pairwise incompatibilities (meetings that
+// can't share a room, shifts that can't be worked back-to-back, etc). Instead
+// of adding an at-most-one constraint per edge, edges are first covered using
+// cliques (sets of literals that conflict pairwise), with an at-most-one
+// constraint per clique. This results in far fewer (and tighter) constraints
+// for dense graphs. The constraints added are returned.
+//
+// Cliques are found greedily: for each edge not yet covered, we grow a clique
+// from its endpoints, considering literals in the order they first appear in
+// the list of edges.
+func (m *Model) AddConflictGraph(edges [][2]Literal) []Constraint {
+	var order []Literal // literals in the order they first appear
+	adjacent := make(map[int32]map[int32]bool)
+	for _, edge := range edges {
+		u, v := edge[0], edge[1]
+		if u.index() == v.index() {
+			m.fail("conflict graph: self-loop on %s", u.name())
+			continue
+		}
+		for _, l := range edge {
+			if adjacent[l.index()] == nil {
+				adjacent[l.index()] = make(map[int32]bool)
+				order = append(order, l)
+			}
+		}
+		adjacent[u.index()][v.index()] = true
+		adjacent[v.index()][u.index()] = true
+	}
+
+	type pair struct{ u, v int32 }
+	covered := make(map[pair]bool)
+	var cs []Constraint
+	for _, edge := range edges {
+		u, v := edge[0], edge[1]
+		if u.index() == v.index() || covered[pair{u.index(), v.index()}] {
+			continue
+		}
+
+		clique := []Literal{u, v}
+		for _, candidate := range order {
+			member := true
+			for _, l := range clique {
+				if !adjacent[candidate.index()][l.index()] {
+					member = false // also excludes members themselves
+					break
+				}
+			}
+			if member {
+				clique = append(clique, candidate)
+			}
+		}
+		for i := range clique {
+			for j := range clique {
+				covered[pair{clique[i].index(), clique[j].index()}] = true
+			}
+		}
+		cs = append(cs, NewAtMostKConstraint(1, clique...))
+	}
+	m.AddConstraints(cs...)
+	return cs
+}
@@ -18,7 +18,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/internal"
+	"github.com/irfansharif/solver/pb"
 )
 
 // Constraint is what a model attempts to satisfy when deciding on a solution.
@@ -39,12 +40,9 @@ type Constraint interface {
 	fmt.Stringer
 
 	// WithName sets a name for the constraint; used for debugging/logging
-	// purposes.
-	//
-	// TODO(irfansharif): We don't use it in our pretty printing, yet. That
-	// aside it would be nice to have a unified way to name things. For intvars,
-	// literal, intervals -- it's provided as part of initializer. We use
-	// separate things for models and constraints.
+	// purposes. Named constraints are printed with their names, as are named
+	// variables (see IntVar.WithName) and intervals, whose names are otherwise
+	// provided when they're instantiated.
 	WithName(name string) Constraint
 
 	// protos returns the underlying CP-SAT constraint protobuf representations.
@@ -62,6 +60,10 @@ type constraint struct {
 	str string
 
 	enforcement []Literal
+
+	// explicit is set for constraints constructed using AlwaysTrue or
+	// AlwaysFalse, which aren't degenerate despite their empty inputs.
+	explicit bool
 }
 
 // WithName is part of the Constraint interface.
@@ -77,6 +79,9 @@ func (c *constraint) String() string {
 	}
 
 	var b strings.Builder
+	if name := c.pb.GetName(); name != "" {
+		b.WriteString(fmt.Sprintf("%s: ", quoteName(name)))
+	}
 	b.WriteString(c.str)
 	if len(c.enforcement) != 0 {
 		b.WriteString(" if (")
@@ -99,14 +104,14 @@ func NewAllDifferentConstraint(vars ...IntVar) Constraint {
 	b.WriteString("all-different: ")
 	printVars(&b, vars...)
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_AllDiff{
-				AllDiff: &pb.AllDifferentConstraintProto{
-					Vars: intVarList(vars).indexes(),
-				},
-			},
+	ct := arenaFor(vars...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_AllDiff{
+		AllDiff: &pb.AllDifferentConstraintProto{
+			Vars: intVarList(vars).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
@@ -127,6 +132,51 @@ func NewAllSameConstraint(vars ...IntVar) Constraint {
 	return constraints{cs: cs, str: b.String()}
 }
 
+// NewIncreasingConstraint forces the variables to take on increasing values,
+// in the order given. If strict, no two consecutive variables can take on the
+// same value.
+func NewIncreasingConstraint(strict bool, vars ...IntVar) Constraint {
+	return newMonotonicConstraint("increasing", strict, vars, func(prev, next IntVar) LinearExpr {
+		return NewLinearExpr([]IntVar{next, prev}, []int64{1, -1}, 0)
+	})
+}
+
+// NewDecreasingConstraint forces the variables to take on decreasing values,
+// in the order given. If strict, no two consecutive variables can take on the
+// same value.
+func NewDecreasingConstraint(strict bool, vars ...IntVar) Constraint {
+	return newMonotonicConstraint("decreasing", strict, vars, func(prev, next IntVar) LinearExpr {
+		return NewLinearExpr([]IntVar{prev, next}, []int64{1, -1}, 0)
+	})
+}
+
+// newMonotonicConstraint constrains the step between consecutive variables,
+// as computed by the given function, to be non-negative (or positive, if
+// strict).
+func newMonotonicConstraint(kind string, strict bool, vars []IntVar, step func(prev, next IntVar) LinearExpr) Constraint {
+	var b strings.Builder
+	if strict {
+		b.WriteString("strictly-")
+	}
+	b.WriteString(fmt.Sprintf("%s: ", kind))
+	printVars(&b, vars...)
+
+	lb := int64(0)
+	if strict {
+		lb = 1
+	}
+	var cs []Constraint
+	for i := 1; i < len(vars); i++ {
+		e := step(vars[i-1], vars[i])
+		_, ub := linearBounds(e)
+		if ub < lb {
+			ub = lb // the step can't be large enough, we're infeasible regardless
+		}
+		cs = append(cs, NewLinearConstraint(e, NewDomain(lb, ub)))
+	}
+	return constraints{cs: cs, str: b.String()}
+}
+
 // NewAtMostKConstraint ensures that no more than k literals are true.
 func NewAtMostKConstraint(k int, literals ...Literal) Constraint {
 	var c Constraint
@@ -184,58 +234,61 @@ func NewExactlyKConstraint(k int, literals ...Literal) Constraint {
 	return c
 }
 
-// NewBooleanAndConstraint ensures that all literals are true.
+// NewBooleanAndConstraint ensures that all literals are true. With no
+// literals, it's always satisfied; see DegeneratePolicy.
 func NewBooleanAndConstraint(literals ...Literal) Constraint {
 	var b strings.Builder
 	b.WriteString("boolean-and: ")
 	printLiterals(&b, literals...)
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_BoolAnd{
-				BoolAnd: &pb.BoolArgumentProto{
-					Literals: asIntVars(literals).indexes(),
-				},
-			},
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_BoolAnd{
+		BoolAnd: &pb.BoolArgumentProto{
+			Literals: asIntVars(literals).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
 
 // NewBooleanOrConstraint ensures that at least one literal is true. It can be
 // thought of as a special case of NewAtLeastKConstraint, but one that uses a
-// more efficient internal encoding.
+// more efficient internal encoding. With no literals, it's never satisfied;
+// see DegeneratePolicy.
 func NewBooleanOrConstraint(literals ...Literal) Constraint {
 	var b strings.Builder
 	b.WriteString("boolean-or: ")
 	printLiterals(&b, literals...)
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_BoolOr{
-				BoolOr: &pb.BoolArgumentProto{
-					Literals: asIntVars(literals).indexes(),
-				},
-			},
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_BoolOr{
+		BoolOr: &pb.BoolArgumentProto{
+			Literals: asIntVars(literals).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
 
 // NewBooleanXorConstraint ensures that an odd number of the literals are true.
+// With no literals, it's never satisfied; see DegeneratePolicy.
 func NewBooleanXorConstraint(literals ...Literal) Constraint {
 	var b strings.Builder
 	b.WriteString("boolean-xor: ")
 	printLiterals(&b, literals...)
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_BoolXor{
-				BoolXor: &pb.BoolArgumentProto{
-					Literals: asIntVars(literals).indexes(),
-				},
-			},
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_BoolXor{
+		BoolXor: &pb.BoolArgumentProto{
+			Literals: asIntVars(literals).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
@@ -251,37 +304,39 @@ func NewImplicationConstraint(a, b Literal) Constraint {
 // NewAllowedLiteralAssignmentsConstraint ensures that the values of the n-tuple
 // formed by the given literals is one of the listed n-tuple assignments.
 func NewAllowedLiteralAssignmentsConstraint(literals []Literal, assignments [][]bool) Constraint {
-	return newLiteralAssignmentsConstraintInternal(literals, assignments)
+	return newLiteralAssignmentsConstraintInternal(literals, assignments, false)
 }
 
 // NewForbiddenLiteralAssignmentsConstraint ensures that the values of the
 // n-tuple formed by the given literals is not one of the listed n-tuple
 // assignments.
 func NewForbiddenLiteralAssignmentsConstraint(literals []Literal, assignments [][]bool) Constraint {
-	c := newLiteralAssignmentsConstraintInternal(literals, assignments)
-	c.pb.GetTable().Negated = true
-	return c
+	return newLiteralAssignmentsConstraintInternal(literals, assignments, true)
 }
 
 // NewDivisionConstraint ensures that the target is to equal to
 // numerator/denominator. It also ensures that the denominator is non-zero.
 func NewDivisionConstraint(target, numerator, denominator IntVar) Constraint {
+	a := arenaFor(target, numerator, denominator)
+	ct := a.constraintProto()
+	ct.Constraint = &pb.ConstraintProto_IntDiv{
+		IntDiv: a.integerArgumentProto(target, numerator, denominator),
+	}
 	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_IntDiv{
-				IntDiv: &pb.IntegerArgumentProto{
-					Target: target.index(),
-					Vars:   intVarList([]IntVar{numerator, denominator}).indexes(),
-				},
-			},
-		},
+		pb:  ct,
 		str: fmt.Sprintf("%s == %s / %s", target.name(), numerator.name(), denominator.name()),
 	}
 }
 
 // NewProductConstraint ensures that the target to equal to the product of all
 // multiplicands. An empty multiplicands list forces the target to be equal to
-// one.
+// one; see DegeneratePolicy.
+//
+// The underlying solver only reliably supports products of two multiplicands,
+// so larger products are decomposed into a chain of them, using intermediate
+// variables instantiated in the model right away (to capture partial
+// products). It fails if a partial product could overflow an int64 (see
+// WithConstructionErrors for an alternative to panicking).
 func NewProductConstraint(target IntVar, multiplicands ...IntVar) Constraint {
 	var b strings.Builder
 	for i, m := range multiplicands {
@@ -290,81 +345,114 @@ func NewProductConstraint(target IntVar, multiplicands ...IntVar) Constraint {
 		}
 		b.WriteString(m.name())
 	}
+	str := fmt.Sprintf("%s == %s", target.name(), b.String())
+
+	vars := append([]IntVar{target}, multiplicands...)
+	m := modelFor(vars...)
+	if len(multiplicands) <= 2 || m == nil {
+		// Without a model to instantiate intermediate variables in, we can't
+		// decompose the product.
+		return newProductConstraintInternal(target, multiplicands, str)
+	}
+
+	name := protoName(target)
+	var cs []Constraint
+	partial := multiplicands[0]
+	for i := 1; i < len(multiplicands); i++ {
+		factors := []IntVar{partial, multiplicands[i]}
+		lb, ub, ok := productBounds(factors)
+		if !ok {
+			return invalidConstraint(vars, "product (%s): possible integer overflow (partial product #%d)", str, i)
+		}
+		if i == len(multiplicands)-1 {
+			cs = append(cs, newProductConstraintInternal(target, factors, str))
+			break
+		}
+		next := m.newAuxIntVar(lb, ub, derivedName(name, fmt.Sprintf("partial%d", i)))
+		cs = append(cs, newProductConstraintInternal(next, factors, ""))
+		partial = next
+	}
+	return constraints{cs: cs, str: str}
+}
+
+// NewPowerConstraint ensures that the target is equal to base^exponent. It's
+// expressed as a product (see NewProductConstraint), with the base repeated
+// exponent times. The exponent can't be negative.
+func NewPowerConstraint(target, base IntVar, exponent int) Constraint {
+	str := fmt.Sprintf("%s == %s^%d", target.name(), base.name(), exponent)
+	var c Constraint
+	switch {
+	case exponent < 0:
+		return invalidConstraint([]IntVar{target, base}, "power (%s): negative exponent", str)
+	case exponent == 0:
+		c = NewLinearConstraint(Sum(target), NewDomain(1, 1))
+	case exponent == 1:
+		c = NewEqualityConstraint(Sum(target), Sum(base))
+	default:
+		multiplicands := make([]IntVar, exponent)
+		for i := range multiplicands {
+			multiplicands[i] = base
+		}
+		c = NewProductConstraint(target, multiplicands...)
+	}
+	return constraints{cs: []Constraint{c}, str: str}
+}
 
+func newProductConstraintInternal(target IntVar, multiplicands []IntVar, str string) Constraint {
+	a := arenaFor(target)
+	ct := a.constraintProto()
+	ct.Constraint = &pb.ConstraintProto_IntProd{
+		IntProd: a.integerArgumentProto(target, multiplicands...),
+	}
 	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_IntProd{
-				IntProd: &pb.IntegerArgumentProto{
-					Target: target.index(),
-					Vars:   intVarList(multiplicands).indexes(),
-				},
-			},
-		},
-		str: fmt.Sprintf("%s == %s", target.name(), b.String()),
+		pb:  ct,
+		str: str,
 	}
 }
 
 // NewMaximumConstraint ensures that the target is equal to the maximum of all
-// variables.
+// variables. It's a special case of NewLinearMaximumConstraint.
 func NewMaximumConstraint(target IntVar, vars ...IntVar) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_IntMax{
-				IntMax: &pb.IntegerArgumentProto{
-					Target: target.index(),
-					Vars:   intVarList(vars).indexes(),
-				},
-			},
-		},
-	}
+	return NewLinearMaximumConstraint(Sum(target), intVarList(vars).linearExprs()...)
 }
 
 // NewMinimumConstraint ensures that the target is equal to the minimum of all
-// variables.
+// variables. It's a special case of NewLinearMinimumConstraint.
 func NewMinimumConstraint(target IntVar, vars ...IntVar) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_IntMin{
-				IntMin: &pb.IntegerArgumentProto{
-					Target: target.index(),
-					Vars:   intVarList(vars).indexes(),
-				},
-			},
-		},
-	}
+	return NewLinearMinimumConstraint(Sum(target), intVarList(vars).linearExprs()...)
 }
 
 // NewModuloConstraint ensures that the target to equal to dividend%divisor. The
 // domain of the divisor must be strictly positive.
 func NewModuloConstraint(target, dividend, divisor IntVar) Constraint {
 	if !divisor.domain().positive() {
-		panic("invalid domain for divisor: not strictly positive")
+		return invalidConstraint([]IntVar{target, dividend, divisor},
+			"modulo (%s == %s %% %s): invalid domain for divisor %s: not strictly positive",
+			target.name(), dividend.name(), divisor.name(), divisor)
+	}
+	a := arenaFor(target, dividend, divisor)
+	ct := a.constraintProto()
+	ct.Constraint = &pb.ConstraintProto_IntMod{
+		IntMod: a.integerArgumentProto(target, dividend, divisor),
 	}
 	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_IntMod{
-				IntMod: &pb.IntegerArgumentProto{
-					Target: target.index(),
-					Vars:   intVarList([]IntVar{dividend, divisor}).indexes(),
-				},
-			},
-		},
+		pb:  ct,
 		str: fmt.Sprintf("%s == %s %% %s", target.name(), dividend.name(), divisor.name()),
 	}
 }
 
 // NewAllowedAssignmentsConstraint ensures that the values of the n-tuple
 // formed by the given variables is one of the listed n-tuple assignments.
+// Assignments can use Wildcard for variables that can take on any value.
 func NewAllowedAssignmentsConstraint(vars []IntVar, assignments [][]int64) Constraint {
-	return newAssignmentsConstraintInternal(vars, assignments)
+	return newAssignmentsConstraintInternal(vars, assignments, false)
 }
 
 // NewForbiddenAssignmentsConstraint ensures that the values of the n-tuple
 // formed by the given variables is not one of the listed n-tuple assignments.
+// Assignments can use Wildcard for variables that can take on any value.
 func NewForbiddenAssignmentsConstraint(vars []IntVar, assignments [][]int64) Constraint {
-	c := newAssignmentsConstraintInternal(vars, assignments)
-	c.pb.GetTable().Negated = true
-	return c
+	return newAssignmentsConstraintInternal(vars, assignments, true)
 }
 
 // NewLinearConstraint ensures that the linear expression lies in the given
@@ -379,76 +467,207 @@ func NewLinearConstraint(e LinearExpr, d Domain) Constraint {
 	b.WriteString(" in ")
 	b.WriteString(d.String())
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_Linear{
-				Linear: &pb.LinearConstraintProto{
-					Vars:   e.vars(),
-					Coeffs: e.coeffs(),
-					Domain: d.list(e.offset()),
-				},
-			},
+	ct := arenaFor(linearExprList{e}.intVars()...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Linear{
+		Linear: &pb.LinearConstraintProto{
+			Vars:   e.vars(),
+			Coeffs: e.coeffs(),
+			Domain: d.list(e.offset()),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
 
+// NewWeightedSumConstraint ensures that the target is equal to the weighted
+// sum of the given variables, where coeffs[i] is the weight of vars[i]. Like
+// ScalProd, it validates its inputs.
+func NewWeightedSumConstraint(target IntVar, vars []IntVar, coeffs []int64) Constraint {
+	if len(vars) != len(coeffs) {
+		return invalidConstraint(append([]IntVar{target}, vars...),
+			"weighted-sum: mismatched number of variables (%d: %s) and coefficients (%d)",
+			len(vars), intVarList(vars).names(), len(coeffs))
+	}
+	sum := ScalProd(vars, coeffs)
+	c := NewEqualityConstraint(Sum(target), sum)
+	c.(*constraint).str = fmt.Sprintf("weighted-sum: %s == %s",
+		target.name(), sum.String()) // hijack the string representation
+	return c
+}
+
+// NewEqualityConstraint ensures that a == b.
+func NewEqualityConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint("==", a, b, func(lb, ub int64) Domain {
+		return NewDomain(0, 0)
+	})
+}
+
+// NewInequalityConstraint ensures that a != b.
+func NewInequalityConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint("!=", a, b, func(lb, ub int64) Domain {
+		switch {
+		case lb <= -1 && ub >= 1:
+			return NewDomain(lb, -1, 1, ub)
+		case lb <= -1:
+			return NewDomain(lb, -1)
+		case ub >= 1:
+			return NewDomain(1, ub)
+		default:
+			return NewDomain(1, 1)
+		}
+	})
+}
+
+// NewLessThanConstraint ensures that a < b.
+func NewLessThanConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint("<", a, b, func(lb, ub int64) Domain {
+		if lb > -1 {
+			lb = -1
+		}
+		return NewDomain(lb, -1)
+	})
+}
+
+// NewLessOrEqualConstraint ensures that a <= b.
+func NewLessOrEqualConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint("<=", a, b, func(lb, ub int64) Domain {
+		if lb > 0 {
+			lb = 0
+		}
+		return NewDomain(lb, 0)
+	})
+}
+
+// NewGreaterThanConstraint ensures that a > b.
+func NewGreaterThanConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint(">", a, b, func(lb, ub int64) Domain {
+		if ub < 1 {
+			ub = 1
+		}
+		return NewDomain(1, ub)
+	})
+}
+
+// NewGreaterOrEqualConstraint ensures that a >= b.
+func NewGreaterOrEqualConstraint(a, b LinearExpr) Constraint {
+	return newComparisonConstraint(">=", a, b, func(lb, ub int64) Domain {
+		if ub < 0 {
+			ub = 0
+		}
+		return NewDomain(0, ub)
+	})
+}
+
+// newComparisonConstraint constrains a - b to the domain computed by the given
+// function, using the bounds of a - b. Using the bounds (instead of
+// math.{Min,Max}Int64) keeps the domain from overflowing once shifted by the
+// expression's offset. If the comparison can't hold, the domain lies outside
+// the bounds; we're infeasible regardless.
+func newComparisonConstraint(op string, a, b LinearExpr, domain func(lb, ub int64) Domain) Constraint {
+	e := difference(a, b)
+	c := NewLinearConstraint(e, domain(linearBounds(e)))
+	c.(*constraint).str = fmt.Sprintf("%s %s %s",
+		a.String(), op, b.String()) // hijack the string representation
+	return c
+}
+
 // NewLinearMaximumConstraint ensures that the target is equal to the maximum of
-// all linear expressions.
+// all linear expressions. Constants and affine expressions can be used directly,
+// without needing auxiliary variables.
 func NewLinearMaximumConstraint(target LinearExpr, exprs ...LinearExpr) Constraint {
-	var b strings.Builder
-	b.WriteString("linear-max: ")
-	b.WriteString(fmt.Sprintf("%s == max(", target.String()))
-	for i, e := range exprs {
-		if i != 0 {
-			b.WriteString(", ")
-		}
-		b.WriteString(e.String())
+	ct := arenaFor(linearExprList{target}.intVars()...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_LinMax{
+		LinMax: &pb.LinearArgumentProto{
+			Target: target.proto(),
+			Exprs:  linearExprList(exprs).protos(),
+		},
 	}
-	b.WriteString(")")
-
 	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_LinMax{
-				LinMax: &pb.LinearArgumentProto{
-					Target: target.proto(),
-					Exprs:  linearExprList(exprs).protos(),
-				},
-			},
-		},
-		str: b.String(),
+		pb:  ct,
+		str: fmt.Sprintf("linear-max: %s == max(%s)", target.String(), linearExprList(exprs).String()),
 	}
 }
 
 // NewLinearMinimumConstraint ensures that the target is equal to the minimum of
-// all linear expressions.
+// all linear expressions. Constants and affine expressions can be used directly,
+// without needing auxiliary variables.
 func NewLinearMinimumConstraint(target LinearExpr, exprs ...LinearExpr) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_LinMin{
-				LinMin: &pb.LinearArgumentProto{
-					Target: target.proto(),
-					Exprs:  linearExprList(exprs).protos(),
-				},
-			},
+	ct := arenaFor(linearExprList{target}.intVars()...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_LinMin{
+		LinMin: &pb.LinearArgumentProto{
+			Target: target.proto(),
+			Exprs:  linearExprList(exprs).protos(),
 		},
 	}
+	return &constraint{
+		pb:  ct,
+		str: fmt.Sprintf("linear-min: %s == min(%s)", target.String(), linearExprList(exprs).String()),
+	}
+}
+
+// NewAbsConstraint ensures that the target is equal to the absolute value of
+// the given linear expression. It's expressed as the maximum of the expression
+// and its negation.
+func NewAbsConstraint(target LinearExpr, e LinearExpr) Constraint {
+	c := NewLinearMaximumConstraint(target, e, negate(e))
+	c.(*constraint).str = fmt.Sprintf("abs: %s == |%s|",
+		target.String(), e.String()) // hijack the string representation
+	return c
+}
+
+// NewMinDistanceConstraint ensures that |x - y| >= d. It's expressed as the
+// disjunction x - y <= -d or x - y >= d, which a single linear constraint over
+// a non-contiguous domain captures without auxiliary variables.
+func NewMinDistanceConstraint(x, y IntVar, d int64) Constraint {
+	if d < 0 {
+		return invalidConstraint([]IntVar{x, y}, "min-distance: negative distance %d", d)
+	}
+	c := newComparisonConstraint(">=", Sum(x), Sum(y), func(lb, ub int64) Domain {
+		switch {
+		case d == 0:
+			return NewDomain(lb, ub)
+		case lb <= -d && ub >= d:
+			return NewDomain(lb, -d, d, ub)
+		case lb <= -d:
+			return NewDomain(lb, -d)
+		case ub >= d:
+			return NewDomain(d, ub)
+		default:
+			return NewDomain(d, d) // we're infeasible regardless
+		}
+	})
+	c.(*constraint).str = fmt.Sprintf("min-distance: |%s - %s| >= %d",
+		x.name(), y.name(), d) // hijack the string representation
+	return c
+}
+
+// NewMaxDistanceConstraint ensures that |x - y| <= d.
+func NewMaxDistanceConstraint(x, y IntVar, d int64) Constraint {
+	if d < 0 {
+		return invalidConstraint([]IntVar{x, y}, "max-distance: negative distance %d", d)
+	}
+	c := newComparisonConstraint("<=", Sum(x), Sum(y), func(lb, ub int64) Domain {
+		return NewDomain(-d, d)
+	})
+	c.(*constraint).str = fmt.Sprintf("max-distance: |%s - %s| <= %d",
+		x.name(), y.name(), d) // hijack the string representation
+	return c
 }
 
 // NewElementConstraint ensures that the target is equal to vars[index].
 // Implicitly index takes on one of the values in [0, len(vars)).
 func NewElementConstraint(target, index IntVar, vars ...IntVar) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_Element{
-				Element: &pb.ElementConstraintProto{
-					Target: target.index(),
-					Index:  index.index(),
-					Vars:   intVarList(vars).indexes(),
-				},
-			},
+	ct := arenaFor(target, index).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Element{
+		Element: &pb.ElementConstraintProto{
+			Target: target.index(),
+			Index:  index.index(),
+			Vars:   intVarList(vars).indexes(),
 		},
 	}
+	return &constraint{pb: ct}
 }
 
 // NewNonOverlappingConstraint ensures that all the intervals are disjoint.
@@ -467,43 +686,82 @@ func NewNonOverlappingConstraint(intervals ...Interval) Constraint {
 		b.WriteString(fmt.Sprintf("{%s, %s}", start.name(), end.name()))
 	}
 
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_NoOverlap{
-				NoOverlap: &pb.NoOverlapConstraintProto{
-					Intervals: intervalList(intervals).indexes(),
-				},
-			},
+	ct := arenaFor(intervalList(intervals).starts()...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_NoOverlap{
+		NoOverlap: &pb.NoOverlapConstraintProto{
+			Intervals: intervalList(intervals).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: b.String(),
 	}
 }
 
+// NewSpanConstraint ensures that the cover interval spans the given intervals
+// exactly: it starts with the earliest start, and ends with the latest end.
+// It's typically used to compute the makespan of a set of tasks. Enforcement
+// literals on the given intervals aren't taken into account; intervals that
+// are absent are still spanned.
+func NewSpanConstraint(cover Interval, intervals ...Interval) Constraint {
+	start, end, _ := cover.Parameters()
+	if len(intervals) == 0 {
+		return invalidConstraint([]IntVar{start}, "span (%s): no intervals to span", cover.name())
+	}
+
+	return constraints{
+		cs: []Constraint{
+			NewMinimumConstraint(start, intervalList(intervals).starts()...),
+			NewMaximumConstraint(end, intervalList(intervals).ends()...),
+		},
+		str: fmt.Sprintf("span: %s | %s", cover.name(), intervalList(intervals).names()),
+	}
+}
+
 // NewNonOverlapping2DConstraint ensures that the boxes defined by the following
 // don't overlap:
 //
 // 		[xintervals[i].start, xintervals[i].end)
 // 		[yintervals[i].start, yintervals[i].end)
 //
-// Intervals/boxes of size zero are considered for overlap if the last argument
-// is true.
+// Intervals/boxes of size zero are allowed to overlap with other boxes if the
+// last argument is true.
+//
+// NB: OR-Tools v9.3 dropped support for the last argument, never letting boxes
+// of size zero overlap with boxes of non-zero size. When linked against newer
+// versions, we emulate the old behavior by leaving out boxes known to be of
+// size zero. If a box could have size zero but isn't fixed to it, the old
+// behavior can't be emulated and we panic (or record an error, see
+// WithConstructionErrors).
 func NewNonOverlapping2DConstraint(
 	xintervals []Interval,
 	yintervals []Interval,
 	boxesWithNoAreaCanOverlap bool,
 ) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_NoOverlap_2D{
-				NoOverlap_2D: &pb.NoOverlap2DConstraintProto{
-					XIntervals: intervalList(xintervals).indexes(),
-					YIntervals: intervalList(yintervals).indexes(),
-
-					BoxesWithNullAreaCanOverlap: boxesWithNoAreaCanOverlap,
-				},
-			},
+	starts := intervalList(xintervals).starts()
+	if len(xintervals) != len(yintervals) {
+		return invalidConstraint(starts,
+			"non-overlapping-2d: mismatched lengths of x intervals (%d: %s) and y intervals (%d: %s)",
+			len(xintervals), intervalList(xintervals).names(), len(yintervals), intervalList(yintervals).names())
+	}
+	if boxesWithNoAreaCanOverlap && internal.ORToolsVersionAtLeast(9, 3) {
+		var err error
+		xintervals, yintervals, err = withoutNullAreaBoxes(xintervals, yintervals)
+		if err != nil {
+			return invalidConstraint(starts, "non-overlapping-2d: %v", err)
+		}
+	}
+
+	ct := arenaFor(starts...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_NoOverlap_2D{
+		NoOverlap_2D: &pb.NoOverlap2DConstraintProto{
+			XIntervals: intervalList(xintervals).indexes(),
+			YIntervals: intervalList(yintervals).indexes(),
+
+			BoxesWithNullAreaCanOverlap: boxesWithNoAreaCanOverlap,
 		},
 	}
+	return &constraint{pb: ct}
 }
 
 // NewCumulativeConstraint ensures that the sum of the demands of the intervals
@@ -512,7 +770,9 @@ func NewNonOverlapping2DConstraint(
 // Intervals of size zero are ignored.
 func NewCumulativeConstraint(capacity IntVar, intervals []Interval, demands []IntVar) Constraint {
 	if len(intervals) != len(demands) {
-		panic("mismatched lengths of intervals and demands")
+		return invalidConstraint(append([]IntVar{capacity}, demands...),
+			"cumulative: mismatched lengths of intervals (%d: %s) and demands (%d: %s)",
+			len(intervals), intervalList(intervals).names(), len(demands), intVarList(demands).names())
 	}
 	var b strings.Builder
 	for i := range intervals {
@@ -521,55 +781,178 @@ func NewCumulativeConstraint(capacity IntVar, intervals []Interval, demands []In
 		}
 		b.WriteString(fmt.Sprintf("%s: %s", intervals[i].name(), demands[i].name()))
 	}
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_Cumulative{
-				Cumulative: &pb.CumulativeConstraintProto{
-					Capacity:  capacity.index(),
-					Intervals: intervalList(intervals).indexes(),
-					Demands:   intVarList(demands).indexes(),
-				},
-			},
+	ct := arenaFor(capacity).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Cumulative{
+		Cumulative: &pb.CumulativeConstraintProto{
+			Capacity:  capacity.index(),
+			Intervals: intervalList(intervals).indexes(),
+			Demands:   intVarList(demands).indexes(),
 		},
+	}
+	return &constraint{
+		pb:  ct,
 		str: fmt.Sprintf("cumulative: %s | %s", b.String(), capacity.name()),
 	}
 }
 
+// NewRoutesConstraint ensures that the arcs that are present form routes, as
+// in vehicle routing problems: multiple vehicles leaving and returning to a
+// depot (node 0). The i-th arc, going from tails[i] to heads[i], is present iff
+// literals[i] is true. More formally, the graph formed by the present arcs
+// must satisfy the following:
+//
+//   - every node other than the depot has exactly one incoming and outgoing
+//     arc;
+//   - the depot has as many incoming arcs as outgoing ones;
+//   - there are no cycles, except through the depot.
+//
+// Nodes can be left out of all routes using self-arcs (except for the depot).
+func NewRoutesConstraint(tails, heads []int, literals []Literal) Constraint {
+	if len(tails) != len(heads) || len(tails) != len(literals) {
+		return invalidConstraint(asIntVars(literals),
+			"routes: mismatched lengths of tails (%d), heads (%d) and literals (%d: %s)",
+			len(tails), len(heads), len(literals), asIntVars(literals).names())
+	}
+	var b strings.Builder
+	var ts, hs []int32
+	for i := range tails {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%d → %d: %s", tails[i], heads[i], literals[i].name()))
+		ts, hs = append(ts, int32(tails[i])), append(hs, int32(heads[i]))
+	}
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Routes{
+		Routes: &pb.RoutesConstraintProto{
+			Tails:    ts,
+			Heads:    hs,
+			Literals: asIntVars(literals).indexes(),
+		},
+	}
+	return &constraint{
+		pb:  ct,
+		str: fmt.Sprintf("routes: %s", b.String()),
+	}
+}
+
+// Transition is a labeled transition between two states of an automaton. See
+// NewAutomatonConstraint.
+type Transition struct {
+	Tail, Head int64 // the states transitioned from and to
+	Label      int64 // the value consumed
+}
+
+// NewAutomatonConstraint ensures that the sequence of values taken on by the
+// given variables is accepted by the automaton described by the start state,
+// the final states, and the transitions between states. Starting from the
+// start state, each variable's value (in order) must label a transition out
+// of the current state, moving to the next one. After the last variable, the
+// automaton must be in one of the final states. It's typically used to
+// restrict sequences of values to a regular language (shift patterns in
+// rostering problems, say). For a given tail state, transitions must have
+// distinct labels.
+func NewAutomatonConstraint(vars []IntVar, startState int64, finalStates []int64, transitions []Transition) Constraint {
+	var b strings.Builder
+	b.WriteString("automaton: ")
+	printVars(&b, vars...)
+	b.WriteString(fmt.Sprintf(" | start: %d, final: ", startState))
+	for i, s := range finalStates {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprint(s))
+	}
+	b.WriteString(" | ")
+
+	var tails, heads, labels []int64
+	for i, t := range transitions {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%d → %d: %d", t.Tail, t.Head, t.Label))
+		tails, heads, labels = append(tails, t.Tail), append(heads, t.Head), append(labels, t.Label)
+	}
+
+	ct := arenaFor(vars...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Automaton{
+		Automaton: &pb.AutomatonConstraintProto{
+			StartingState:   startState,
+			FinalStates:     append([]int64(nil), finalStates...),
+			TransitionTail:  tails,
+			TransitionHead:  heads,
+			TransitionLabel: labels,
+			Vars:            intVarList(vars).indexes(),
+		},
+	}
+	return &constraint{
+		pb:  ct,
+		str: b.String(),
+	}
+}
+
 // newAtMostOneConstraint is a special case of NewAtMostKConstraint that uses a
 // more efficient internal encoding.
 func newAtMostOneConstraint(literals ...Literal) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_AtMostOne{
-				AtMostOne: &pb.BoolArgumentProto{
-					Literals: asIntVars(literals).indexes(),
-				},
-			},
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_AtMostOne{
+		AtMostOne: &pb.BoolArgumentProto{
+			Literals: asIntVars(literals).indexes(),
 		},
 	}
+	return &constraint{pb: ct}
 }
 
 // newExactlyOneConstraint is a special case of NewExactlyKConstraint that uses
 // a more efficient internal encoding.
 func newExactlyOneConstraint(literals ...Literal) Constraint {
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_ExactlyOne{
-				ExactlyOne: &pb.BoolArgumentProto{
-					Literals: asIntVars(literals).indexes(),
-				},
-			},
+	ct := arenaFor(asIntVars(literals)...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_ExactlyOne{
+		ExactlyOne: &pb.BoolArgumentProto{
+			Literals: asIntVars(literals).indexes(),
 		},
 	}
+	return &constraint{pb: ct}
 }
 
 // OnlyEnforceIf is part of the Constraint interface.
 func (c *constraint) OnlyEnforceIf(literals ...Literal) Constraint {
+	if len(literals) > 0 {
+		c.linearizeForEnforcement()
+	}
 	c.pb.EnforcementLiteral = asIntVars(literals).indexes()
 	c.enforcement = append(c.enforcement, literals...)
 	return c
 }
 
+// linearizeForEnforcement rewrites at-most-one and exactly-one constraints
+// into their equivalent linear forms. The solver doesn't support enforcement
+// literals for the former, but does for linear constraints.
+func (c *constraint) linearizeForEnforcement() {
+	var literals []int32
+	var domain []int64
+	switch c.pb.Constraint.(type) {
+	case *pb.ConstraintProto_AtMostOne:
+		literals, domain = c.pb.GetAtMostOne().GetLiterals(), []int64{0, 1}
+	case *pb.ConstraintProto_ExactlyOne:
+		literals, domain = c.pb.GetExactlyOne().GetLiterals(), []int64{1, 1}
+	default:
+		return
+	}
+
+	coeffs := make([]int64, len(literals))
+	for i := range coeffs {
+		coeffs[i] = 1
+	}
+	c.pb.Constraint = &pb.ConstraintProto_Linear{
+		Linear: &pb.LinearConstraintProto{
+			Vars:   literals,
+			Coeffs: coeffs,
+			Domain: domain,
+		},
+	}
+}
+
 // protos is part of the Constraint interface.
 func (c *constraint) protos() []*pb.ConstraintProto {
 	return []*pb.ConstraintProto{c.pb}
@@ -593,6 +976,9 @@ func (c constraints) WithName(name string) Constraint {
 
 // String is part of the Constraint interface.
 func (c constraints) String() string {
+	if c.name != "" {
+		return fmt.Sprintf("%s: %s", quoteName(c.name), c.str)
+	}
 	return c.str
 }
 
@@ -613,7 +999,7 @@ func (c constraints) protos() []*pb.ConstraintProto {
 	return res
 }
 
-func newLiteralAssignmentsConstraintInternal(literals []Literal, assignments [][]bool) *constraint {
+func newLiteralAssignmentsConstraintInternal(literals []Literal, assignments [][]bool, negated bool) Constraint {
 	var integerAssignments [][]int64
 	for _, assignment := range assignments { // convert [][]bool to [][]int64
 		var integerAssignment []int64
@@ -627,27 +1013,53 @@ func newLiteralAssignmentsConstraintInternal(literals []Literal, assignments [][
 		integerAssignments = append(integerAssignments, integerAssignment)
 	}
 
-	return newAssignmentsConstraintInternal(asIntVars(literals), integerAssignments)
+	return newAssignmentsConstraintInternal(asIntVars(literals), integerAssignments, negated)
 }
 
-func newAssignmentsConstraintInternal(vars []IntVar, assignments [][]int64) *constraint {
+func newAssignmentsConstraintInternal(vars []IntVar, assignments [][]int64, negated bool) Constraint {
 	var values []int64
-	for _, assignment := range assignments {
+	for i, assignment := range assignments {
 		if len(assignment) != len(vars) {
-			panic("mismatched assignment and vars length")
+			kind := "allowed-assignments"
+			if negated {
+				kind = "forbidden-assignments"
+			}
+			return invalidConstraint(vars, "%s: mismatched length of assignment #%d (%d) and vars (%d: %s)",
+				kind, i, len(assignment), len(vars), intVarList(vars).names())
 		}
 		values = append(values, assignment...)
 	}
-	return &constraint{
-		pb: &pb.ConstraintProto{
-			Constraint: &pb.ConstraintProto_Table{
-				Table: &pb.TableConstraintProto{
-					Vars:   intVarList(vars).indexes(),
-					Values: values,
-				},
-			},
+	if hasWildcards(assignments) {
+		return newWildcardAssignmentsConstraint(vars, assignments, negated)
+	}
+	ct := arenaFor(vars...).constraintProto()
+	ct.Constraint = &pb.ConstraintProto_Table{
+		Table: &pb.TableConstraintProto{
+			Vars:    intVarList(vars).indexes(),
+			Values:  values,
+			Negated: negated,
 		},
 	}
+	return &constraint{pb: ct}
+}
+
+// withoutNullAreaBoxes filters out the boxes (defined by the given x and y
+// intervals) that are fixed to have an area of zero. It errors out if any of
+// the remaining boxes could have an area of zero.
+func withoutNullAreaBoxes(xintervals, yintervals []Interval) (xs, ys []Interval, err error) {
+	for i := range xintervals {
+		_, _, xsize := xintervals[i].Parameters()
+		_, _, ysize := yintervals[i].Parameters()
+		if xsize.domain().fixed(0) || ysize.domain().fixed(0) {
+			continue
+		}
+		if xsize.domain().contains(0) || ysize.domain().contains(0) {
+			return nil, nil, fmt.Errorf("cannot allow boxes with no area to overlap: box (%s, %s) may have no area",
+				xintervals[i].name(), yintervals[i].name())
+		}
+		xs, ys = append(xs, xintervals[i]), append(ys, yintervals[i])
+	}
+	return xs, ys, nil
 }
 
 // printVars is a helper to print out intvars of the form: i1, i2, ..., iN.
@@ -0,0 +1,322 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/irfansharif/solver/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithoutNullAreaBoxes(t *testing.T) {
+	model := NewModel("")
+	start := model.NewIntVar(0, 10, "start")
+	end := model.NewIntVar(0, 10, "end")
+	zero := model.NewConstant(0, "zero")
+	two := model.NewConstant(2, "two")
+	maybeZero := model.NewIntVar(0, 2, "maybe-zero")
+
+	point := model.NewInterval(start, start, zero, "point")
+	line := model.NewInterval(start, end, two, "line")
+	maybe := model.NewInterval(start, end, maybeZero, "maybe")
+
+	xs, ys, err := withoutNullAreaBoxes([]Interval{point, line}, []Interval{line, line})
+	require.NoError(t, err)
+	require.Equal(t, []Interval{line}, xs)
+	require.Equal(t, []Interval{line}, ys)
+
+	_, _, err = withoutNullAreaBoxes([]Interval{maybe}, []Interval{line})
+	require.EqualError(t, err, "cannot allow boxes with no area to overlap: box (maybe, line) may have no area")
+}
+
+func TestNonOverlapping2DWithNullAreaBoxes(t *testing.T) {
+	// A box that may have no area (but isn't fixed to it) placed within
+	// another. It's only allowed to overlap if the underlying solver honors
+	// boxesWithNoAreaCanOverlap, which OR-Tools v9.3+ doesn't; there we reject
+	// the constraint instead.
+	model := NewModel("")
+	zero, one, four := model.NewConstant(0, "zero"), model.NewConstant(1, "one"), model.NewConstant(4, "four")
+	size := model.NewIntVar(0, 2, "size")
+	end := model.NewIntVar(0, 4, "end")
+	outer := model.NewInterval(zero, four, four, "outer")
+	inner := model.NewInterval(one, end, size, "inner")
+	if internal.ORToolsVersionAtLeast(9, 3) {
+		require.Panics(t, func() {
+			NewNonOverlapping2DConstraint([]Interval{outer, inner}, []Interval{outer, outer}, true)
+		})
+		return
+	}
+	model.AddConstraints(
+		NewNonOverlapping2DConstraint([]Interval{outer, inner}, []Interval{outer, outer}, true),
+	)
+	model.Maximize(Sum(size))
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(0), result.Value(size))
+}
+
+func TestEnforcedKConstraints(t *testing.T) {
+	model := NewModel("")
+	a, b, c := model.NewLiteral("a"), model.NewLiteral("b"), model.NewLiteral("c")
+
+	for _, tc := range []struct {
+		c      Constraint
+		domain []int64
+	}{
+		{NewAtMostKConstraint(1, a, b).OnlyEnforceIf(c), []int64{0, 1}},
+		{NewExactlyKConstraint(1, a, b).OnlyEnforceIf(c), []int64{1, 1}},
+	} {
+		protos := tc.c.protos()
+		require.Len(t, protos, 1)
+		linear := protos[0].GetLinear()
+		require.NotNil(t, linear, "expected enforced constraint to be linearized")
+		require.Equal(t, []int32{a.index(), b.index()}, linear.Vars)
+		require.Equal(t, []int64{1, 1}, linear.Coeffs)
+		require.Equal(t, tc.domain, linear.Domain)
+		require.Equal(t, []int32{c.index()}, protos[0].EnforcementLiteral)
+	}
+
+	// Without enforcement literals, we keep the more efficient encoding.
+	require.NotNil(t, NewAtMostKConstraint(1, a, b).OnlyEnforceIf().(*constraint).pb.GetAtMostOne())
+}
+
+func TestMonotonicConstraints(t *testing.T) {
+	model := NewModel("")
+	a, b, c := model.NewIntVar(0, 3, "a"), model.NewIntVar(0, 3, "b"), model.NewIntVar(0, 3, "c")
+
+	increasing := NewIncreasingConstraint(false, a, b, c)
+	require.Equal(t, "increasing: a, b, c", increasing.String())
+	require.Len(t, increasing.protos(), 2)
+	require.Equal(t, []int64{0, 3}, increasing.protos()[0].GetLinear().GetDomain())
+
+	decreasing := NewDecreasingConstraint(true, a, b, c)
+	require.Equal(t, "strictly-decreasing: a, b, c", decreasing.String())
+	require.Equal(t, []int64{1, 3}, decreasing.protos()[1].GetLinear().GetDomain())
+
+	model.AddConstraints(NewIncreasingConstraint(true, a, b, c))
+	model.Maximize(Sum(a))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, []int64{1, 2, 3}, []int64{result.Value(a), result.Value(b), result.Value(c)})
+
+	// Strictly monotonic sequences longer than the domains allow are
+	// infeasible.
+	model = NewModel("")
+	var vars []IntVar
+	for i := 0; i < 3; i++ {
+		vars = append(vars, model.NewIntVar(0, 1, ""))
+	}
+	model.AddConstraints(NewDecreasingConstraint(true, vars...))
+	require.True(t, model.Solve().Infeasible())
+}
+
+func TestDistanceConstraints(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 5, "x"), model.NewIntVar(2, 4, "y")
+
+	for _, tc := range []struct {
+		c      Constraint
+		str    string
+		domain []int64
+	}{
+		{NewMinDistanceConstraint(x, y, 2), "min-distance: |x - y| >= 2", []int64{-4, -2, 2, 3}},
+		{NewMinDistanceConstraint(x, y, 4), "min-distance: |x - y| >= 4", []int64{-4, -4}},
+		{NewMinDistanceConstraint(x, y, 0), "min-distance: |x - y| >= 0", []int64{-4, 3}},
+		{NewMinDistanceConstraint(x, y, 6), "min-distance: |x - y| >= 6", []int64{6, 6}},
+		{NewMaxDistanceConstraint(x, y, 1), "max-distance: |x - y| <= 1", []int64{-1, 1}},
+	} {
+		require.Equal(t, tc.str, tc.c.String())
+		require.Len(t, tc.c.protos(), 1)
+		require.Equal(t, tc.domain, tc.c.protos()[0].GetLinear().GetDomain())
+	}
+
+	require.PanicsWithValue(t, "min-distance: negative distance -1", func() {
+		NewMinDistanceConstraint(x, y, -1)
+	})
+}
+
+func TestComparisonConstraints(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 5, "x"), model.NewIntVar(2, 4, "y")
+
+	for _, tc := range []struct {
+		c      Constraint
+		str    string
+		domain []int64
+	}{
+		{NewEqualityConstraint(Sum(x), Sum(y)), "x == y", []int64{0, 0}},
+		{NewInequalityConstraint(Sum(x), Sum(y)), "x != y", []int64{-4, -1, 1, 3}},
+		{NewLessThanConstraint(Sum(x), Sum(y)), "x < y", []int64{-4, -1}},
+		{NewLessOrEqualConstraint(Sum(x), Sum(y)), "x <= y", []int64{-4, 0}},
+		{NewGreaterThanConstraint(Sum(x), Sum(y)), "x > y", []int64{1, 3}},
+		{NewGreaterOrEqualConstraint(Sum(x), Sum(y)), "x >= y", []int64{0, 3}},
+		// Offsets are folded into the domain, and variables on both sides are
+		// combined.
+		{
+			NewLessOrEqualConstraint(NewLinearExpr([]IntVar{x, y}, []int64{2, 1}, -6), Sum(x)),
+			"2x + y - 6 <= x", []int64{2, 6},
+		},
+	} {
+		require.Equal(t, tc.str, tc.c.String())
+		require.Len(t, tc.c.protos(), 1)
+		require.Equal(t, tc.domain, tc.c.protos()[0].GetLinear().GetDomain())
+	}
+
+	// Comparisons that can't hold are infeasible.
+	require.Equal(t, []int64{5, 5}, NewGreaterThanConstraint(Sum(y), NewLinearExpr(nil, nil, 4)).protos()[0].GetLinear().GetDomain())
+
+	model.AddConstraints(
+		NewGreaterThanConstraint(Sum(x), Sum(y)),
+		NewInequalityConstraint(Sum(x), NewLinearExpr(nil, nil, 5)),
+	)
+	model.Minimize(Sum(x))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(3), result.Value(x))
+}
+
+func TestSpanConstraint(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	cover := model.NewInterval(model.NewIntVar(0, 10, "s"), model.NewIntVar(0, 10, "e"), model.NewIntVar(0, 10, "d"), "cover")
+	NewSpanConstraint(cover)
+	require.EqualError(t, model.Err(), "span (cover): no intervals to span")
+
+	model = NewModel("")
+	var intervals []Interval
+	for i, start := range []int64{4, 1, 6} {
+		s := model.NewConstant(start, fmt.Sprintf("s%d", i))
+		e := model.NewConstant(start+2, fmt.Sprintf("e%d", i))
+		intervals = append(intervals, model.NewInterval(s, e, model.NewConstant(2, ""), fmt.Sprintf("i%d", i)))
+	}
+	start, end := model.NewIntVar(0, 10, "start"), model.NewIntVar(0, 10, "end")
+	cover = model.NewInterval(start, end, model.NewIntVar(0, 10, "size"), "cover")
+
+	c := NewSpanConstraint(cover, intervals...)
+	require.Equal(t, "span: cover | i0, i1, i2", c.String())
+	model.AddConstraints(c)
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(1), result.Value(start))
+	require.Equal(t, int64(8), result.Value(end))
+}
+
+func TestProductDecomposition(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	a, b, c := model.NewIntVar(1, 3, "a"), model.NewIntVar(-2, 2, "b"), model.NewIntVar(2, 5, "c")
+	target := model.NewIntVar(-100, 100, "target")
+
+	product := NewProductConstraint(target, a, b, c)
+	require.Equal(t, "target == a * b * c", product.String())
+	protos := product.protos()
+	require.Len(t, protos, 2)
+	partial := model.vars[len(model.vars)-1]
+	require.Equal(t, "target.partial1 in [-6, 6]", partial.String())
+	require.Equal(t, partial.index(), protos[0].GetIntProd().GetTarget())
+	require.Equal(t, []int32{a.index(), b.index()}, protos[0].GetIntProd().GetVars())
+	require.Equal(t, target.index(), protos[1].GetIntProd().GetTarget())
+	require.Equal(t, []int32{partial.index(), c.index()}, protos[1].GetIntProd().GetVars())
+
+	// Binary products are left as is.
+	require.Len(t, NewProductConstraint(target, a, b).protos(), 1)
+
+	huge := model.NewIntVar(0, math.MaxInt64/4, "huge")
+	NewProductConstraint(target, a, huge, huge)
+	require.EqualError(t, model.Err(), "product (target == a * huge * huge): possible integer overflow (partial product #2)")
+
+	model = NewModel("")
+	a, b, c = model.NewIntVar(1, 3, "a"), model.NewIntVar(-2, 2, "b"), model.NewIntVar(2, 5, "c")
+	target = model.NewIntVar(-100, 100, "target")
+	model.AddConstraints(NewProductConstraint(target, a, b, c))
+	model.Minimize(Sum(target))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(-30), result.ObjectiveValue())
+}
+
+func TestPowerConstraint(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x, y := model.NewIntVar(-3, 3, "x"), model.NewIntVar(-100, 100, "y")
+
+	NewPowerConstraint(y, x, -1)
+	require.EqualError(t, model.Err(), "power (y == x^-1): negative exponent")
+	require.Equal(t, []int64{1, 1}, NewPowerConstraint(y, x, 0).protos()[0].GetLinear().GetDomain())
+	require.Len(t, NewPowerConstraint(y, x, 1).protos(), 1)
+
+	cube := NewPowerConstraint(y, x, 3)
+	require.Equal(t, "y == x^3", cube.String())
+	require.Len(t, cube.protos(), 2)
+
+	model = NewModel("")
+	x, y = model.NewIntVar(-3, 3, "x"), model.NewIntVar(-100, 100, "y")
+	model.AddConstraints(NewPowerConstraint(y, x, 3))
+	model.Minimize(Sum(y))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(-27), result.Value(y))
+}
+
+func TestWeightedSumConstraint(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x, y := model.NewIntVar(0, 5, "x"), model.NewIntVar(0, 5, "y")
+	total := model.NewIntVar(0, 100, "total")
+
+	NewWeightedSumConstraint(total, []IntVar{x, y}, []int64{2})
+	require.EqualError(t, model.Err(), "weighted-sum: mismatched number of variables (2: x, y) and coefficients (1)")
+
+	c := NewWeightedSumConstraint(total, []IntVar{x, y}, []int64{2, -3})
+	require.Equal(t, "weighted-sum: total == 2x - 3y", c.String())
+	linear := c.protos()[0].GetLinear()
+	require.Equal(t, []int32{total.index(), x.index(), y.index()}, linear.GetVars())
+	require.Equal(t, []int64{1, -2, 3}, linear.GetCoeffs())
+	require.Equal(t, []int64{0, 0}, linear.GetDomain())
+}
+
+func TestNaming(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "").WithName("x")
+	l := model.NewLiteral("")
+	l.Not().WithName("l")
+	itv := model.NewFixedSizeInterval(x, 2, "")
+	itv.WithName("itv")
+
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(l).WithName("cap"),
+		NewBooleanOrConstraint(l).WithName("at least one"),
+		NewNonOverlappingConstraint(itv),
+	)
+	require.Equal(t, "x in [0, 10]", x.String())
+	require.Equal(t, "~l", l.Not().(IntVar).String())
+	require.Equal(t, "itv", itv.name())
+
+	str := model.String()
+	require.Contains(t, str, "cap: linear-constraint: x in [0, 5] if (l)")
+	require.Contains(t, str, `"at least one": boolean-or: l`)
+	require.Contains(t, str, "non-overlapping:")
+	require.Equal(t,
+		"constraint #1 (cap: linear-constraint: x in [0, 5] if (l)); var #0 (x)",
+		model.describe("constraint #1; var #0"),
+	)
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewLinearCumulativeConstraint is like NewCumulativeConstraint, except the
+// capacity and demands are linear expressions. This lets demands be derived
+// quantities (a per-unit demand scaled by a batch size, say) without callers
+// needing to introduce auxiliary variables for them.
+//
+// The bundled version of OR-Tools only supports variables as capacities and
+// demands, so expressions that aren't plain variables are captured using
+// auxiliary variables, instantiated in the model right away. The returned
+// constraint still needs to be added to the model.
+//
+// TODO(irfansharif): Emit the expression-based representation directly once
+// we've upgraded past OR-Tools v9.1. See pb/README.md.
+func (m *Model) NewLinearCumulativeConstraint(capacity LinearExpr, intervals []Interval, demands []LinearExpr) Constraint {
+	if len(intervals) != len(demands) {
+		return invalidConstraint(linearExprList(demands).intVars(),
+			"cumulative: mismatched lengths of intervals (%d: %s) and demands (%d: %s)",
+			len(intervals), intervalList(intervals).names(), len(demands), linearExprList(demands).String())
+	}
+
+	var b strings.Builder
+	for i := range intervals {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%s: %s", intervals[i].name(), demands[i].String()))
+	}
+
+	var cs []Constraint
+	capacityVar, c := m.variableFor(capacity, "")
+	cs = append(cs, c...)
+	demandVars := make([]IntVar, len(demands))
+	for i, demand := range demands {
+		demandVars[i], c = m.variableFor(demand, derivedName(intervals[i].(*interval).pb.GetName(), "demand"))
+		cs = append(cs, c...)
+	}
+	cs = append(cs, NewCumulativeConstraint(capacityVar, intervals, demandVars))
+	return constraints{
+		cs:  cs,
+		str: fmt.Sprintf("cumulative: %s | %s", b.String(), capacity.String()),
+	}
+}
+
+// variableFor returns a variable equal to the given linear expression: the
+// variable itself if the expression is a plain variable, or an auxiliary one
+// (instantiated using the given name) constrained to equal it otherwise.
+func (m *Model) variableFor(e LinearExpr, name string) (IntVar, []Constraint) {
+	vars, coeffs, offset := e.Parameters()
+	if len(vars) == 1 && coeffs[0] == 1 && offset == 0 {
+		return vars[0], nil
+	}
+	if len(vars) == 0 {
+		return m.NewConstant(offset, name), nil
+	}
+
+	lb, ub := linearBounds(e)
+	v := m.NewIntVar(lb, ub, name)
+	return v, []Constraint{NewEqualityConstraint(Sum(v), e)}
+}
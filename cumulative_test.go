@@ -0,0 +1,57 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinearCumulativeConstraint(t *testing.T) {
+	model := NewModel("")
+	batch := model.NewIntVar(1, 3, "batch")
+	var intervals []Interval
+	for _, name := range []string{"a", "b"} {
+		start := model.NewIntVar(0, 10, name+".start")
+		end := model.NewIntVar(0, 10, name+".end")
+		intervals = append(intervals, model.NewInterval(start, end, model.NewConstant(2, ""), name))
+	}
+
+	numVars := len(model.pb.GetVariables())
+	c := model.NewLinearCumulativeConstraint(
+		NewLinearExpr(nil, nil, 4),
+		intervals,
+		[]LinearExpr{NewLinearExpr([]IntVar{batch}, []int64{2}, 0), Sum(batch)},
+	)
+	require.Equal(t, "cumulative: a: 2batch, b: batch | 4", c.String())
+	// The capacity is captured using a constant, and the first demand using an
+	// auxiliary variable; the second demand is used as is.
+	require.Len(t, model.pb.GetVariables(), numVars+2)
+	require.Equal(t, "a.demand", model.pb.GetVariables()[numVars+1].GetName())
+	require.Equal(t, []int64{2, 6}, model.pb.GetVariables()[numVars+1].GetDomain())
+	require.Len(t, c.protos(), 2)
+
+	// Both intervals have to run concurrently, which caps the batch size.
+	model.AddConstraints(
+		c,
+		NewEqualityConstraint(Sum(intervals[0].(*interval).start), Sum(intervals[1].(*interval).start)),
+	)
+	model.Maximize(Sum(batch))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(1), result.Value(batch))
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// DegeneratePolicy determines how a model handles degenerate constraints,
+// those constructed using empty inputs. Their semantics follow from the
+// underlying solver's, and can be surprising:
+//
+//   - NewBooleanOrConstraint and NewBooleanXorConstraint with no literals are
+//     always false, as is NewExactlyKConstraint with k == 1;
+//   - NewBooleanAndConstraint with no literals is always true, as are
+//     NewAtMostKConstraint with k == 1, NewAllDifferentConstraint with fewer
+//     than two variables, and scheduling and routing constraints with no
+//     intervals or arcs;
+//   - NewProductConstraint with no multiplicands forces the target to be
+//     equal to one.
+type DegeneratePolicy int
+
+const (
+	// AllowDegenerate adds degenerate constraints to the model as is. It's
+	// the default.
+	AllowDegenerate DegeneratePolicy = iota
+	// NormalizeDegenerate rewrites degenerate constraints into their explicit
+	// equivalents: AlwaysTrue, AlwaysFalse, or a linear constraint fixing the
+	// target of an empty product. Enforcement literals are retained. The
+	// constraints themselves are left as is; it's normalized copies that are
+	// added to the model.
+	NormalizeDegenerate
+	// RejectDegenerate treats degenerate constraints as construction errors;
+	// we panic, or record an error if configured using
+	// WithConstructionErrors. Rejected constraints are left out of the model,
+	// all of their parts included.
+	RejectDegenerate
+)
+
+// WithDegeneratePolicy configures how the model handles degenerate
+// constraints. See DegeneratePolicy.
+func WithDegeneratePolicy(p DegeneratePolicy) ModelOption {
+	return func(m *Model) {
+		m.degeneratePolicy = p
+	}
+}
+
+// AlwaysTrue returns a constraint that's always satisfied. Enforced using
+// literals (see Constraint.OnlyEnforceIf), it has no effect.
+func AlwaysTrue() Constraint {
+	return &constraint{
+		pb: &pb.ConstraintProto{
+			Constraint: &pb.ConstraintProto_BoolAnd{BoolAnd: &pb.BoolArgumentProto{}},
+		},
+		str:      "always-true",
+		explicit: true,
+	}
+}
+
+// AlwaysFalse returns a constraint that's never satisfied. Enforced using
+// literals (see Constraint.OnlyEnforceIf), it ensures that at least one of
+// them is false.
+func AlwaysFalse() Constraint {
+	return &constraint{
+		pb: &pb.ConstraintProto{
+			Constraint: &pb.ConstraintProto_BoolOr{BoolOr: &pb.BoolArgumentProto{}},
+		},
+		str:      "always-false",
+		explicit: true,
+	}
+}
+
+// admit applies the model's degenerate policy to the given constraint,
+// returning the protos to add to the model. Degenerate protos are normalized
+// into copies, leaving the constraint itself as is. If any of them is
+// rejected, none of the constraint's protos are added.
+func (m *Model) admit(c Constraint) []*pb.ConstraintProto {
+	protos := c.protos()
+	if m.degeneratePolicy == AllowDegenerate {
+		return protos
+	}
+
+	explicit := explicitProtos(c)
+	admitted := make([]*pb.ConstraintProto, 0, len(protos))
+	normalized := make(map[*pb.ConstraintProto]*pb.ConstraintProto)
+	for _, ct := range protos {
+		holds, ok := degenerate(ct)
+		if !ok || explicit[ct] {
+			admitted = append(admitted, ct)
+			continue
+		}
+
+		switch m.degeneratePolicy {
+		case NormalizeDegenerate:
+			n := proto.Clone(ct).(*pb.ConstraintProto)
+			switch {
+			case ct.GetIntProd() != nil:
+				n.Constraint = &pb.ConstraintProto_Linear{
+					Linear: &pb.LinearConstraintProto{
+						Vars:   []int32{ct.GetIntProd().GetTarget()},
+						Coeffs: []int64{1},
+						Domain: []int64{1, 1},
+					},
+				}
+			case holds:
+				n.Constraint = AlwaysTrue().protos()[0].Constraint
+			default:
+				n.Constraint = AlwaysFalse().protos()[0].Constraint
+			}
+			normalized[ct] = n
+			admitted = append(admitted, n)
+		case RejectDegenerate:
+			m.fail("degenerate constraint (%s)", c)
+			return nil
+		default:
+			panic(fmt.Sprintf("unrecognized degenerate policy: %d", m.degeneratePolicy))
+		}
+	}
+
+	if len(normalized) != 0 && m.normalized == nil {
+		m.normalized = make(map[*pb.ConstraintProto]*pb.ConstraintProto)
+	}
+	for ct, n := range normalized {
+		m.normalized[ct] = n
+	}
+	return admitted
+}
+
+// explicitProtos returns the protos of the given constraint that were
+// constructed using AlwaysTrue or AlwaysFalse, if any.
+func explicitProtos(c Constraint) map[*pb.ConstraintProto]bool {
+	var res map[*pb.ConstraintProto]bool
+	var walk func(c Constraint)
+	walk = func(c Constraint) {
+		switch c := c.(type) {
+		case *constraint:
+			if c.explicit {
+				if res == nil {
+					res = make(map[*pb.ConstraintProto]bool)
+				}
+				res[c.pb] = true
+			}
+		case constraints:
+			for _, cons := range c.cs {
+				walk(cons)
+			}
+		case *constraints:
+			for _, cons := range c.cs {
+				walk(cons)
+			}
+		}
+	}
+	walk(c)
+	return res
+}
+
+// protosOf returns the protos the given constraint was added to the model as,
+// accounting for degenerate ones normalized into copies (see admit).
+func (m *Model) protosOf(c Constraint) []*pb.ConstraintProto {
+	protos := c.protos()
+	if len(m.normalized) == 0 {
+		return protos
+	}
+	res := make([]*pb.ConstraintProto, len(protos))
+	for i, ct := range protos {
+		if n, ok := m.normalized[ct]; ok {
+			ct = n
+		}
+		res[i] = ct
+	}
+	return res
+}
+
+// degenerate returns whether the given constraint proto is degenerate, i.e.
+// constructed using empty inputs, and if so whether it always holds. Empty
+// products are degenerate but neither always hold nor never do; we report
+// them as not holding.
+func degenerate(ct *pb.ConstraintProto) (holds, ok bool) {
+	switch ct.Constraint.(type) {
+	case *pb.ConstraintProto_BoolOr:
+		return false, len(ct.GetBoolOr().GetLiterals()) == 0
+	case *pb.ConstraintProto_BoolXor:
+		return false, len(ct.GetBoolXor().GetLiterals()) == 0
+	case *pb.ConstraintProto_ExactlyOne:
+		return false, len(ct.GetExactlyOne().GetLiterals()) == 0
+	case *pb.ConstraintProto_BoolAnd:
+		return true, len(ct.GetBoolAnd().GetLiterals()) == 0
+	case *pb.ConstraintProto_AtMostOne:
+		return true, len(ct.GetAtMostOne().GetLiterals()) == 0
+	case *pb.ConstraintProto_AllDiff:
+		return true, len(ct.GetAllDiff().GetVars()) < 2
+	case *pb.ConstraintProto_NoOverlap:
+		return true, len(ct.GetNoOverlap().GetIntervals()) == 0
+	case *pb.ConstraintProto_NoOverlap_2D:
+		return true, len(ct.GetNoOverlap_2D().GetXIntervals()) == 0
+	case *pb.ConstraintProto_Cumulative:
+		return true, len(ct.GetCumulative().GetIntervals()) == 0
+	case *pb.ConstraintProto_Routes:
+		return true, len(ct.GetRoutes().GetLiterals()) == 0
+	case *pb.ConstraintProto_Linear:
+		if len(ct.GetLinear().GetVars()) != 0 {
+			return false, false
+		}
+		d := &domain{intervals: ct.GetLinear().GetDomain()}
+		return d.contains(0), true
+	case *pb.ConstraintProto_IntProd:
+		return false, len(ct.GetIntProd().GetVars()) == 0
+	default:
+		return false, false
+	}
+}
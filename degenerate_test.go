@@ -0,0 +1,129 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegenerateConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		c     Constraint
+		holds bool
+		ok    bool
+	}{
+		{NewBooleanOrConstraint(), false, true},
+		{NewBooleanXorConstraint(), false, true},
+		{NewExactlyKConstraint(1), false, true},
+		{NewBooleanAndConstraint(), true, true},
+		{NewAtMostKConstraint(1), true, true},
+		{NewAtMostKConstraint(2), true, true},
+		{NewAllDifferentConstraint(), true, true},
+		{NewNonOverlappingConstraint(), true, true},
+		{NewRoutesConstraint(nil, nil, nil), true, true},
+	} {
+		holds, ok := degenerate(tc.c.protos()[0])
+		require.Equal(t, tc.ok, ok, tc.c.String())
+		require.Equal(t, tc.holds, holds, tc.c.String())
+	}
+
+	model := NewModel("")
+	x := model.NewLiteral("x")
+	_, ok := degenerate(NewBooleanOrConstraint(x).protos()[0])
+	require.False(t, ok)
+}
+
+func TestNormalizeDegenerate(t *testing.T) {
+	model := NewModel("", WithDegeneratePolicy(NormalizeDegenerate))
+	x := model.NewLiteral("x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(
+		NewBooleanXorConstraint().OnlyEnforceIf(x),
+		NewAtMostKConstraint(1),
+		NewProductConstraint(y),
+	)
+
+	cts := model.pb.GetConstraints()
+	require.Len(t, cts, 3)
+	require.NotNil(t, cts[0].GetBoolOr())
+	require.Equal(t, []int32{x.index()}, cts[0].GetEnforcementLiteral())
+	require.NotNil(t, cts[1].GetBoolAnd())
+	require.Equal(t, []int32{y.index()}, cts[2].GetLinear().GetVars())
+	require.Equal(t, []int64{1, 1}, cts[2].GetLinear().GetDomain())
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.False(t, result.BooleanValue(x))
+	require.Equal(t, int64(1), result.Value(y))
+}
+
+func TestNormalizeDegenerateCopies(t *testing.T) {
+	model := NewModel("", WithDegeneratePolicy(NormalizeDegenerate))
+	c := NewBooleanXorConstraint()
+	h := model.AddConstraintHandles(c)[0]
+
+	// The constraint itself is left as is, with a normalized copy added in its
+	// place.
+	require.NotNil(t, c.protos()[0].GetBoolXor())
+	require.NotNil(t, model.pb.GetConstraints()[0].GetBoolOr())
+
+	// It can still be disabled and removed through its handle.
+	h.Disable()
+	require.True(t, model.disabled(c))
+	require.NotNil(t, model.pb.GetConstraints()[0].GetBoolAnd())
+	h.Enable()
+	require.NotNil(t, model.pb.GetConstraints()[0].GetBoolOr())
+	h.Remove()
+	require.True(t, h.removed)
+	require.Empty(t, model.constraints)
+}
+
+func TestRejectDegenerate(t *testing.T) {
+	model := NewModel("m", WithDegeneratePolicy(RejectDegenerate))
+	require.PanicsWithValue(t, "model m: degenerate constraint (boolean-or: )",
+		func() { model.AddConstraints(NewBooleanOrConstraint()) })
+
+	model = NewModel("m", WithDegeneratePolicy(RejectDegenerate), WithConstructionErrors())
+	x := model.NewLiteral("x")
+	model.AddConstraints(NewBooleanOrConstraint(x), NewBooleanAndConstraint())
+	require.Len(t, model.pb.GetConstraints(), 1)
+	require.EqualError(t, model.Err(), "model m: degenerate constraint (boolean-and: )")
+
+	// Constraints made up of multiple parts are rejected as a whole.
+	model.AddConstraints(constraints{
+		cs:  []Constraint{NewBooleanOrConstraint(x), NewBooleanAndConstraint()},
+		str: "composite",
+	})
+	require.Len(t, model.pb.GetConstraints(), 1)
+	require.Contains(t, model.Err().Error(), "model m: degenerate constraint (composite)")
+}
+
+func TestExplicitConstraintsAreNotDegenerate(t *testing.T) {
+	// AlwaysTrue and AlwaysFalse are the explicit forms degenerate constraints
+	// are normalized into; they're admitted as is under every policy.
+	for _, p := range []DegeneratePolicy{NormalizeDegenerate, RejectDegenerate} {
+		model := NewModel("", WithDegeneratePolicy(p), WithConstructionErrors())
+		x := model.NewLiteral("x")
+		always, never := AlwaysTrue(), AlwaysFalse().OnlyEnforceIf(x)
+		model.AddConstraints(always, never, constraints{cs: []Constraint{AlwaysTrue()}, str: "composite"})
+		require.NoError(t, model.Err())
+		require.Len(t, model.pb.GetConstraints(), 3)
+		require.Same(t, always.protos()[0], model.pb.GetConstraints()[0])
+		require.Same(t, never.protos()[0], model.pb.GetConstraints()[1])
+		require.Empty(t, model.normalized)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+// requireEquivalent solves the given models, built through different code
+// paths but capturing the same logical problem, and asserts that they agree:
+// they're both feasible (or not), with the same objective value. If the
+// models declare the same variables, each solution is also verified against
+// the other model. It guards against the wrappers drifting from the
+// semantics of the underlying protos.
+func requireEquivalent(t *testing.T, a, b *Model, os ...Option) {
+	t.Helper()
+
+	ra, rb := a.Solve(os...), b.Solve(os...)
+	require.Equal(t, ra.Status(), rb.Status(), "models disagree on feasibility")
+	if !ra.solved() {
+		return
+	}
+	require.Equal(t, ra.ObjectiveValue(), rb.ObjectiveValue(), "models disagree on objective value")
+
+	if len(a.pb.GetVariables()) != len(b.pb.GetVariables()) {
+		return
+	}
+	require.NoError(t, b.verify(ra.pb.GetSolution()), "solution to the first model violates the second")
+	require.NoError(t, a.verify(rb.pb.GetSolution()), "solution to the second model violates the first")
+}
+
+// fromProto returns a model wrapping the given proto, bypassing the wrappers
+// used to build one.
+func fromProto(p *pb.CpModelProto) *Model {
+	return &Model{pb: p}
+}
+
+func TestDifferentialProtos(t *testing.T) {
+	// A small knapsack problem: pick items maximizing value within a capacity.
+	weights, values := []int64{3, 4, 5, 6}, []int64{4, 5, 7, 8}
+	for _, capacity := range []int64{10, 2} {
+		model := NewModel("")
+		var items []Literal
+		for range weights {
+			items = append(items, model.NewLiteral(""))
+		}
+		model.AddConstraints(NewLinearConstraint(Dot(items, weights), NewDomain(0, capacity)))
+		model.AddConstraints(NewAtLeastKConstraint(1, items...))
+		model.Maximize(Dot(items, values))
+
+		raw := &pb.CpModelProto{}
+		for range weights {
+			raw.Variables = append(raw.Variables, &pb.IntegerVariableProto{Domain: []int64{0, 1}})
+		}
+		raw.Constraints = []*pb.ConstraintProto{
+			{Constraint: &pb.ConstraintProto_Linear{Linear: &pb.LinearConstraintProto{
+				Vars: []int32{0, 1, 2, 3}, Coeffs: weights, Domain: []int64{0, capacity},
+			}}},
+			{Constraint: &pb.ConstraintProto_BoolOr{BoolOr: &pb.BoolArgumentProto{
+				Literals: []int32{0, 1, 2, 3},
+			}}},
+		}
+		raw.Objective = &pb.CpObjectiveProto{
+			Vars: []int32{0, 1, 2, 3}, Coeffs: []int64{-4, -5, -7, -8}, ScalingFactor: -1,
+		}
+
+		requireEquivalent(t, model, fromProto(raw))
+	}
+}
+
+func TestDifferentialEnforcement(t *testing.T) {
+	// Enforced at-most-one constraints are linearized by the wrappers; check
+	// that they're equivalent to the enforced linear constraint, as expressed
+	// in raw protos.
+	model := NewModel("")
+	a, b, c, e := model.NewLiteral("a"), model.NewLiteral("b"), model.NewLiteral("c"), model.NewLiteral("e")
+	model.AddConstraints(NewAtMostKConstraint(1, a, b, c).OnlyEnforceIf(e))
+	model.AddConstraints(NewBooleanAndConstraint(a, b))
+	model.Maximize(Sum(e))
+
+	raw := &pb.CpModelProto{}
+	for i := 0; i < 4; i++ {
+		raw.Variables = append(raw.Variables, &pb.IntegerVariableProto{Domain: []int64{0, 1}})
+	}
+	raw.Constraints = []*pb.ConstraintProto{
+		{
+			EnforcementLiteral: []int32{3},
+			Constraint: &pb.ConstraintProto_Linear{Linear: &pb.LinearConstraintProto{
+				Vars: []int32{0, 1, 2}, Coeffs: []int64{1, 1, 1}, Domain: []int64{0, 1},
+			}},
+		},
+		{Constraint: &pb.ConstraintProto_BoolAnd{BoolAnd: &pb.BoolArgumentProto{
+			Literals: []int32{0, 1},
+		}}},
+	}
+	raw.Objective = &pb.CpObjectiveProto{Vars: []int32{3}, Coeffs: []int64{-1}, ScalingFactor: -1}
+
+	requireEquivalent(t, model, fromProto(raw))
+}
+
+func TestDifferentialBuilder(t *testing.T) {
+	// Models built incrementally are equivalent to ones built directly.
+	model := NewModel("")
+	b := NewModelBuilder("", WithChunkSize(2))
+	var direct, built []IntVar
+	for i := 0; i < 5; i++ {
+		direct = append(direct, model.NewIntVar(0, 4, ""))
+		built = append(built, b.NewIntVar(0, 4, ""))
+	}
+	model.AddConstraints(NewAllDifferentConstraint(direct...))
+	b.AddConstraints(NewAllDifferentConstraint(built...))
+	for i := 1; i < 5; i++ {
+		model.AddConstraints(NewLinearConstraint(Sum(direct[i-1], direct[i]), NewDomain(3, 5)))
+		b.AddConstraints(NewLinearConstraint(Sum(built[i-1], built[i]), NewDomain(3, 5)))
+	}
+	model.Minimize(Sum(direct[0]))
+	builtModel := b.Model()
+	builtModel.Minimize(Sum(built[0]))
+
+	requireEquivalent(t, model, builtModel)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SolveDiverse solves the model repeatedly to find up to k solutions, each
+// differing from every other in the values of at least minDistance variables
+// (constants aside). After each solve, the model is constrained to be at
+// least that Hamming distance away from the solution found, which makes for
+// meaningful alternatives unlike the near-duplicates enumeration (see
+// WithEnumeration) typically produces. The model itself isn't modified.
+//
+// For models with objectives, each solution is the best one at the required
+// distance from the ones before it. Fewer than k results are returned if
+// there aren't enough sufficiently diverse solutions (or if a solve fails to
+// find one, with a timeout say).
+func (m *Model) SolveDiverse(k, minDistance int, os ...Option) Results {
+	if minDistance < 1 {
+		panic(fmt.Sprintf("invalid minimum distance: %d", minDistance))
+	}
+
+	m.finalize()
+	model := proto.Clone(m.pb).(*pb.CpModelProto)
+	var vars []int32 // the variables solutions are to differ in
+	for i, v := range m.pb.GetVariables() {
+		if d := v.GetDomain(); len(d) != 2 || d[0] != d[1] {
+			vars = append(vars, int32(i))
+		}
+	}
+
+	var results Results
+	for len(results) < k {
+		result := m.solve(model, os...)
+		if !result.solved() {
+			break
+		}
+		// Leave out the variables we've added.
+		result.pb.Solution = result.pb.Solution[:len(m.pb.GetVariables())]
+		results = append(results, result)
+		addDistanceFrom(model, vars, result.pb.GetSolution(), minDistance)
+	}
+	return results
+}
+
+// addDistanceFrom constrains the given model proto to differ from the given
+// solution in the values of at least minDistance of the given variables. For
+// each variable, we add a literal that if true, forces the variable to differ.
+func addDistanceFrom(model *pb.CpModelProto, vars []int32, solution []int64, minDistance int) {
+	differs := make([]int32, len(vars))
+	for i, v := range vars {
+		differs[i] = int32(len(model.Variables))
+		model.Variables = append(model.Variables, &pb.IntegerVariableProto{Domain: []int64{0, 1}})
+
+		value := solution[v]
+		var domain []int64
+		if value > math.MinInt64 {
+			domain = append(domain, math.MinInt64, value-1)
+		}
+		if value < math.MaxInt64 {
+			domain = append(domain, value+1, math.MaxInt64)
+		}
+		model.Constraints = append(model.Constraints, &pb.ConstraintProto{
+			EnforcementLiteral: []int32{differs[i]},
+			Constraint: &pb.ConstraintProto_Linear{
+				Linear: &pb.LinearConstraintProto{Vars: []int32{v}, Coeffs: []int64{1}, Domain: domain},
+			},
+		})
+	}
+
+	coeffs := make([]int64, len(differs))
+	for i := range coeffs {
+		coeffs[i] = 1
+	}
+	ub := int64(len(differs))
+	if ub < int64(minDistance) {
+		ub = int64(minDistance) // there can't be another solution, we're infeasible regardless
+	}
+	model.Constraints = append(model.Constraints, &pb.ConstraintProto{
+		Constraint: &pb.ConstraintProto_Linear{
+			Linear: &pb.LinearConstraintProto{
+				Vars:   differs,
+				Coeffs: coeffs,
+				Domain: []int64{int64(minDistance), ub},
+			},
+		},
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDistanceFrom(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.NewConstant(42, "c")
+	y := model.NewIntVar(0, 10, "y")
+
+	proto := model.pb
+	addDistanceFrom(proto, []int32{x.index(), y.index()}, []int64{3, 42, 0}, 1)
+	require.Len(t, proto.GetVariables(), 5)
+	require.Len(t, proto.GetConstraints(), 3)
+	require.Equal(t, []int32{3}, proto.Constraints[0].GetEnforcementLiteral())
+	require.Equal(t, []int64{math.MinInt64, 2, 4, math.MaxInt64}, proto.Constraints[0].GetLinear().GetDomain())
+	require.Equal(t, []int64{math.MinInt64, -1, 1, math.MaxInt64}, proto.Constraints[1].GetLinear().GetDomain())
+	require.Equal(t, []int32{3, 4}, proto.Constraints[2].GetLinear().GetVars())
+	require.Equal(t, []int64{1, 2}, proto.Constraints[2].GetLinear().GetDomain())
+}
+
+func TestSolveDiverse(t *testing.T) {
+	model := NewModel("")
+	var vars []IntVar
+	for i := 0; i < 3; i++ {
+		vars = append(vars, model.NewLiteral(""))
+	}
+
+	// There are at most four binary strings of length three that pairwise
+	// differ in at least two positions.
+	results := model.SolveDiverse(10, 2)
+	require.Len(t, results, 4)
+	require.Len(t, model.pb.GetConstraints(), 0)
+	for i := range results {
+		require.Len(t, results[i].pb.GetSolution(), 3)
+		for j := 0; j < i; j++ {
+			distance := 0
+			for _, v := range vars {
+				if results[i].Value(v) != results[j].Value(v) {
+					distance++
+				}
+			}
+			require.True(t, distance >= 2)
+		}
+	}
+	require.Len(t, model.SolveDiverse(2, 2), 2)
+}
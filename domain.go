@@ -42,6 +42,8 @@ type Domain interface {
 
 	list(shift int64) []int64
 	positive() bool
+	contains(v int64) bool
+	fixed(v int64) bool
 }
 
 type domain struct {
@@ -118,7 +120,63 @@ func (d *domain) list(shift int64) []int64 {
 	return ls
 }
 
-// domain is part of the Domain interface.
+// complement returns the domain of all int64 values not in d. It may be
+// empty.
+func (d *domain) complement() *domain {
+	var intervals []int64
+	next := int64(math.MinInt64) // the smallest value not yet accounted for
+	for i := 0; i < len(d.intervals); i += 2 {
+		min, max := d.intervals[i], d.intervals[i+1]
+		if min > next {
+			intervals = append(intervals, next, min-1)
+		}
+		if max == math.MaxInt64 {
+			return &domain{intervals: intervals}
+		}
+		next = max + 1
+	}
+	return &domain{intervals: append(intervals, next, math.MaxInt64)}
+}
+
+// intersect returns the domain of values in both d and o. It may be empty.
+func (d *domain) intersect(o *domain) *domain {
+	var intervals []int64
+	for i, j := 0, 0; i < len(d.intervals) && j < len(o.intervals); {
+		lo, hi := d.intervals[i], d.intervals[i+1]
+		if o.intervals[j] > lo {
+			lo = o.intervals[j]
+		}
+		if o.intervals[j+1] < hi {
+			hi = o.intervals[j+1]
+		}
+		if lo <= hi {
+			intervals = append(intervals, lo, hi)
+		}
+		if d.intervals[i+1] < o.intervals[j+1] {
+			i += 2
+		} else {
+			j += 2
+		}
+	}
+	return &domain{intervals: intervals}
+}
+
+// positive is part of the Domain interface.
 func (d *domain) positive() bool {
 	return d.intervals[0] >= 0
 }
+
+// contains is part of the Domain interface.
+func (d *domain) contains(v int64) bool {
+	for i := 0; i < len(d.intervals); i += 2 {
+		if min, max := d.intervals[i], d.intervals[i+1]; min <= v && v <= max {
+			return true
+		}
+	}
+	return false
+}
+
+// fixed is part of the Domain interface.
+func (d *domain) fixed(v int64) bool {
+	return len(d.intervals) == 2 && d.intervals[0] == v && d.intervals[1] == v
+}
@@ -15,6 +15,7 @@
 package solver
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -40,3 +41,30 @@ func TestDomainList(t *testing.T) {
 	require.Equal(t, []int64{0, 12, 24, 32}, NewDomain(0, 12, 24, 32).list(0))
 	require.Equal(t, []int64{-2, 10, 22, 30}, NewDomain(0, 12, 24, 32).list(2))
 }
+
+func TestDomainContains(t *testing.T) {
+	d := NewDomain(0, 2, 5, 7)
+	for _, v := range []int64{0, 1, 2, 5, 6, 7} {
+		require.True(t, d.contains(v), v)
+	}
+	for _, v := range []int64{-1, 3, 4, 8} {
+		require.False(t, d.contains(v), v)
+	}
+}
+
+func TestDomainFixed(t *testing.T) {
+	require.True(t, NewDomain(0, 0).fixed(0))
+	require.False(t, NewDomain(0, 0).fixed(1))
+	require.False(t, NewDomain(0, 1).fixed(0))
+	require.False(t, NewDomain(0, 0, 2, 2).fixed(0))
+}
+
+func TestDomainComplement(t *testing.T) {
+	complement := func(d Domain) []int64 {
+		return d.(*domain).complement().intervals
+	}
+	require.Equal(t, []int64{math.MinInt64, -1, 3, 4, 8, math.MaxInt64}, complement(NewDomain(0, 2, 5, 7)))
+	require.Equal(t, []int64{1, math.MaxInt64}, complement(NewDomain(math.MinInt64, 0)))
+	require.Equal(t, []int64{math.MinInt64, -1}, complement(NewDomain(0, math.MaxInt64)))
+	require.Empty(t, complement(NewDomain(math.MinInt64, math.MaxInt64)))
+}
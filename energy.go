@@ -0,0 +1,120 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NewEnergyConstraint ensures that the total energy of the given intervals,
+// their sizes multiplied by their demands (intervals[i]'s demand is specified
+// in demands[i]), doesn't exceed the given capacity over the horizon. Optional
+// intervals (see Interval.OnlyEnforceIf) only contribute energy if present.
+//
+// It's a relaxation of NewCumulativeConstraint that the solver propagates
+// more eagerly, and which significantly strengthens cumulative models when
+// added alongside them. To bound the energy within a time window (with a
+// correspondingly smaller capacity), pass in the intervals constrained to lie
+// within it.
+//
+// Each interval's energy is captured using an auxiliary variable, instantiated
+// in the model right away. The returned constraint still needs to be added to
+// the model.
+func (m *Model) NewEnergyConstraint(intervals []Interval, demands []IntVar, horizonCapacity LinearExpr) Constraint {
+	if len(intervals) != len(demands) {
+		return invalidConstraint(demands,
+			"energy: mismatched lengths of intervals (%d: %s) and demands (%d: %s)",
+			len(intervals), intervalList(intervals).names(), len(demands), intVarList(demands).names())
+	}
+
+	var b strings.Builder
+	for i := range intervals {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%s: %s", intervals[i].name(), demands[i].name()))
+	}
+
+	var cs []Constraint
+	vars, coeffs, offset := horizonCapacity.Parameters()
+	vars, coeffs = append([]IntVar(nil), vars...), append([]int64(nil), coeffs...)
+	_, slack := linearBounds(horizonCapacity)
+	for i, itv := range intervals {
+		_, _, size := itv.Parameters()
+		factors := []IntVar{size, demands[i]}
+		if presence := itv.(*interval).enforcement; presence != nil {
+			factors = append(factors, presence)
+		}
+		lb, ub, ok := productBounds(factors)
+		if !ok {
+			return invalidConstraint(demands, "energy: possible integer overflow (%s: %s * %s)",
+				itv.name(), size.name(), demands[i].name())
+		}
+		energy := m.NewIntVar(lb, ub, derivedName(itv.(*interval).pb.GetName(), "energy"))
+		cs = append(cs, NewProductConstraint(energy, factors...))
+
+		vars, coeffs = append(vars, energy), append(coeffs, -1)
+		slack -= lb
+	}
+	if slack < 0 {
+		slack = 0 // the energy can't possibly fit, we're infeasible regardless
+	}
+	cs = append(cs, NewLinearConstraint(NewLinearExpr(vars, coeffs, offset), NewDomain(0, slack)))
+	return constraints{
+		cs:  cs,
+		str: fmt.Sprintf("energy: %s | %s", b.String(), horizonCapacity.String()),
+	}
+}
+
+// productBounds returns the bounds of the product of the given variables,
+// and whether it could overflow an int64.
+func productBounds(factors []IntVar) (lb, ub int64, ok bool) {
+	lb, ub = 1, 1
+	for _, f := range factors {
+		flb, fub := Bounds(f)
+		var candidates []int64
+		for _, pair := range [][2]int64{{lb, flb}, {lb, fub}, {ub, flb}, {ub, fub}} {
+			c, ok := mul(pair[0], pair[1])
+			if !ok {
+				return 0, 0, false
+			}
+			candidates = append(candidates, c)
+		}
+		lb, ub = candidates[0], candidates[0]
+		for _, c := range candidates[1:] {
+			if c < lb {
+				lb = c
+			}
+			if c > ub {
+				ub = c
+			}
+		}
+	}
+	return lb, ub, true
+}
+
+// mul returns a*b, and whether it fits in an int64.
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithConstructionErrors configures the model to accumulate the errors
+// encountered when constructing constraints and expressions over its
+// variables (mismatched argument lengths, say) instead of panicking. The
+// errors are retrievable using Model.Err. Invalid constraints are left out of
+// the model, and invalid expressions are treated as empty ones; models with
+// construction errors are neither valid (see Model.Validate) nor solved, the
+// result of solving them being Invalid() with Err() returning the errors.
+func WithConstructionErrors() ModelOption {
+	return func(m *Model) {
+		m.accumulateErrors = true
+	}
+}
+
+// Err returns the errors accumulated when constructing the model, if any. See
+// WithConstructionErrors.
+func (m *Model) Err() error {
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return m.errs
+}
+
+// constructionErrors is a list of errors encountered when constructing a
+// model.
+type constructionErrors []error
+
+// Error is part of the error interface.
+func (es constructionErrors) Error() string {
+	if len(es) == 1 {
+		return es[0].Error()
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d construction errors: ", len(es)))
+	for i, err := range es {
+		if i != 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// fail reports an error encountered when constructing the model, prefixed
+// with the model's name (if any). If the model was configured using
+// WithConstructionErrors, the error is recorded and returned; otherwise we
+// panic. It's safe to call on a nil model, which always panics.
+func (m *Model) fail(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if m == nil {
+		panic(msg)
+	}
+	if name := m.pb.GetName(); name != "" {
+		msg = fmt.Sprintf("model %s: %s", m.name(), msg)
+	}
+	if m.callers != nil {
+		msg = fmt.Sprintf("%s [%s]", msg, caller())
+	}
+	if !m.accumulateErrors {
+		panic(msg)
+	}
+	err := errors.New(msg)
+	if m.concurrent != nil {
+		// Constraints are constructed outside of the model, and may be
+		// reporting errors from multiple goroutines.
+		m.concurrent.errsMu.Lock()
+		defer m.concurrent.errsMu.Unlock()
+	}
+	m.errs = append(m.errs, err)
+	return err
+}
+
+// invalidConstraint reports an error encountered when constructing a
+// constraint over the given variables (see Model.fail), returning a
+// placeholder that has no effect when added to a model.
+func invalidConstraint(vars []IntVar, format string, args ...interface{}) Constraint {
+	err := modelFor(vars...).fail(format, args...)
+	return constraints{str: fmt.Sprintf("invalid: %v", err)}
+}
+
+// modelFor returns the model the given variables were instantiated in, if
+// any.
+func modelFor(vars ...IntVar) *Model {
+	for _, v := range vars {
+		if iv, ok := v.(*intVar); ok && iv.model != nil {
+			return iv.model
+		}
+	}
+	return nil
+}
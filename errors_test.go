@@ -0,0 +1,83 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructionPanics(t *testing.T) {
+	model := NewModel("m")
+	a, b := model.NewIntVar(0, 10, "a"), model.NewIntVar(0, 10, "b")
+	c := model.NewIntVar(-5, 5, "c")
+	x, y := model.NewLiteral("x"), model.NewLiteral("y")
+
+	require.PanicsWithValue(t,
+		"model m: modulo (a == b % c): invalid domain for divisor c in [-5, 5]: not strictly positive",
+		func() { NewModuloConstraint(a, b, c) })
+	require.PanicsWithValue(t,
+		"model m: routes: mismatched lengths of tails (2), heads (1) and literals (2: x, y)",
+		func() { NewRoutesConstraint([]int{0, 1}, []int{1}, []Literal{x, y}) })
+	require.PanicsWithValue(t,
+		"model m: forbidden-assignments: mismatched length of assignment #1 (1) and vars (2: a, b)",
+		func() { NewForbiddenAssignmentsConstraint([]IntVar{a, b}, [][]int64{{0, 1}, {2}}) })
+	require.PanicsWithValue(t,
+		"model m: coverage objective: mismatched number of literals (2: x, y) and weights (1)",
+		func() { model.MaximizeCoverage([]Literal{x, y}, []int64{1}) })
+
+	// Without a model to refer to, we only have the constraint to go by.
+	require.PanicsWithValue(t,
+		"routes: mismatched lengths of tails (1), heads (0) and literals (0: )",
+		func() { NewRoutesConstraint([]int{0}, nil, nil) })
+}
+
+func TestConstructionErrors(t *testing.T) {
+	model := NewModel("m", WithConstructionErrors())
+	a, b := model.NewIntVar(0, 10, "a"), model.NewIntVar(0, 10, "b")
+	c := model.NewIntVar(-5, 5, "c")
+	x, y := model.NewLiteral("x"), model.NewLiteral("y")
+	require.NoError(t, model.Err())
+
+	model.AddConstraints(
+		NewAllDifferentConstraint(a, b),
+		NewModuloConstraint(a, b, c),
+		NewRoutesConstraint([]int{0, 1}, []int{1}, []Literal{x, y}).WithName("routes"),
+		NewCumulativeConstraint(model.NewConstant(1, "C"), nil, []IntVar{a}).OnlyEnforceIf(x),
+	)
+	model.Minimize(ScalProd([]IntVar{a, b}, []int64{1}))
+	model.MaximizeCoverage([]Literal{x, y}, []int64{1})
+
+	// Invalid constraints and expressions are left out of the model.
+	require.Len(t, model.pb.GetConstraints(), 1)
+	require.Empty(t, model.pb.GetObjective().GetVars())
+
+	require.EqualError(t, model.Err(), "5 construction errors: "+
+		"model m: modulo (a == b % c): invalid domain for divisor c in [-5, 5]: not strictly positive; "+
+		"model m: routes: mismatched lengths of tails (2), heads (1) and literals (2: x, y); "+
+		"model m: cumulative: mismatched lengths of intervals (0: ) and demands (1: a); "+
+		"model m: scalar product: mismatched number of variables (2: a, b) and coefficients (1); "+
+		"model m: coverage objective: mismatched number of literals (2: x, y) and weights (1)")
+
+	// Since they're left out, models with construction errors can't be
+	// solved.
+	ok, err := model.Validate()
+	require.False(t, ok)
+	require.Equal(t, model.Err(), err)
+	result := model.Solve()
+	require.True(t, result.Invalid())
+	require.Equal(t, model.Err(), result.Err())
+}
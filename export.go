@@ -0,0 +1,340 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Export writes out the model as a binary CpModelProto, finalizing it first
+// (see Model.Finalize, except it's not frozen). It can be loaded back using
+// LoadModel, or used directly with OR-Tools' own tooling.
+func (m *Model) Export(w io.Writer) error {
+	buf, err := proto.Marshal(m.finalized().pb)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// ExportText is like Export, except it writes out the model using the protobuf
+// text format (see ExportTextProto). It can be loaded back using
+// LoadModelText.
+func (m *Model) ExportText(w io.Writer) error {
+	_, err := io.WriteString(w, m.ExportTextProto())
+	return err
+}
+
+// ExportTextProto returns the model in the protobuf text format as printed by
+// OR-Tools itself (fields in declaration order, one per line, indented by two
+// spaces), finalizing it first. It can be fed into upstream tooling, like the
+// solve binary or cp_model_fuzzer, when triaging models built here. Unlike
+// prototext's, the output is stable, so it can be diffed and checked in.
+func (m *Model) ExportTextProto() string {
+	var b strings.Builder
+	writeTextProto(&b, m.finalized().pb.ProtoReflect(), 0)
+	return b.String()
+}
+
+// LoadModel reads a model written out using Model.Export (or any binary
+// CpModelProto). The model's variables, literals (variables with domain
+// [0, 1]), constants and intervals are reconstructed from the proto, and can
+// be retrieved using Model.Variables and Model.Intervals. Only what's captured
+// by the proto is retained: the objective, hints, assumptions and decision
+// strategies included, but not Go-side bookkeeping like where things were
+// added from (see WithCallerTracking). Malformed protos, ones referring to
+// variables that don't exist say, are rejected with an error.
+func LoadModel(r io.Reader, opts ...ModelOption) (*Model, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var model pb.CpModelProto
+	if err := proto.Unmarshal(buf, &model); err != nil {
+		return nil, fmt.Errorf("loading model: %w", err)
+	}
+	return loadModel(&model, opts...)
+}
+
+// LoadModelText is like LoadModel, except it reads models written out using
+// the protobuf text format (see Model.ExportText).
+func LoadModelText(r io.Reader, opts ...ModelOption) (*Model, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var model pb.CpModelProto
+	if err := prototext.Unmarshal(buf, &model); err != nil {
+		return nil, fmt.Errorf("loading model: %w", err)
+	}
+	return loadModel(&model, opts...)
+}
+
+// Variables returns all the model's variables (literals and constants
+// included), ordered by index.
+func (m *Model) Variables() []IntVar {
+	return m.variables()
+}
+
+// Intervals returns all the model's intervals. It's empty for models built
+// without introspection (see WithoutIntrospection).
+func (m *Model) Intervals() []Interval {
+	return m.intervals
+}
+
+// loadModel reconstructs a model, alongside the Go-side representations of
+// what it's made up of, from the given proto. Malformed protos, ones that
+// can't be reconstructed, are rejected (see checkLoadable).
+func loadModel(model *pb.CpModelProto, opts ...ModelOption) (*Model, error) {
+	if err := checkLoadable(model); err != nil {
+		return nil, fmt.Errorf("loading model: %w", err)
+	}
+
+	m := NewModel(model.GetName(), opts...)
+	m.pb = model
+
+	vars := make([]*intVar, len(model.GetVariables()))
+	for i, v := range model.GetVariables() {
+		d := &domain{intervals: v.GetDomain()}
+		literal := len(d.intervals) == 2 && d.intervals[0] == 0 && d.intervals[1] == 1
+		constant := len(d.intervals) == 2 && d.intervals[0] == d.intervals[1] && !literal
+		iv := newIntVar(d, int32(i), literal, constant, v.GetName())
+		iv.pb, iv.model = v, m
+		vars[i] = iv
+		if !m.introspect() {
+			continue
+		}
+		switch {
+		case literal:
+			m.literals = append(m.literals, iv)
+		case constant:
+			m.constants = append(m.constants, iv)
+		default:
+			m.vars = append(m.vars, iv)
+		}
+	}
+	ref := func(r int32) *intVar {
+		if r < 0 {
+			return vars[-r-1].Not().(*intVar)
+		}
+		return vars[r]
+	}
+
+	if m.introspect() {
+		for i, ct := range model.GetConstraints() {
+			if arg := ct.GetInterval(); arg != nil {
+				itv := &interval{
+					pb:    ct,
+					idx:   int32(i),
+					start: ref(arg.GetStart()), end: ref(arg.GetEnd()), size: ref(arg.GetSize()),
+				}
+				if lits := ct.GetEnforcementLiteral(); len(lits) == 1 {
+					itv.enforcement = ref(lits[0])
+				}
+				m.intervals = append(m.intervals, itv)
+				continue
+			}
+			m.constraints = append(m.constraints, &constraint{pb: ct, str: loadedDescription(ct, ref)})
+		}
+	}
+
+	for _, r := range model.GetAssumptions() {
+		m.assumptions = append(m.assumptions, ref(r))
+	}
+	if objective := model.GetObjective(); objective != nil {
+		var ivs []IntVar
+		coeffs := append([]int64(nil), objective.GetCoeffs()...)
+		offset := objective.GetOffset()
+		for _, r := range objective.GetVars() {
+			ivs = append(ivs, ref(r))
+		}
+		m.minimize = objective.GetScalingFactor() >= 0
+		if !m.minimize {
+			for i := range coeffs {
+				coeffs[i] = -coeffs[i]
+			}
+			offset = -offset
+		}
+		m.objective = NewLinearExpr(ivs, coeffs, int64(offset))
+	}
+	return m, nil
+}
+
+// checkLoadable checks that the given proto can be reconstructed into a model:
+// the domains of its variables are well-formed, everything it refers to
+// exists (see Model.CheckReferences), and its objective is integral. It doesn't
+// otherwise check that the model is valid (see Model.Validate).
+func checkLoadable(model *pb.CpModelProto) error {
+	for i, v := range model.GetVariables() {
+		if d := v.GetDomain(); len(d) == 0 || len(d)%2 != 0 {
+			return fmt.Errorf("variable #%d: malformed domain %v", i, d)
+		}
+	}
+
+	c := &referenceChecker{model: model}
+	for i, ct := range model.GetConstraints() {
+		if err := c.constraint(ct); err != nil {
+			return fmt.Errorf("constraint #%d: %v", i, err)
+		}
+	}
+	objective := model.GetObjective()
+	if err := c.variables(objective.GetVars()...); err != nil {
+		return fmt.Errorf("objective: %v", err)
+	}
+	if len(objective.GetVars()) != len(objective.GetCoeffs()) {
+		return fmt.Errorf("objective: mismatched number of variables (%d) and coefficients (%d)",
+			len(objective.GetVars()), len(objective.GetCoeffs()))
+	}
+	if offset := objective.GetOffset(); offset != math.Trunc(offset) ||
+		offset < math.MinInt64 || offset >= math.MaxInt64 {
+		return fmt.Errorf("objective: offset %v isn't a 64-bit integer", offset)
+	}
+	hint := model.GetSolutionHint()
+	if err := c.variables(hint.GetVars()...); err != nil {
+		return fmt.Errorf("hint: %v", err)
+	}
+	if len(hint.GetVars()) != len(hint.GetValues()) {
+		return fmt.Errorf("hint: mismatched number of variables (%d) and values (%d)",
+			len(hint.GetVars()), len(hint.GetValues()))
+	}
+	if err := c.literals(model.GetAssumptions()...); err != nil {
+		return fmt.Errorf("assumptions: %v", err)
+	}
+	for i, s := range model.GetSearchStrategy() {
+		if err := c.variables(s.GetVariables()...); err != nil {
+			return fmt.Errorf("decision strategy #%d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// loadedDescription describes a constraint loaded from a proto, using the
+// kind of constraint it is and the variables it refers to. References that
+// can't be resolved (ref returning nil) are described by index.
+func loadedDescription(ct *pb.ConstraintProto, ref func(int32) *intVar) string {
+	kind := strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", ct.GetConstraint()), "*pb.ConstraintProto_"))
+	refs := referencesOf(ct)
+	var names []string
+	for _, r := range append(append([]int32(nil), refs.variables...), refs.literals...) {
+		if iv := ref(r); iv != nil {
+			names = append(names, iv.name())
+		} else {
+			names = append(names, fmt.Sprintf("#%d", r))
+		}
+	}
+	return fmt.Sprintf("%s: %s", kind, strings.Join(names, ", "))
+}
+
+// writeTextProto writes out the given message using the protobuf text format,
+// the way the C++ implementation does it.
+func writeTextProto(b *strings.Builder, msg protoreflect.Message, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+		var values []protoreflect.Value
+		if fd.IsList() {
+			list := msg.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				values = append(values, list.Get(j))
+			}
+		} else {
+			values = append(values, msg.Get(fd))
+		}
+		for _, v := range values {
+			if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+				fmt.Fprintf(b, "%s%s {\n", indent, fd.Name())
+				writeTextProto(b, v.Message(), depth+1)
+				fmt.Fprintf(b, "%s}\n", indent)
+				continue
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", indent, fd.Name(), textProtoValue(fd, v))
+		}
+	}
+}
+
+// textProtoValue formats the given scalar value using the protobuf text
+// format.
+func textProtoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.StringKind:
+		return textProtoQuote([]byte(v.String()))
+	case protoreflect.BytesKind:
+		return textProtoQuote(v.Bytes())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		switch {
+		case math.IsInf(f, 1):
+			return "inf"
+		case math.IsInf(f, -1):
+			return "-inf"
+		case math.IsNaN(f):
+			return "nan"
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	default:
+		return v.String()
+	}
+}
+
+// textProtoQuote quotes the given string, C-escaping it the way the C++
+// implementation does.
+func textProtoQuote(s []byte) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestExport(t *testing.T) {
+	model := NewModel("export")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	present := model.NewLiteral("present")
+	model.NewInterval(x, y, model.NewConstant(3, "size"), "itv").OnlyEnforceIf(present)
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 15)))
+	model.AddAssumptions(present.Not())
+	model.Maximize(Sum(x))
+
+	for _, tc := range []struct {
+		name   string
+		export func(*Model, *bytes.Buffer) error
+		load   func(*bytes.Buffer) (*Model, error)
+	}{
+		{
+			name:   "binary",
+			export: func(m *Model, buf *bytes.Buffer) error { return m.Export(buf) },
+			load:   func(buf *bytes.Buffer) (*Model, error) { return LoadModel(buf) },
+		},
+		{
+			name:   "text",
+			export: func(m *Model, buf *bytes.Buffer) error { return m.ExportText(buf) },
+			load:   func(buf *bytes.Buffer) (*Model, error) { return LoadModelText(buf) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tc.export(model, &buf))
+			loaded, err := tc.load(&buf)
+			require.NoError(t, err)
+
+			var names []string
+			for _, v := range loaded.Variables() {
+				names = append(names, v.String())
+			}
+			require.Equal(t, []string{"x in [0, 10]", "y in [0, 10]", "present", "size == 3"}, names)
+			require.True(t, proto.Equal(model.pb, loaded.pb))
+
+			require.Contains(t, loaded.String(), "linear: x, y")
+
+			intervals := loaded.Intervals()
+			require.Len(t, intervals, 1)
+			require.Equal(t, "present", intervals[0].(*interval).enforcement.name())
+
+			e, maximize, ok := loaded.Objective()
+			require.True(t, ok)
+			require.True(t, maximize)
+			require.Equal(t, "x", e.String())
+			require.Len(t, loaded.assumptions, 1)
+			require.Equal(t, "~present", loaded.assumptions[0].name())
+
+			// The handles are bound to the loaded model, and can be used to
+			// extend it.
+			lx := loaded.Variables()[0]
+			loaded.AddConstraints(NewLinearConstraint(Sum(lx), NewDomain(0, 4)))
+			require.Len(t, loaded.pb.GetConstraints(), len(model.pb.GetConstraints())+1)
+		})
+	}
+
+	_, err := LoadModel(bytes.NewBufferString("garbage"))
+	require.Error(t, err)
+}
+
+func TestExportTextProto(t *testing.T) {
+	model := NewModel("triage")
+	x := model.NewIntVar(0, 10, "x \"é\"")
+	model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(2, 4)))
+	model.Maximize(Sum(x))
+
+	text := model.ExportTextProto()
+	require.Equal(t, `name: "triage"
+variables {
+  name: "x \"\303\251\""
+  domain: 0
+  domain: 10
+}
+constraints {
+  linear {
+    vars: 0
+    coeffs: 1
+    domain: 2
+    domain: 4
+  }
+}
+objective {
+  vars: 0
+  coeffs: -1
+  offset: -0
+  scaling_factor: -1
+}
+`, text)
+	require.Equal(t, text, model.ExportTextProto())
+
+	loaded, err := LoadModelText(bytes.NewBufferString(text))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(model.pb, loaded.pb))
+}
+
+func TestLoadMalformedModel(t *testing.T) {
+	variables := []*pb.IntegerVariableProto{{Domain: []int64{0, 1}}, {Domain: []int64{0, 10}}}
+	boolOr := func(lits ...int32) *pb.ConstraintProto {
+		return &pb.ConstraintProto{Constraint: &pb.ConstraintProto_BoolOr{BoolOr: &pb.BoolArgumentProto{Literals: lits}}}
+	}
+	for _, tc := range []struct {
+		model *pb.CpModelProto
+		err   string
+	}{
+		{
+			model: &pb.CpModelProto{Variables: []*pb.IntegerVariableProto{{Domain: []int64{0}}}},
+			err:   "variable #0: malformed domain [0]",
+		},
+		{
+			model: &pb.CpModelProto{Variables: variables, Constraints: []*pb.ConstraintProto{boolOr(0, -3)}},
+			err:   "constraint #0: variable #2 doesn't exist (2 variables)",
+		},
+		{
+			model: &pb.CpModelProto{Variables: variables, Constraints: []*pb.ConstraintProto{{
+				Constraint: &pb.ConstraintProto_Interval{Interval: &pb.IntervalConstraintProto{Start: 1, End: 7, Size: 1}},
+			}}},
+			err: "constraint #0: variable #7 doesn't exist (2 variables)",
+		},
+		{
+			model: &pb.CpModelProto{Variables: variables, Objective: &pb.CpObjectiveProto{Vars: []int32{5}, Coeffs: []int64{1}}},
+			err:   "objective: variable #5 doesn't exist (2 variables)",
+		},
+		{
+			model: &pb.CpModelProto{Variables: variables, Objective: &pb.CpObjectiveProto{Vars: []int32{1}, Coeffs: []int64{1}, Offset: 0.5}},
+			err:   "objective: offset 0.5 isn't a 64-bit integer",
+		},
+		{
+			model: &pb.CpModelProto{Variables: variables, Assumptions: []int32{1}},
+			err:   "assumptions: literal refers to non-boolean variable  (#1) with domain [0, 10]",
+		},
+	} {
+		buf, err := proto.Marshal(tc.model)
+		require.NoError(t, err)
+		_, err = LoadModel(bytes.NewReader(buf))
+		require.EqualError(t, err, "loading model: "+tc.err)
+	}
+}
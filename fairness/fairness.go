@@ -0,0 +1,120 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package fairness provides helpers to encode fairness requirements, as
+// commonly found in employee scheduling problems: spreading work evenly across
+// employees, keeping workloads close to a target, and so on. They're built out
+// of linear constraints over auxiliary variables, which are subtle to get
+// right by hand.
+//
+// The helpers are typically applied to variables capturing per-employee
+// workloads (the number of shifts assigned to each, say), and return
+// variables or expressions meant to be minimized.
+package fairness
+
+import "github.com/irfansharif/solver"
+
+// Spread returns a new variable constrained to be equal to the difference
+// between the largest and smallest values taken on by the given variables.
+// With no variables, the spread is zero.
+func Spread(m *solver.Model, vars []solver.IntVar) solver.IntVar {
+	if len(vars) == 0 {
+		return m.NewConstant(0, "")
+	}
+
+	lb, ub := solver.Bounds(vars[0])
+	for _, v := range vars[1:] {
+		vlb, vub := solver.Bounds(v)
+		lb, ub = min(lb, vlb), max(ub, vub)
+	}
+	hi := m.NewIntVar(lb, ub, "")
+	lo := m.NewIntVar(lb, ub, "")
+	spread := m.NewIntVar(0, ub-lb, "")
+	m.AddConstraints(
+		solver.NewMaximumConstraint(hi, vars...),
+		solver.NewMinimumConstraint(lo, vars...),
+		solver.NewLinearConstraint(
+			solver.NewLinearExpr([]solver.IntVar{spread, hi, lo}, []int64{1, -1, 1}, 0),
+			solver.NewDomain(0, 0),
+		),
+	)
+	return spread
+}
+
+// SpreadMinimization sets the model's objective to minimize the spread of the
+// given variables (see Spread), i.e. the difference between the largest and
+// smallest values taken on by them.
+func SpreadMinimization(m *solver.Model, vars []solver.IntVar) {
+	m.Minimize(solver.Sum(Spread(m, vars)))
+}
+
+// DeviationFromTarget returns an expression for the total deviation of the
+// given variables from the target, i.e. the sum of |vars[i] - target|.
+func DeviationFromTarget(m *solver.Model, vars []solver.IntVar, target int64) solver.LinearExpr {
+	var deviations []solver.IntVar
+	for _, v := range vars {
+		lb, ub := solver.Bounds(v)
+		deviation := m.NewIntVar(0, max(abs(lb-target), abs(ub-target)), "")
+		m.AddConstraints(solver.NewAbsConstraint(
+			solver.Sum(deviation),
+			solver.NewLinearExpr([]solver.IntVar{v}, []int64{1}, -target),
+		))
+		deviations = append(deviations, deviation)
+	}
+	return solver.Sum(deviations...)
+}
+
+// GiniProxy returns an expression for the sum of pairwise absolute
+// differences between the given variables, i.e. the sum of |vars[i] -
+// vars[j]| for all i < j. It's the numerator of the Gini coefficient, a
+// measure of inequality; the denominator is non-linear, but when the total
+// workload is fixed, minimizing the numerator alone is equivalent. It
+// introduces an auxiliary variable per pair.
+func GiniProxy(m *solver.Model, vars []solver.IntVar) solver.LinearExpr {
+	var gaps []solver.IntVar
+	for i := range vars {
+		ilb, iub := solver.Bounds(vars[i])
+		for j := i + 1; j < len(vars); j++ {
+			jlb, jub := solver.Bounds(vars[j])
+			gap := m.NewIntVar(0, max(abs(iub-jlb), abs(jub-ilb)), "")
+			m.AddConstraints(solver.NewAbsConstraint(
+				solver.Sum(gap),
+				solver.NewLinearExpr([]solver.IntVar{vars[i], vars[j]}, []int64{1, -1}, 0),
+			))
+			gaps = append(gaps, gap)
+		}
+	}
+	return solver.Sum(gaps...)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
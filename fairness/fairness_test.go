@@ -0,0 +1,71 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fairness
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/irfansharif/solver"
+	"github.com/stretchr/testify/require"
+)
+
+// workloads returns a model with the given number of employees, splitting the
+// given number of shifts between them.
+func workloads(employees int, shifts int64) (*solver.Model, []solver.IntVar) {
+	model := solver.NewModel("")
+	var vars []solver.IntVar
+	for i := 0; i < employees; i++ {
+		vars = append(vars, model.NewIntVar(0, shifts, fmt.Sprintf("e%d", i)))
+	}
+	model.AddConstraints(solver.NewLinearConstraint(solver.Sum(vars...), solver.NewDomain(shifts, shifts)))
+	return model, vars
+}
+
+func TestSpreadMinimization(t *testing.T) {
+	model, vars := workloads(3, 7)
+	SpreadMinimization(model, vars)
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(1), result.ObjectiveValue())
+}
+
+func TestSpreadWithoutVariables(t *testing.T) {
+	model := solver.NewModel("")
+	spread := Spread(model, nil)
+	require.Equal(t, "<unnamed> == 0", spread.String())
+}
+
+func TestDeviationFromTarget(t *testing.T) {
+	model, vars := workloads(3, 7)
+	model.Minimize(DeviationFromTarget(model, vars, 3))
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(1), result.ObjectiveValue())
+}
+
+func TestGiniProxy(t *testing.T) {
+	model, vars := workloads(3, 7)
+	model.Minimize(GiniProxy(model, vars))
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(2), result.ObjectiveValue()) // 3, 2, 2
+	for _, v := range vars {
+		require.Contains(t, []int64{2, 3}, result.Value(v))
+	}
+}
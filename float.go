@@ -0,0 +1,175 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FloatLinearExpr is a linear expression with fractional coefficients and
+// offset, fractional costs say. The underlying solver only works with
+// integers, so they're scaled up to integers (by a common power of ten) when
+// used in a model; see NewFloatLinearConstraint and Model.MinimizeFloat.
+type FloatLinearExpr struct {
+	vars   []IntVar
+	coeffs []float64
+	offset float64
+}
+
+// NewFloatLinearExpr instantiates a new linear expression with fractional
+// coefficients and offset, representing:
+//
+//   sum(coefficients[i] * vars[i]) + offset
+func NewFloatLinearExpr(vars []IntVar, coeffs []float64, offset float64) FloatLinearExpr {
+	return FloatLinearExpr{vars: vars, coeffs: coeffs, offset: offset}
+}
+
+// Scaled returns the expression scaled up to integer coefficients and offset,
+// alongside the scale used: the smallest power of ten for which the scaled
+// coefficients and offset, once rounded, are within the given precision (0.01,
+// say) of the originals. Errors are reported through the model the variables
+// were instantiated in (see WithConstructionErrors); expressions without any
+// panic instead.
+func (e FloatLinearExpr) Scaled(precision float64) (_ LinearExpr, scale int64) {
+	return e.scaled(modelFor(e.vars...), precision)
+}
+
+// scaled is like Scaled, reporting errors through the given model (if any).
+func (e FloatLinearExpr) scaled(m *Model, precision float64) (_ LinearExpr, scale int64) {
+	if len(e.vars) != len(e.coeffs) {
+		m.fail("float expression: mismatched number of variables (%d: %s) and coefficients (%d)",
+			len(e.vars), intVarList(e.vars).names(), len(e.coeffs))
+		return Sum(), 1
+	}
+	scale, err := floatScale(precision, append([]float64{e.offset}, e.coeffs...)...)
+	if err != nil {
+		m.fail("float expression %s: %v", e, err)
+		return Sum(), 1
+	}
+	return e.scaledBy(scale), scale
+}
+
+// String returns a printable representation of the expression.
+func (e FloatLinearExpr) String() string {
+	var b strings.Builder
+	for i, v := range e.vars {
+		if i != 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString(fmt.Sprintf("%g%s", e.coeffs[i], v.name()))
+	}
+	if len(e.vars) == 0 || e.offset != 0 {
+		if len(e.vars) != 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString(fmt.Sprintf("%g", e.offset))
+	}
+	return b.String()
+}
+
+// scaledBy returns the expression scaled up by the given factor, rounding the
+// coefficients and offset to the nearest integer.
+func (e FloatLinearExpr) scaledBy(scale int64) LinearExpr {
+	coeffs := make([]int64, len(e.coeffs))
+	for i, c := range e.coeffs {
+		coeffs[i] = int64(math.Round(c * float64(scale)))
+	}
+	return NewLinearExpr(e.vars, coeffs, int64(math.Round(e.offset*float64(scale))))
+}
+
+// NewFloatLinearConstraint ensures that the linear expression with fractional
+// coefficients lies within the given (fractional) bounds. The expression and
+// bounds are scaled up to integers by a common power of ten, the smallest for
+// which they're within the given precision of the originals (see
+// FloatLinearExpr.Scaled).
+func NewFloatLinearConstraint(e FloatLinearExpr, lb, ub, precision float64) Constraint {
+	if len(e.vars) != len(e.coeffs) {
+		return invalidConstraint(e.vars, "float-linear-constraint: mismatched number of variables (%d: %s) and coefficients (%d)",
+			len(e.vars), intVarList(e.vars).names(), len(e.coeffs))
+	}
+	scale, err := floatScale(precision, append([]float64{e.offset, lb, ub}, e.coeffs...)...)
+	if err != nil {
+		return invalidConstraint(e.vars, "float-linear-constraint: %s in [%g, %g]: %v", e, lb, ub, err)
+	}
+
+	s := float64(scale)
+	c := NewLinearConstraint(e.scaledBy(scale), NewDomain(int64(math.Round(lb*s)), int64(math.Round(ub*s))))
+	c.(*constraint).str = fmt.Sprintf("float-linear-constraint: %s in [%g, %g] (scaled by %d)", e, lb, ub, scale)
+	return c
+}
+
+// MinimizeFloat sets a minimization objective for the model, one with
+// fractional coefficients. It's scaled up to integers (see
+// FloatLinearExpr.Scaled), and the scale is reported on results (see
+// Result.ObjectiveScale and Result.FloatObjectiveValue). Penalties of soft
+// constraints (see Model.AddSoftConstraint) are added as is, in scaled units.
+func (m *Model) MinimizeFloat(e FloatLinearExpr, precision float64) {
+	scaled, scale := e.scaled(m, precision)
+	m.Minimize(scaled)
+	m.objectiveScale = scale
+}
+
+// MaximizeFloat is like MinimizeFloat, for maximization objectives.
+func (m *Model) MaximizeFloat(e FloatLinearExpr, precision float64) {
+	scaled, scale := e.scaled(m, precision)
+	m.Maximize(scaled)
+	m.objectiveScale = scale
+}
+
+// ObjectiveScale returns the factor the model's objective was scaled up by, for
+// models with fractional objectives (see Model.MinimizeFloat). It's 1
+// otherwise.
+func (r Result) ObjectiveScale() int64 {
+	if r.objectiveScale == 0 {
+		return 1
+	}
+	return r.objectiveScale
+}
+
+// FloatObjectiveValue is like ObjectiveValue, except it's scaled back down for
+// models with fractional objectives (see Model.MinimizeFloat). It's the value
+// of the rounded objective, within the precision the objective was scaled
+// with of the original's.
+func (r Result) FloatObjectiveValue() float64 {
+	return r.ObjectiveValue() / float64(r.ObjectiveScale())
+}
+
+// floatScale returns the smallest power of ten scaling the given values up to
+// integers, once rounded, within the given precision of the originals.
+func floatScale(precision float64, values ...float64) (int64, error) {
+	if !(precision > 0 && precision <= 1) {
+		return 0, fmt.Errorf("invalid precision %g: expected in (0, 1]", precision)
+	}
+	for scale := int64(1); ; scale *= 10 {
+		s := float64(scale)
+		exact := true
+		for _, v := range values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return 0, fmt.Errorf("invalid value %g", v)
+			}
+			if math.Abs(v*s) >= math.MaxInt64/2 {
+				return 0, fmt.Errorf("%g scaled by %d overflows", v, scale)
+			}
+			if math.Abs(math.Round(v*s)/s-v) > precision {
+				exact = false
+			}
+		}
+		if exact {
+			return scale, nil
+		}
+	}
+}
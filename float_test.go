@@ -0,0 +1,74 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatLinearExpr(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+
+	e := NewFloatLinearExpr([]IntVar{x, y}, []float64{0.5, 1.25}, 0.1)
+	require.Equal(t, "0.5x + 1.25y + 0.1", e.String())
+	scaled, scale := e.Scaled(0.001)
+	require.Equal(t, int64(100), scale)
+	require.Equal(t, "50x + 125y + 10", scaled.String())
+
+	// Coarser precisions make for smaller scales, rounding as needed.
+	scaled, scale = NewFloatLinearExpr([]IntVar{x}, []float64{1.0 / 3}, 0).Scaled(0.01)
+	require.Equal(t, int64(100), scale)
+	require.Equal(t, "33x", scaled.String())
+	_, scale = NewFloatLinearExpr([]IntVar{x}, []float64{2}, 0).Scaled(0.01)
+	require.Equal(t, int64(1), scale)
+
+	c := NewFloatLinearConstraint(e, 1.5, 7.75, 0.01)
+	require.Equal(t, "float-linear-constraint: 0.5x + 1.25y + 0.1 in [1.5, 7.75] (scaled by 100)", c.String())
+	require.Equal(t, []int64{50, 125}, c.protos()[0].GetLinear().GetCoeffs())
+	require.Equal(t, []int64{140, 765}, c.protos()[0].GetLinear().GetDomain())
+
+	require.PanicsWithValue(t, "float-linear-constraint: 0.5x + 1.25y + 0.1 in [0, 1]: invalid precision 0: expected in (0, 1]", func() {
+		NewFloatLinearConstraint(e, 0, 1, 0)
+	})
+
+	// Expressions without variables have no model to report errors through,
+	// unless they're used as objectives.
+	require.PanicsWithValue(t, "float expression 0.5: invalid precision 2: expected in (0, 1]", func() {
+		NewFloatLinearExpr(nil, nil, 0.5).Scaled(2)
+	})
+	lenient := NewModel("", WithConstructionErrors())
+	lenient.MinimizeFloat(NewFloatLinearExpr(nil, nil, 0.5), 2)
+	require.EqualError(t, lenient.Err(), "float expression 0.5: invalid precision 2: expected in (0, 1]")
+}
+
+func TestFloatObjective(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.MinimizeFloat(NewFloatLinearExpr([]IntVar{x}, []float64{0.25}, 0), 0.01)
+	require.Equal(t, int64(100), model.objectiveScale)
+	require.Equal(t, []int64{25}, model.pb.GetObjective().GetCoeffs())
+
+	result := Result{pb: &pb.CpSolverResponse{ObjectiveValue: 75}, objectiveScale: model.objectiveScale}
+	require.Equal(t, int64(100), result.ObjectiveScale())
+	require.Equal(t, 0.75, result.FloatObjectiveValue())
+
+	model.Minimize(Sum(x))
+	require.Zero(t, model.objectiveScale)
+	require.Equal(t, int64(1), Result{pb: &pb.CpSolverResponse{}}.ObjectiveScale())
+}
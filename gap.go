@@ -0,0 +1,62 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "fmt"
+
+// NewMinGapConstraint ensures that the given intervals don't overlap, with at
+// least the given gap between consecutive ones (for cleanup time between jobs
+// or meetings, say). Optional intervals (see Interval.OnlyEnforceIf) only
+// require gaps around them if present.
+//
+// It's expressed using "shadow" intervals: the given ones, extended by the gap,
+// that are made to not overlap. The shadow intervals (and the variables making
+// them up) are instantiated in the model right away. The returned constraint
+// still needs to be added to the model.
+func (m *Model) NewMinGapConstraint(intervals []Interval, gap int64) Constraint {
+	if gap < 0 {
+		return invalidConstraint(intervalList(intervals).starts(),
+			"min-gap (%s): negative gap (%d)", intervalList(intervals).names(), gap)
+	}
+	if gap == 0 {
+		return NewNonOverlappingConstraint(intervals...)
+	}
+
+	var cs []Constraint
+	shadows := make([]Interval, len(intervals))
+	for i, itv := range intervals {
+		start, end, size := itv.Parameters()
+		name := itv.(*interval).pb.GetName()
+
+		var extended IntVar
+		if lb, ub := Bounds(size); lb == ub {
+			extended = m.NewConstant(lb+gap, derivedName(name, "shadow.size"))
+		} else {
+			extended = m.NewIntVarFromDomain(&domain{intervals: size.domain().list(-gap)}, derivedName(name, "shadow.size"))
+			cs = append(cs, NewLinearConstraint(
+				NewLinearExpr([]IntVar{extended, size}, []int64{1, -1}, 0), NewDomain(gap, gap)))
+		}
+		shadowEnd := m.NewIntVarFromDomain(&domain{intervals: end.domain().list(-gap)}, derivedName(name, "shadow.end"))
+		shadows[i] = m.NewInterval(start, shadowEnd, extended, derivedName(name, "shadow"))
+		if presence := itv.(*interval).enforcement; presence != nil {
+			shadows[i].OnlyEnforceIf(presence)
+		}
+	}
+	cs = append(cs, NewNonOverlappingConstraint(shadows...))
+	return constraints{
+		cs:  cs,
+		str: fmt.Sprintf("min-gap: %s | %d", intervalList(intervals).names(), gap),
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewHammingDistanceConstraint ensures that the target is equal to the number
+// of positions where the two given lists of variables differ.
+//
+// It's expressed using literals indicating whether each position differs,
+// instantiated in the model right away; the returned constraint still needs
+// to be added to the model.
+func NewHammingDistanceConstraint(target IntVar, a, b []IntVar) Constraint {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("hamming-distance: %s == |", target.name()))
+	printVars(&s, a...)
+	s.WriteString(" ≠ ")
+	printVars(&s, b...)
+	s.WriteString("|")
+	str := s.String()
+
+	all := append(append([]IntVar{target}, a...), b...)
+	if len(a) != len(b) {
+		return invalidConstraint(all, "hamming-distance: mismatched lengths of a (%d: %s) and b (%d: %s)",
+			len(a), intVarList(a).names(), len(b), intVarList(b).names())
+	}
+	m := modelFor(all...)
+	if m == nil {
+		return invalidConstraint(all, "%s: variables not instantiated in a model", str)
+	}
+
+	var cs []Constraint
+	differs := make([]IntVar, len(a))
+	for i := range a {
+		lit := m.newAuxLiteral(derivedName(protoName(target), fmt.Sprintf("differs%d", i)))
+		cs = append(cs, Reify(NewInequalityConstraint(Sum(a[i]), Sum(b[i])), lit))
+		differs[i] = lit
+	}
+	cs = append(cs, NewEqualityConstraint(Sum(target), Sum(differs...)))
+	return constraints{cs: cs, str: str}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "github.com/irfansharif/solver/pb"
+
+// ConstraintHandle refers to a constraint added to a model (see
+// Model.AddConstraintHandles), and is used to retract it before solving: either
+// temporarily (see Disable) or for good (see Remove).
+type ConstraintHandle struct {
+	m *Model
+	c Constraint
+
+	// masked holds, for disabled constraints, their protos keyed by their
+	// position in the model; the positions hold trivially satisfied protos
+	// in the interim.
+	masked  map[int]*pb.ConstraintProto
+	removed bool
+}
+
+// AddConstraintHandles is like AddConstraints, also returning handles to the
+// constraints (in the same order), through which they can be retracted before
+// solving.
+func (m *Model) AddConstraintHandles(cs ...Constraint) []*ConstraintHandle {
+	m.AddConstraints(cs...)
+	handles := make([]*ConstraintHandle, len(cs))
+	for i, c := range cs {
+		handles[i] = &ConstraintHandle{m: m, c: c}
+	}
+	m.handles = append(m.handles, handles...)
+	return handles
+}
+
+// Constraint returns the constraint the handle refers to.
+func (h *ConstraintHandle) Constraint() Constraint {
+	return h.c
+}
+
+// Enabled returns whether the constraint is part of the model, i.e. it's
+// neither disabled nor removed.
+func (h *ConstraintHandle) Enabled() bool {
+	return !h.removed && h.masked == nil
+}
+
+// Disable retracts the constraint from the model until it's re-enabled (see
+// Enable). It's a no-op for constraints already disabled. Intervals can't be
+// disabled, since other constraints refer to them.
+func (h *ConstraintHandle) Disable() {
+	h.m.assertMutable()
+	if h.removed {
+		h.m.fail("cannot disable constraint %s: already removed", h.c.String())
+		return
+	}
+	if _, ok := h.c.(Interval); ok {
+		h.m.fail("cannot disable interval %s", h.c.String())
+		return
+	}
+	if h.masked != nil {
+		return
+	}
+
+	protos := make(map[*pb.ConstraintProto]bool)
+	for _, ct := range h.m.protosOf(h.c) {
+		protos[ct] = true
+	}
+	h.masked = make(map[int]*pb.ConstraintProto)
+	for i, ct := range h.m.pb.GetConstraints() {
+		if protos[ct] {
+			h.masked[i] = ct
+			h.m.pb.Constraints[i] = &pb.ConstraintProto{
+				Constraint: &pb.ConstraintProto_BoolAnd{BoolAnd: &pb.BoolArgumentProto{}},
+			}
+		}
+	}
+}
+
+// Enable adds back a constraint previously disabled (see Disable). It's a
+// no-op for constraints that aren't disabled.
+func (h *ConstraintHandle) Enable() {
+	h.m.assertMutable()
+	if h.removed {
+		h.m.fail("cannot enable constraint %s: already removed", h.c.String())
+		return
+	}
+	for i, ct := range h.masked {
+		h.m.pb.Constraints[i] = ct
+	}
+	h.masked = nil
+}
+
+// Remove removes the constraint from the model for good (see
+// RemoveConstraintChange). Intervals can't be removed, since other constraints
+// refer to them.
+func (h *ConstraintHandle) Remove() {
+	h.m.assertMutable()
+	if h.removed {
+		return
+	}
+	h.Enable()
+	h.removed = h.m.removeConstraint(h.c)
+}
+
+// disabled returns whether the given constraint was disabled through its
+// handle (see ConstraintHandle.Disable).
+func (m *Model) disabled(c Constraint) bool {
+	protos := m.protosOf(c)
+	if len(protos) == 0 {
+		return false
+	}
+	for _, h := range m.handles {
+		if h.masked == nil {
+			continue
+		}
+		masked := make(map[*pb.ConstraintProto]bool, len(h.masked))
+		for _, ct := range h.masked {
+			masked[ct] = true
+		}
+		if masked[protos[0]] {
+			return true
+		}
+	}
+	return false
+}
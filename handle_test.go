@@ -0,0 +1,65 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintHandles(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	handles := model.AddConstraintHandles(
+		NewLinearConstraint(Sum(x), NewDomain(2, 4)),
+		NewLinearConstraint(Sum(x), NewDomain(3, 10)),
+	)
+	require.Len(t, handles, 2)
+	lower, upper := handles[0], handles[1]
+	require.True(t, lower.Enabled())
+	require.Equal(t, "linear-constraint: x in [2, 4]", lower.Constraint().String())
+
+	ct := model.pb.GetConstraints()[0]
+	lower.Disable()
+	lower.Disable()
+	require.False(t, lower.Enabled())
+	require.Nil(t, model.pb.GetConstraints()[0].GetLinear())
+	require.Equal(t, ct, lower.Constraint().protos()[0])
+	require.NotContains(t, model.String(), "[2, 4]")
+	require.Contains(t, model.String(), "constraints (num = 1)")
+
+	lower.Enable()
+	require.True(t, lower.Enabled())
+	require.Equal(t, ct, model.pb.GetConstraints()[0])
+
+	upper.Disable()
+	upper.Remove()
+	require.False(t, upper.Enabled())
+	require.Len(t, model.pb.GetConstraints(), 2)
+	require.Nil(t, model.pb.GetConstraints()[1].GetLinear())
+	require.NotContains(t, model.String(), "[3, 10]")
+	require.PanicsWithValue(t, "cannot enable constraint linear-constraint: x in [3, 10]: already removed", upper.Enable)
+
+	itv := model.NewFixedSizeInterval(x, 2, "itv")
+	handle := model.AddConstraintHandles(NewNonOverlappingConstraint(itv))[0]
+	handle.Disable()
+	require.Nil(t, model.pb.GetConstraints()[len(model.pb.GetConstraints())-1].GetNoOverlap())
+	handle.Enable()
+	require.NotNil(t, model.pb.GetConstraints()[len(model.pb.GetConstraints())-1].GetNoOverlap())
+
+	model.Finalize()
+	require.Panics(t, lower.Disable)
+}
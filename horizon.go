@@ -0,0 +1,131 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// InferHorizon returns a scheduling horizon for the given intervals, derived
+// from the domains of their variables: a range of time that's sufficient to
+// schedule them all. It starts with the earliest possible start. It ends with
+// the latest possible end, or if earlier, once all the intervals could have
+// been scheduled back to back after the last of them could start.
+//
+// The horizon is sufficient for intervals constrained to not overlap or to
+// share a resource, but not necessarily in the presence of other constraints
+// (precedences with delays between intervals, say); it's a heuristic, to be
+// used with Model.SetHorizon.
+func InferHorizon(intervals []Interval) (min, max int64) {
+	if len(intervals) == 0 {
+		return 0, 0
+	}
+
+	var latestStart, totalSize int64
+	var ok, overflow bool
+	for i, itv := range intervals {
+		start, end, size := itv.Parameters()
+		slb, _ := Bounds(start)
+		_, eub := Bounds(end)
+		_, sub := Bounds(size)
+		if i == 0 || slb < min {
+			min = slb
+		}
+		if i == 0 || eub > max {
+			max = eub
+		}
+		if i == 0 || slb > latestStart {
+			latestStart = slb
+		}
+		if totalSize, ok = add(totalSize, sub); !ok {
+			overflow = true
+		}
+	}
+	if overflow {
+		return min, max // back to back could take forever
+	}
+	if end, ok := add(latestStart, totalSize); ok && end < max {
+		max = end
+	}
+	return min, max
+}
+
+// add returns a+b, and whether it fits in an int64.
+func add(a, b int64) (int64, bool) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, false
+	}
+	return c, true
+}
+
+// SetHorizon tightens the domains of the starts and ends of all the model's
+// intervals to lie within [lb, ub]. Loose horizons, the default when
+// instantiating variables over [0, math.MaxInt64] say, make for slow
+// scheduling solves; see InferHorizon for one way to pick a tighter one.
+// Variables used elsewhere in the model are tightened there too. Starts or ends
+// that are negated literals have the literals they negate tightened instead.
+func (m *Model) SetHorizon(lb, ub int64) {
+	m.assertMutable()
+	horizon := NewDomain(lb, ub).(*domain)
+
+	tightened := make(map[int32]*domain)
+	for _, ct := range m.pb.GetConstraints() {
+		itv := ct.GetInterval()
+		if itv == nil {
+			continue
+		}
+		for _, ref := range []int32{itv.GetStart(), itv.GetEnd()} {
+			idx, h := ref, horizon
+			if ref < 0 {
+				idx = -ref - 1
+				h = negatedLiteralHorizon(lb, ub)
+			}
+			variable := m.pb.GetVariables()[idx]
+			d := (&domain{intervals: variable.GetDomain()}).intersect(h)
+			if len(d.intervals) == 0 {
+				name := variable.GetName()
+				if ref < 0 {
+					name = "~" + name
+				}
+				m.fail("horizon %s: excludes the domain of %s%s",
+					horizon, name, m.variableLocation(idx))
+				continue
+			}
+			variable.Domain = d.intervals
+			tightened[idx] = d
+		}
+	}
+
+	// Keep the variables we hold onto in sync with their protos; others pick
+	// up the change from the protos they share with the model.
+	for _, iv := range m.vars {
+		if d, ok := tightened[iv.index()]; ok {
+			iv.(*intVar).d = d
+		}
+	}
+}
+
+// negatedLiteralHorizon returns the domain a literal is restricted to when its
+// negation is to lie within [lb, ub]: ~x lies within it iff x lies within
+// [1-ub, 1-lb]. Literals being over [0, 1], we clamp first to not overflow.
+func negatedLiteralHorizon(lb, ub int64) *domain {
+	if lb < 0 {
+		lb = 0
+	}
+	if ub > 1 {
+		ub = 1
+	}
+	if lb > ub {
+		return &domain{}
+	}
+	return &domain{intervals: []int64{1 - ub, 1 - lb}}
+}
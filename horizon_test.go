@@ -0,0 +1,83 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferHorizon(t *testing.T) {
+	model := NewModel("")
+	a := model.NewInterval(model.NewIntVar(2, 100, "as"), model.NewIntVar(0, 1000, "ae"), model.NewConstant(3, "ad"), "a")
+	b := model.NewInterval(model.NewIntVar(5, 100, "bs"), model.NewIntVar(0, 1000, "be"), model.NewIntVar(1, 4, "bd"), "b")
+	c := model.NewFixedInterval(10, 12, "c")
+
+	min, max := InferHorizon([]Interval{a, b})
+	require.Equal(t, int64(2), min)
+	require.Equal(t, int64(5+3+4), max)
+
+	min, max = InferHorizon([]Interval{a, b, c})
+	require.Equal(t, int64(2), min)
+	require.Equal(t, int64(10+3+4+2), max)
+
+	// The latest possible end is used if it's earlier.
+	d := model.NewInterval(model.NewIntVar(0, 5, "ds"), model.NewIntVar(0, 8, "de"), model.NewIntVar(0, 100, "dd"), "d")
+	min, max = InferHorizon([]Interval{d})
+	require.Equal(t, int64(0), min)
+	require.Equal(t, int64(8), max)
+
+	// Intervals that could take forever back to back don't overflow.
+	e := model.NewInterval(model.NewIntVar(0, 5, "es"), model.NewIntVar(0, math.MaxInt64, "ee"),
+		model.NewIntVar(0, math.MaxInt64, "ed"), "e")
+	min, max = InferHorizon([]Interval{a, e, e})
+	require.Equal(t, int64(0), min)
+	require.Equal(t, int64(math.MaxInt64), max)
+}
+
+func TestSetHorizon(t *testing.T) {
+	model := NewModel("")
+	start := model.NewIntVar(0, math.MaxInt64, "start")
+	end := model.NewIntVarFromDomain(NewDomain(-10, 0, 5, math.MaxInt64), "end")
+	model.NewInterval(start, end, model.NewConstant(5, "size"), "i")
+	model.NewFixedInterval(2, 4, "fixed")
+
+	model.SetHorizon(0, 20)
+	require.Equal(t, []int64{0, 20}, model.pb.GetVariables()[start.index()].GetDomain())
+	require.Equal(t, []int64{0, 0, 5, 20}, model.pb.GetVariables()[end.index()].GetDomain())
+	lb, ub := Bounds(start)
+	require.Equal(t, []int64{0, 20}, []int64{lb, ub})
+	require.Equal(t, "end in [0, 0] ∪ [5, 20]", end.String())
+
+	model = NewModel("", WithConstructionErrors())
+	model.NewFixedInterval(2, 7, "fixed")
+	model.SetHorizon(5, 20)
+	require.EqualError(t, model.Err(), "horizon [5, 20]: excludes the domain of fixed.start")
+
+	// Variables the model doesn't hold onto are tightened too, as are the
+	// literals negated starts or ends negate.
+	model = NewModel("", WithoutIntrospection())
+	start = model.NewIntVar(-5, 100, "start")
+	l := model.NewLiteral("l")
+	model.NewInterval(start, l.Not(), model.NewIntVar(-100, 100, "size"), "i")
+	model.SetHorizon(1, 20)
+	lb, ub = Bounds(start)
+	require.Equal(t, []int64{1, 20}, []int64{lb, ub})
+	require.Equal(t, []int64{0, 0}, model.pb.GetVariables()[l.index()].GetDomain())
+	lb, ub = Bounds(l)
+	require.Equal(t, []int64{0, 0}, []int64{lb, ub})
+}
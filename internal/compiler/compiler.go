@@ -0,0 +1,392 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package compiler applies statements of the language used to describe models
+// (see package modellang) to the models they describe. It's shared by
+// modellang and this repo's datadriven tests.
+package compiler
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/irfansharif/solver"
+	"github.com/irfansharif/solver/internal/parser/ast"
+)
+
+// Compiler applies statements to the model being compiled. Alongside the model
+// itself, it captures the variables declared by the statements, keyed by name.
+type Compiler struct {
+	Model *solver.Model
+
+	Vars      map[string]solver.IntVar // variables and constants
+	Literals  map[string]solver.Literal
+	Intervals map[string]solver.Interval
+}
+
+// New returns a compiler applying statements to the given model.
+func New(model *solver.Model) *Compiler {
+	return &Compiler{
+		Model:     model,
+		Vars:      make(map[string]solver.IntVar),
+		Literals:  make(map[string]solver.Literal),
+		Intervals: make(map[string]solver.Interval),
+	}
+}
+
+// Compile applies the given statement to the model. Statements that don't
+// describe the model (printing or solving it, inspecting results) are rejected;
+// it's up to callers to handle them. Constraint constructors panic on invalid
+// input; these panics are returned as errors.
+func (c *Compiler) Compile(stmt *ast.Statement) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			}
+			err = fmt.Errorf("%s.%s: %v", stmt.Receiver, stmt.Method, r)
+		}
+	}()
+
+	if stmt.Receiver == "result" {
+		return fmt.Errorf("%s.%s: results can't be inspected in model descriptions", stmt.Receiver, stmt.Method)
+	}
+
+	m := c.Model
+	switch stmt.Method {
+	case ast.VarsMethod: // model.vars(x, y, z in [0, 2])
+		argument := stmt.Argument.(*ast.DomainArgument)
+		if len(argument.Variables) == 0 {
+			return fmt.Errorf("%s.%s: expected variables, got linear expressions", stmt.Receiver, stmt.Method)
+		}
+		dom := domainOf(argument.Domains...)
+		for _, v := range argument.Variables {
+			c.declare(v)
+			c.Vars[ast.Name(v)] = m.NewIntVarFromDomain(dom, ast.Name(v))
+		}
+	case ast.LiteralsMethod: // model.literals(c, d)
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		for _, l := range argument.Variables {
+			c.declare(l)
+			c.Literals[ast.Name(l)] = m.NewLiteral(ast.Name(l))
+		}
+	case ast.ConstantsMethod: // model.constants(a, b == 42)
+		argument := stmt.Argument.(*ast.ConstantsArgument)
+		for _, v := range argument.Variables {
+			c.declare(v)
+			c.Vars[ast.Name(v)] = m.NewConstant(int64(argument.Constant), ast.Name(v))
+		}
+	case ast.IntervalsMethod: // model.intervals(i as [s, e | 0..4]) if a
+		var enforcement []solver.Literal
+		if stmt.Enforcement != nil {
+			enforcement = c.literals(stmt.Enforcement.Literals...)
+		}
+
+		argument := stmt.Argument.(*ast.IntervalsArgument)
+		for _, iv := range argument.Intervals {
+			c.declare(iv.Name)
+			variables, domains := iv.InlineVariables()
+			for i, v := range variables {
+				c.declare(v)
+				c.Vars[ast.Name(v)] = m.NewIntVarFromDomain(domainOf(domains[i]), ast.Name(v))
+			}
+			vars := c.intVars(iv.Start, iv.End, iv.Size)
+			interval := m.NewInterval(vars[0], vars[1], vars[2], ast.Name(iv.Name))
+			interval.OnlyEnforceIf(enforcement...)
+			c.Intervals[ast.Name(iv.Name)] = interval
+		}
+	case ast.MinimizeMethod, ast.MaximizeMethod: // model.minimize(x + 2y)
+		argument := stmt.Argument.(*ast.LinearExprsArgument)
+		if len(argument.Exprs) != 1 {
+			return fmt.Errorf("%s.%s: expected a single linear expression", stmt.Receiver, stmt.Method)
+		}
+		if stmt.Method == ast.MinimizeMethod {
+			m.Minimize(c.linearExpr(argument.Exprs[0]))
+		} else {
+			m.Maximize(c.linearExpr(argument.Exprs[0]))
+		}
+	case ast.PrintMethod, ast.SolveMethod, ast.SolveAllMethod, ast.ValidateMethod:
+		return fmt.Errorf("%s.%s: unsupported in model descriptions", stmt.Receiver, stmt.Method)
+	case ast.LinearExprsMethod: // constrain.linear-exprs(x + y, z in [0, 4]) if a
+		m.AddConstraints(c.linearConstraints(stmt)...)
+	default:
+		constraint, err := c.constraint(stmt)
+		if err != nil {
+			return err
+		}
+		m.AddConstraints(constraint)
+	}
+	return nil
+}
+
+// constraint constructs the constraint described by the given statement.
+func (c *Compiler) constraint(stmt *ast.Statement) (solver.Constraint, error) {
+	var enforcement []solver.Literal
+	if stmt.Enforcement != nil {
+		enforcement = c.literals(stmt.Enforcement.Literals...)
+	}
+
+	switch stmt.Method {
+	case ast.AllDifferentMethod: // constrain.all-different(x, y, z)
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewAllDifferentConstraint(c.intVars(argument.Variables...)...), nil
+	case ast.AllSameMethod: // constrain.all-same(x, y, z)
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewAllSameConstraint(c.intVars(argument.Variables...)...), nil
+	case ast.AssignmentsMethod: // constrain.assignments([a, b] ∈ [0, 1] ∪ [1, 0])
+		argument := stmt.Argument.(*ast.AssignmentsArgument)
+		if argument.ForLiterals() {
+			literals := c.literals(argument.Variables...)
+			if argument.In {
+				return solver.NewAllowedLiteralAssignmentsConstraint(literals, argument.AllowedLiteralAssignments), nil
+			}
+			return solver.NewForbiddenLiteralAssignmentsConstraint(literals, argument.AllowedLiteralAssignments), nil
+		}
+		vars := c.intVars(argument.Variables...)
+		if argument.In {
+			return solver.NewAllowedAssignmentsConstraint(vars, argument.AsInt64s()), nil
+		}
+		return solver.NewForbiddenAssignmentsConstraint(vars, argument.AsInt64s()), nil
+	case ast.AtLeastKMethod: // constrain.at-least-k(a to c | 2) if d
+		argument := stmt.Argument.(*ast.KArgument)
+		return solver.NewAtLeastKConstraint(argument.K, c.literals(argument.Literals...)...).OnlyEnforceIf(enforcement...), nil
+	case ast.AtMostKMethod: // constrain.at-most-k(a to c | 2) if d
+		argument := stmt.Argument.(*ast.KArgument)
+		return solver.NewAtMostKConstraint(argument.K, c.literals(argument.Literals...)...).OnlyEnforceIf(enforcement...), nil
+	case ast.ExactlyKMethod: // constrain.exactly-k(a to c | 2) if d
+		argument := stmt.Argument.(*ast.KArgument)
+		return solver.NewExactlyKConstraint(argument.K, c.literals(argument.Literals...)...).OnlyEnforceIf(enforcement...), nil
+	case ast.BinaryOpMethod: // constrain.binary-op(a % 7 == c)
+		argument := stmt.Argument.(*ast.BinaryOpArgument)
+		names, values := argument.Constants()
+		for i, name := range names {
+			c.constant(name, values[i])
+		}
+		vars := c.intVars(argument.Left, argument.Right, argument.Target)
+		left, right, target := vars[0], vars[1], vars[2]
+		switch argument.Op {
+		case "%":
+			return solver.NewModuloConstraint(target, left, right), nil
+		case "/":
+			return solver.NewDivisionConstraint(target, left, right), nil
+		default:
+			return solver.NewProductConstraint(target, left, right), nil
+		}
+	case ast.BooleanAndMethod: // constrain.boolean-and(a, b) if c
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewBooleanAndConstraint(c.literals(argument.Variables...)...).OnlyEnforceIf(enforcement...), nil
+	case ast.BooleanOrMethod: // constrain.boolean-or(a, b) if c
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewBooleanOrConstraint(c.literals(argument.Variables...)...).OnlyEnforceIf(enforcement...), nil
+	case ast.BooleanXorMethod: // constrain.boolean-xor(a, b)
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewBooleanXorConstraint(c.literals(argument.Variables...)...), nil
+	case ast.CumulativeMethod: // constrain.cumulative(i: x, j: y | z)
+		argument := stmt.Argument.(*ast.CumulativeArgument)
+		return solver.NewCumulativeConstraint(
+			c.intVars(argument.Capacity)[0],
+			c.intervals(argument.Intervals()...),
+			c.intVars(argument.Demands()...),
+		), nil
+	case ast.ElementMethod: // constrain.element(t == [x, y, z][i])
+		argument := stmt.Argument.(*ast.ElementArgument)
+		vars := c.intVars(argument.Target, argument.Index)
+		return solver.NewElementConstraint(vars[0], vars[1], c.intVars(argument.Variables...)...), nil
+	case ast.EqualityMethod:
+		switch argument := stmt.Argument.(type) {
+		case *ast.VariableEqualityArgument: // constrain.equality(t == max(x, y))
+			target := c.intVars(argument.Target)[0]
+			vars := c.intVars(argument.Variables...)
+			if argument.Op == "max" {
+				return solver.NewMaximumConstraint(target, vars...), nil
+			}
+			return solver.NewMinimumConstraint(target, vars...), nil
+		case *ast.LinearEqualityArgument: // constrain.equality(t == min(x + 1, 2y))
+			target := c.linearExpr(argument.Target)
+			var exprs []solver.LinearExpr
+			for _, e := range argument.Exprs {
+				exprs = append(exprs, c.linearExpr(e))
+			}
+			if argument.Op == "max" {
+				return solver.NewLinearMaximumConstraint(target, exprs...), nil
+			}
+			return solver.NewLinearMinimumConstraint(target, exprs...), nil
+		}
+	case ast.ImplicationMethod: // constrain.implication(a → b)
+		argument := stmt.Argument.(*ast.ImplicationArgument)
+		literals := c.literals(argument.Left, argument.Right)
+		return solver.NewImplicationConstraint(literals[0], literals[1]), nil
+	case ast.MinGapMethod: // constrain.minimum-gap(i, j | 2)
+		argument := stmt.Argument.(*ast.KArgument)
+		return c.Model.NewMinGapConstraint(c.intervals(argument.Literals...), int64(argument.K)), nil
+	case ast.NonOverlappingMethod: // constrain.non-overlapping(i, j)
+		argument := stmt.Argument.(*ast.VariablesArgument)
+		return solver.NewNonOverlappingConstraint(c.intervals(argument.Variables...)...), nil
+	case ast.NonOverlapping2DMethod: // constrain.non-overlapping-2D([i, j], [k, l], false)
+		argument := stmt.Argument.(*ast.NonOverlapping2DArgument)
+		return solver.NewNonOverlapping2DConstraint(
+			c.intervals(argument.XVariables...),
+			c.intervals(argument.YVariables...),
+			argument.BoxesWithNoAreaCanOverlap,
+		), nil
+	}
+	return nil, fmt.Errorf("%s.%s: unsupported in model descriptions", stmt.Receiver, stmt.Method)
+}
+
+// linearConstraints constructs the linear constraints described by the given
+// statement, one for each of the expressions listed.
+func (c *Compiler) linearConstraints(stmt *ast.Statement) []solver.Constraint {
+	var enforcement []solver.Literal
+	if stmt.Enforcement != nil {
+		enforcement = c.literals(stmt.Enforcement.Literals...)
+	}
+
+	argument := stmt.Argument.(*ast.DomainArgument)
+	exprs := argument.LinearExprs
+	for _, v := range argument.Variables {
+		exprs = append(exprs, &ast.LinearExpr{
+			LinearTerms: []*ast.LinearTerm{{Coefficient: 1, Variable: v}},
+		})
+	}
+
+	var constraints []solver.Constraint
+	for _, e := range exprs {
+		constraints = append(constraints, solver.NewLinearConstraint(
+			c.linearExpr(e), domainOf(argument.Domains...),
+		).OnlyEnforceIf(enforcement...))
+	}
+	return constraints
+}
+
+// unknownIdentifierError is raised (as a panic, recovered in Compile) when
+// referring to an undeclared identifier, so that lookups compose.
+type unknownIdentifierError struct {
+	kind, name string
+}
+
+func (e *unknownIdentifierError) Error() string {
+	return fmt.Sprintf("unrecognized %s: %s", e.kind, e.name)
+}
+
+// declare checks that the given identifier isn't already in use.
+func (c *Compiler) declare(identifier string) {
+	name := ast.Name(identifier)
+	_, isVar := c.Vars[name]
+	_, isLiteral := c.Literals[name]
+	_, isInterval := c.Intervals[name]
+	if isVar || isLiteral || isInterval {
+		panic(fmt.Errorf("%s already declared", identifier))
+	}
+}
+
+// constant interns the constant with the given name and value, for integer
+// literals used in place of variables.
+func (c *Compiler) constant(name string, value int) {
+	if _, ok := c.Vars[name]; ok {
+		return
+	}
+	c.Vars[name] = c.Model.NewConstant(int64(value), name)
+}
+
+// intVars looks up the given variables. Literals are valid integer variables.
+func (c *Compiler) intVars(identifiers ...string) []solver.IntVar {
+	var vars []solver.IntVar
+	for _, identifier := range identifiers {
+		name := ast.Name(identifier)
+		if iv, ok := c.Vars[name]; ok {
+			vars = append(vars, iv)
+		} else if l, ok := c.Literals[name]; ok {
+			vars = append(vars, l)
+		} else {
+			panic(&unknownIdentifierError{kind: "variable", name: identifier})
+		}
+	}
+	return vars
+}
+
+// literals looks up the given literals.
+func (c *Compiler) literals(identifiers ...string) []solver.Literal {
+	var literals []solver.Literal
+	for _, identifier := range identifiers {
+		name := ast.Name(identifier)
+		l, ok := c.Literals[name]
+		if !ok {
+			panic(&unknownIdentifierError{kind: "literal", name: identifier})
+		}
+		literals = append(literals, l)
+	}
+	return literals
+}
+
+// IntVar looks up the given variable. Literals are valid integer variables.
+func (c *Compiler) IntVar(identifier string) (iv solver.IntVar, err error) {
+	defer c.recover(&err)
+	return c.intVars(identifier)[0], nil
+}
+
+// Literal looks up the given literal.
+func (c *Compiler) Literal(identifier string) (l solver.Literal, err error) {
+	defer c.recover(&err)
+	return c.literals(identifier)[0], nil
+}
+
+// recover recovers from unknown identifier panics, returning them as errors.
+func (c *Compiler) recover(err *error) {
+	if r := recover(); r != nil {
+		e, ok := r.(*unknownIdentifierError)
+		if !ok {
+			panic(r)
+		}
+		*err = e
+	}
+}
+
+// intervals looks up the given intervals.
+func (c *Compiler) intervals(identifiers ...string) []solver.Interval {
+	var intervals []solver.Interval
+	for _, identifier := range identifiers {
+		name := ast.Name(identifier)
+		iv, ok := c.Intervals[name]
+		if !ok {
+			panic(&unknownIdentifierError{kind: "interval", name: identifier})
+		}
+		intervals = append(intervals, iv)
+	}
+	return intervals
+}
+
+// linearExpr constructs the given linear expression.
+func (c *Compiler) linearExpr(e *ast.LinearExpr) solver.LinearExpr {
+	var vars []solver.IntVar
+	var coeffs []int64
+	var offset int64
+	for _, term := range e.LinearTerms {
+		if term.Variable == "" {
+			offset += int64(term.Coefficient)
+			continue
+		}
+		vars = append(vars, c.intVars(term.Variable)[0])
+		coeffs = append(coeffs, int64(term.Coefficient))
+	}
+	return solver.NewLinearExpr(vars, coeffs, offset)
+}
+
+// domainOf returns the domain made up of the given unit domains.
+func domainOf(domains ...*ast.Domain) solver.Domain {
+	var ls []int64
+	for _, d := range domains {
+		ls = append(ls, int64(d.LowerBound), int64(d.UpperBound))
+	}
+	return solver.NewDomain(ls[0], ls[1], ls[2:]...)
+}
@@ -16,9 +16,8 @@ package ast
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
-
-	"github.com/irfansharif/solver"
 )
 
 // Argument represents a statement argument (see Statement).
@@ -35,6 +34,7 @@ import (
 //            | LinearEqualityArgument
 //            | LinearExprsArgument
 //            | NonOverlapping2DArgument
+//            | RelationsArgument
 //            | VariableEqualityArgument
 //            | VariablesArgument .
 type Argument interface {
@@ -120,10 +120,12 @@ func (a *AssignmentsArgument) AsInt64s() [][]int64 {
 	return assignments
 }
 
-// BinaryOpArgument represents a binary operation argument: a * b == c.
+// BinaryOpArgument represents a binary operation argument: a * b == c. Operands
+// can also be integer literals: a % 7 == b.
 // It's used to test New{Product,Division,Modulo}Constraint.
 //
-//   BinaryOpArgument = Identifier ( "/" | "%" | "*" ) Identifier "==" Identifier .
+//   BinaryOpArgument = Operand ( "/" | "%" | "*" ) Operand "==" Operand .
+//   Operand          = Identifier | Number .
 type BinaryOpArgument struct {
 	Left, Right, Op, Target string
 }
@@ -132,6 +134,18 @@ func (b *BinaryOpArgument) String() string {
 	return fmt.Sprintf("%s %s %s == %s", b.Left, b.Op, b.Right, b.Target)
 }
 
+// Constants returns the operands that are integer literals, alongside their
+// values. The operands double as names for the corresponding constants.
+func (b *BinaryOpArgument) Constants() (names []string, values []int) {
+	for _, operand := range []string{b.Left, b.Right, b.Target} {
+		if v, err := strconv.Atoi(operand); err == nil {
+			names = append(names, operand)
+			values = append(values, v)
+		}
+	}
+	return names, values
+}
+
 // ConstantsArgument represents a constants argument: a, b to c == 42.
 // It's used to test NewConstant.
 //
@@ -145,6 +159,27 @@ func (c *ConstantsArgument) String() string {
 	return fmt.Sprintf("%s == %d", strings.Join(c.Variables, ", "), c.Constant)
 }
 
+// AsRelationsArgument returns a RelationsArgument representation of the
+// ConstantsArgument, which is only possible if it refers to a single variable.
+func (c *ConstantsArgument) AsRelationsArgument() (*RelationsArgument, bool) {
+	if len(c.Variables) != 1 {
+		return nil, false
+	}
+	return &RelationsArgument{
+		Relations: []*Relation{
+			{
+				Left: &LinearExpr{
+					LinearTerms: []*LinearTerm{{Coefficient: 1, Variable: c.Variables[0]}},
+				},
+				Right: &LinearExpr{
+					LinearTerms: []*LinearTerm{{Coefficient: c.Constant}},
+				},
+				Op: "==",
+			},
+		},
+	}, true
+}
+
 // CumulativeArgument represents a cumulative argument: i:2, j:4 | C.
 // It's used to test NewCumulativeConstraint.
 //
@@ -212,14 +247,6 @@ func (d *DomainArgument) String() string {
 	return fmt.Sprintf("%s in %s", terms, domains)
 }
 
-func (d *DomainArgument) AsSolverDomain() solver.Domain {
-	var ls []int64
-	for _, domain := range d.Domains {
-		ls = append(ls, int64(domain.LowerBound), int64(domain.UpperBound))
-	}
-	return solver.NewDomain(ls[0], ls[1], ls[2:]...)
-}
-
 // ElementArgument represents an element argument: t == [a,b,c][i].
 // It's used to test NewElementConstraint.
 //
@@ -324,6 +351,23 @@ func (n *NonOverlapping2DArgument) String() string {
 
 //   VariableEqualityArgument = Identifier "==" ( "max" | "min" ) "(" Variables ")" .
 
+// RelationsArgument represents an argument comprised of relations between
+// linear expressions: x < y, a + b == 10.
+// It's used to assert on results.
+//
+//   RelationsArgument = Relations .
+type RelationsArgument struct {
+	Relations []*Relation
+}
+
+func (r *RelationsArgument) String() string {
+	var strs []string
+	for _, relation := range r.Relations {
+		strs = append(strs, relation.String())
+	}
+	return strings.Join(strs, ", ")
+}
+
 // VariableEqualityArgument represents a variable equality argument: j == min(k, i, f).
 // It's used to test New{Minimum,Maximum}Constraint.
 //
@@ -375,6 +419,7 @@ var _ Argument = &KArgument{}
 var _ Argument = &LinearEqualityArgument{}
 var _ Argument = &LinearExprsArgument{}
 var _ Argument = &NonOverlapping2DArgument{}
+var _ Argument = &RelationsArgument{}
 var _ Argument = &VariableEqualityArgument{}
 var _ Argument = &VariablesArgument{}
 
@@ -390,5 +435,6 @@ func (*KArgument) argument()                {}
 func (*LinearEqualityArgument) argument()   {}
 func (*LinearExprsArgument) argument()      {}
 func (*NonOverlapping2DArgument) argument() {}
+func (*RelationsArgument) argument()        {}
 func (*VariableEqualityArgument) argument() {}
 func (*VariablesArgument) argument()        {}
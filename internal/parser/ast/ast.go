@@ -20,6 +20,20 @@ import (
 	"strings"
 )
 
+// Name returns the name the given identifier refers to. Identifiers are either
+// words, which are names as is, or quoted strings (see Parser.Quoted), which
+// are unquoted.
+func Name(identifier string) string {
+	if !strings.HasPrefix(identifier, `"`) {
+		return identifier
+	}
+	name, err := strconv.Unquote(identifier)
+	if err != nil {
+		return identifier
+	}
+	return name
+}
+
 // Statement represents a single statement.
 //
 //   Statement   = Receiver "." Method "(" [ Argument ] ")" [ Enforcement ] .
@@ -52,15 +66,49 @@ func (e *Enforcement) String() string {
 	return fmt.Sprintf("if %s", strings.Join(e.Literals, ", "))
 }
 
-// Interval represents a single interval.
+// Interval represents a single interval. Its start, end and size variables can
+// be declared inline using ranges (i as [0..10, 0..10 | 2..4]), in which case
+// they're named after the interval (i.start, i.end, i.size).
 //
-//   Interval       = Identifier "as" "[" Identifier "," Identifier "|" Identifier "]" .
+//   Interval          = Identifier "as" "[" IntervalComponent "," IntervalComponent "|" IntervalComponent "]" .
+//   IntervalComponent = Identifier | Range .
 type Interval struct {
 	Name, Start, End, Size string // variables
+
+	// Domains for the variables declared inline, nil otherwise.
+	StartDomain, EndDomain, SizeDomain *Domain
 }
 
 func (i *Interval) String() string {
-	return fmt.Sprintf("%s as [%s, %s | %s]", i.Name, i.Start, i.End, i.Size)
+	component := func(variable string, domain *Domain) string {
+		if domain != nil {
+			return fmt.Sprintf("%d..%d", domain.LowerBound, domain.UpperBound)
+		}
+		return variable
+	}
+	return fmt.Sprintf("%s as [%s, %s | %s]", i.Name,
+		component(i.Start, i.StartDomain),
+		component(i.End, i.EndDomain),
+		component(i.Size, i.SizeDomain))
+}
+
+// InlineVariables returns the variables declared inline, alongside their
+// domains.
+func (i *Interval) InlineVariables() (variables []string, domains []*Domain) {
+	for _, c := range []struct {
+		variable string
+		domain   *Domain
+	}{
+		{i.Start, i.StartDomain},
+		{i.End, i.EndDomain},
+		{i.Size, i.SizeDomain},
+	} {
+		if c.domain != nil {
+			variables = append(variables, c.variable)
+			domains = append(domains, c.domain)
+		}
+	}
+	return variables, domains
 }
 
 // Domain represents a unit domain.
@@ -85,7 +133,7 @@ type LinearTerm struct {
 }
 
 func (l *LinearTerm) String() string {
-	if l.Coefficient == 1 {
+	if l.Coefficient == 1 && l.Variable != "" {
 		return fmt.Sprintf("%s", l.Variable)
 	}
 	return fmt.Sprintf("%d%s", l.Coefficient, l.Variable)
@@ -114,7 +162,7 @@ func (l *LinearExpr) String() string {
 			}
 		}
 
-		if term.Coefficient != 1 && term.Coefficient != -1 {
+		if (term.Coefficient != 1 && term.Coefficient != -1) || term.Variable == "" {
 			abs := int64(term.Coefficient)
 			if term.Coefficient < 0 {
 				abs = -abs
@@ -127,6 +175,54 @@ func (l *LinearExpr) String() string {
 	return b.String()
 }
 
+// Eval evaluates the linear expression, using the given function to look up
+// variable values.
+func (l *LinearExpr) Eval(value func(variable string) int64) int64 {
+	var sum int64
+	for _, term := range l.LinearTerms {
+		if term.Variable == "" {
+			sum += int64(term.Coefficient)
+			continue
+		}
+		sum += int64(term.Coefficient) * value(term.Variable)
+	}
+	return sum
+}
+
+// Relation represents a relation between two linear expressions.
+//
+//   Relation       = LinearExpr ( "<" | "<=" | ">" | ">=" | "==" | "!=" ) LinearExpr .
+type Relation struct {
+	Left, Right *LinearExpr
+	Op          string
+}
+
+func (r *Relation) String() string {
+	return fmt.Sprintf("%s %s %s", r.Left, r.Op, r.Right)
+}
+
+// Holds returns true iff the relation holds, using the given function to look
+// up variable values.
+func (r *Relation) Holds(value func(variable string) int64) bool {
+	left, right := r.Left.Eval(value), r.Right.Eval(value)
+	switch r.Op {
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	default:
+		panic(fmt.Sprintf("unrecognized relation: %s", r.Op))
+	}
+}
+
 // IntervalDemand represents an interval identifier and it's corresponding
 // demand.
 //
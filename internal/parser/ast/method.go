@@ -21,6 +21,7 @@ const (
 
 	AllDifferentMethod Method = iota + 128
 	AllSameMethod
+	AssertMethod
 	AssignmentsMethod
 	AtLeastKMethod
 	AtMostKMethod
@@ -39,6 +40,7 @@ const (
 	LinearExprsMethod
 	LiteralsMethod
 	MaximizeMethod
+	MinGapMethod
 	MinimizeMethod
 	NameMethod
 	NonOverlappingMethod
@@ -55,6 +57,7 @@ const (
 var methods = map[Method]string{
 	AllDifferentMethod:     "all-different",
 	AllSameMethod:          "all-same",
+	AssertMethod:           "assert",
 	AssignmentsMethod:      "assignments",
 	AtLeastKMethod:         "at-least-k",
 	AtMostKMethod:          "at-most-k",
@@ -73,6 +76,7 @@ var methods = map[Method]string{
 	LinearExprsMethod:      "linear-exprs",
 	LiteralsMethod:         "literals",
 	MaximizeMethod:         "maximize",
+	MinGapMethod:           "minimum-gap",
 	MinimizeMethod:         "minimize",
 	NameMethod:             "name",
 	NonOverlappingMethod:   "non-overlapping",
@@ -0,0 +1,194 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+
+	"github.com/irfansharif/solver/internal/parser/ast"
+)
+
+// Compile compiles the given statement and returns the corresponding AST node.
+// It returns an error if the statement is malformed, or if it doesn't
+// type-check.
+func Compile(input string) (*ast.Statement, error) {
+	p := New(input)
+	stmt := p.Statement()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	// TODO(irfansharif): Should we make a single receiver+method type? There
+	// are only three receivers, and a static list of methods.
+	switch stmt.Receiver {
+	case "model":
+		switch stmt.Method {
+		case ast.ConstantsMethod, ast.IntervalsMethod, ast.LiteralsMethod,
+			ast.MaximizeMethod, ast.MinimizeMethod, ast.NameMethod,
+			ast.PrintMethod, ast.SolveMethod, ast.SolveAllMethod,
+			ast.ValidateMethod, ast.VarsMethod:
+		default:
+			return nil, fmt.Errorf("unrecognized method: %s.%s", stmt.Receiver, stmt.Method)
+		}
+	case "constrain":
+		switch stmt.Method {
+		case ast.AllDifferentMethod, ast.AllSameMethod, ast.AssignmentsMethod,
+			ast.AtLeastKMethod, ast.AtMostKMethod, ast.BinaryOpMethod,
+			ast.BooleanAndMethod, ast.BooleanOrMethod, ast.BooleanXorMethod,
+			ast.CumulativeMethod, ast.ElementMethod, ast.EqualityMethod,
+			ast.ExactlyKMethod, ast.ImplicationMethod, ast.LinearExprsMethod,
+			ast.MinGapMethod, ast.NonOverlappingMethod, ast.NonOverlapping2DMethod:
+		default:
+			return nil, fmt.Errorf("unrecognized method: %s.%s", stmt.Receiver, stmt.Method)
+		}
+	case "result":
+		switch stmt.Method {
+		case ast.AssertMethod, ast.BoolsMethod, ast.ObjectiveValueMethod, ast.ValuesMethod:
+		default:
+			return nil, fmt.Errorf("unrecognized method: %s.%s", stmt.Receiver, stmt.Method)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized receiver: %s", stmt.Receiver)
+	}
+
+	if stmt.Enforcement != nil {
+		switch stmt.Method {
+		case ast.BooleanOrMethod, ast.BooleanAndMethod, ast.LinearExprsMethod,
+			ast.AtLeastKMethod, ast.AtMostKMethod, ast.ExactlyKMethod:
+		case ast.IntervalsMethod:
+			if len(stmt.Enforcement.Literals) > 1 {
+				return nil, fmt.Errorf("only single enforcement literal supported for %s.%s", stmt.Receiver, stmt.Method)
+			}
+		default:
+			return nil, fmt.Errorf("enforcement clause unsupported for %s.%s", stmt.Receiver, stmt.Method)
+		}
+	}
+
+	if stmt.Argument != nil {
+		switch t := stmt.Argument.(type) {
+		case *ast.AssignmentsArgument:
+
+			switch stmt.Method {
+			case ast.AssignmentsMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.BinaryOpArgument:
+			switch stmt.Method {
+			case ast.BinaryOpMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.ConstantsArgument:
+			switch stmt.Method {
+			case ast.ConstantsMethod:
+			case ast.AssertMethod:
+				// There's ambiguity in the grammar (x == 42), and we give
+				// precedence to ConstantsArgument during parsing. Let's fix up
+				// here.
+				argument, ok := t.AsRelationsArgument()
+				if !ok {
+					return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+				}
+				stmt.Argument = argument
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.CumulativeArgument:
+			switch stmt.Method {
+			case ast.CumulativeMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.DomainArgument:
+			switch stmt.Method {
+			case ast.VarsMethod, ast.LinearExprsMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.ElementArgument:
+			switch stmt.Method {
+			case ast.ElementMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.ImplicationArgument:
+			switch stmt.Method {
+			case ast.ImplicationMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.IntervalsArgument:
+			switch stmt.Method {
+			case ast.IntervalsMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.KArgument:
+			switch stmt.Method {
+			case ast.AtMostKMethod, ast.AtLeastKMethod, ast.ExactlyKMethod, ast.MinGapMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.LinearEqualityArgument:
+			switch stmt.Method {
+			case ast.EqualityMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.LinearExprsArgument:
+			switch stmt.Method {
+			case ast.MaximizeMethod, ast.MinimizeMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.NonOverlapping2DArgument:
+			switch stmt.Method {
+			case ast.NonOverlapping2DMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.RelationsArgument:
+			switch stmt.Method {
+			case ast.AssertMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.VariableEqualityArgument:
+			switch stmt.Method {
+			case ast.EqualityMethod:
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		case *ast.VariablesArgument:
+			switch stmt.Method {
+			case ast.AllDifferentMethod, ast.AllSameMethod,
+				ast.BooleanAndMethod, ast.BooleanOrMethod, ast.BooleanXorMethod,
+				ast.BoolsMethod, ast.LiteralsMethod, ast.NameMethod,
+				ast.NonOverlappingMethod, ast.ValuesMethod:
+			case ast.MaximizeMethod, ast.MinimizeMethod:
+				// There's ambiguity in the grammar, and we give precedence to
+				// VariablesArgument during parsing. Let's fix up here.
+				stmt.Argument = t.AsLinearExprsArgument()
+			default:
+				return nil, fmt.Errorf("unexpected type for %s.%s: %T", stmt.Receiver, stmt.Method, t)
+			}
+		default:
+			return nil, fmt.Errorf("unrecognized type: %T", t)
+		}
+	}
+
+	return stmt, nil
+}
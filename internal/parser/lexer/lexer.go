@@ -15,7 +15,9 @@
 package lexer
 
 import (
-	"github.com/irfansharif/solver/internal/testutils/parser/token"
+	"strconv"
+
+	"github.com/irfansharif/solver/internal/parser/token"
 )
 
 const eof = rune(0)
@@ -64,9 +66,19 @@ func (l *Lexer) Next() token.Token {
 	case '%':
 		t = tok(token.MOD, r)
 	case '<':
-		t = tok(token.LT, r)
+		if l.peek() == '=' {
+			l.move() // move the cursor to the end of the token
+			t = token.Token{Type: token.LE, Value: "<="}
+		} else {
+			t = tok(token.LT, r)
+		}
 	case '>':
-		t = tok(token.GT, r)
+		if l.peek() == '=' {
+			l.move() // move the cursor to the end of the token
+			t = token.Token{Type: token.GE, Value: ">="}
+		} else {
+			t = tok(token.GT, r)
+		}
 	case '∈':
 		t = tok(token.EXISTS, r)
 	case '∉':
@@ -74,7 +86,12 @@ func (l *Lexer) Next() token.Token {
 	case '∪':
 		t = tok(token.UNION, r)
 	case '.':
-		t = tok(token.DOT, r)
+		if l.peek() == '.' {
+			l.move() // move the cursor to the end of the token
+			t = token.Token{Type: token.RANGE, Value: ".."}
+		} else {
+			t = tok(token.DOT, r)
+		}
 	case ':':
 		t = tok(token.COLON, r)
 	case ',':
@@ -98,6 +115,12 @@ func (l *Lexer) Next() token.Token {
 		} else {
 			t = tok(token.ILLEGAL, r)
 		}
+	case '"':
+		if quoted, ok := l.quoted(); ok {
+			t = token.Token{Type: token.QUOTED, Value: quoted}
+		} else {
+			t = tok(token.ILLEGAL, r)
+		}
 	case '!':
 		if l.peek() == '=' {
 			l.move() // move the cursor to the end of the token
@@ -187,6 +210,37 @@ func (l *Lexer) digits() string {
 	return string(l.input[start : l.idx+1])
 }
 
+// quoted lexes a double-quoted string, moving the cursor to the closing quote.
+// The string is unquoted using Go's rules for (interpreted) string literals,
+// so quotes and backslashes within it are escaped using a backslash. If the
+// string is malformed or isn't terminated, false is returned.
+func (l *Lexer) quoted() (string, bool) {
+	start := l.idx
+	for {
+		r := l.peek()
+		if r == eof {
+			return "", false
+		}
+		l.move()
+		if r == '\\' {
+			if l.peek() == eof {
+				return "", false
+			}
+			l.move() // skip over the escaped rune
+			continue
+		}
+		if r == '"' {
+			break
+		}
+	}
+
+	unquoted, err := strconv.Unquote(string(l.input[start : l.idx+1]))
+	if err != nil {
+		return "", false
+	}
+	return unquoted, true
+}
+
 // isWhiteSpace returns true if the rune is a whitespace.
 func isWhitespace(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
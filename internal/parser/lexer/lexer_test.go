@@ -20,16 +20,16 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/datadriven"
+	"github.com/irfansharif/solver/internal/parser/lexer"
+	"github.com/irfansharif/solver/internal/parser/token"
 	"github.com/irfansharif/solver/internal/testutils/bazel"
-	"github.com/irfansharif/solver/internal/testutils/parser/lexer"
-	"github.com/irfansharif/solver/internal/testutils/parser/token"
 )
 
 func TestDatadriven(t *testing.T) {
 	datadriven.Walk(t, "testdata", func(t *testing.T, path string) {
 		if bazel.BuiltWithBazel() {
 			var implant func()
-			path, implant = bazel.WritableSandboxPathFor(t, "internal/testutils/parser/lexer", path)
+			path, implant = bazel.WritableSandboxPathFor(t, "internal/parser/lexer", path)
 			defer implant()
 		}
 
@@ -20,12 +20,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"testing"
 
-	"github.com/irfansharif/solver/internal/testutils/parser/ast"
-	"github.com/irfansharif/solver/internal/testutils/parser/lexer"
-	"github.com/irfansharif/solver/internal/testutils/parser/token"
-	"github.com/stretchr/testify/require"
+	"github.com/irfansharif/solver/internal/parser/ast"
+	"github.com/irfansharif/solver/internal/parser/lexer"
+	"github.com/irfansharif/solver/internal/parser/token"
 )
 
 // Parser exposes a set of parsing primitives to process the datadriven tests.
@@ -33,14 +31,15 @@ type Parser struct {
 	lexer *lexer.Lexer
 	cur   token.Token
 
-	tb     testing.TB
-	trying bool // whether we're currently under a try closure
-	failed bool // whether the try closure has failed
+	err    error // the first error encountered, if any
+	trying bool  // whether we're currently under a try closure
+	failed bool  // whether the try closure has failed
 }
 
-// New initializes a new parser for the given input.
-func New(tb testing.TB, input string) *Parser {
-	p := &Parser{tb: tb, lexer: lexer.New(input)}
+// New initializes a new parser for the given input. Parsing errors are
+// retrievable using Err.
+func New(input string) *Parser {
+	p := &Parser{lexer: lexer.New(input)}
 	p.cur = p.lexer.Next() // stage the current token
 	return p
 }
@@ -52,7 +51,9 @@ func (p *Parser) Digits() int {
 	digits := p.cur.Value
 	p.eat(token.DIGITS)
 	n, err := strconv.Atoi(digits)
-	require.Nil(p, err)
+	if err != nil {
+		p.errorf("%v", err)
+	}
 	return n
 }
 
@@ -68,17 +69,37 @@ func (p *Parser) Boolean() bool {
 	boolean := p.cur.Value
 	p.eat(token.BOOL)
 	b, err := strconv.ParseBool(boolean)
-	require.Nil(p, err)
+	if err != nil {
+		p.errorf("%v", err)
+	}
 	return b
 }
 
 // ----------------------------------------------------------------- Base types.
 
-// Identifier = Word .
+// Identifier = Word | Quoted .
+//
+// Quoted identifiers are returned as is, quotes included, so they're printed
+// back out in a form that can be re-parsed; see ast.Name for the names they
+// refer to.
 func (p *Parser) Identifier() string {
+	if p.match(token.QUOTED) {
+		return p.Quoted()
+	}
 	return p.Word()
 }
 
+// Quoted = `"` { Char } `"` .
+//
+// Quoted strings follow Go's rules for string literals, so quotes and
+// backslashes within them are escaped using a backslash. They're returned in
+// their canonical, quoted form.
+func (p *Parser) Quoted() string {
+	quoted := strconv.Quote(p.cur.Value)
+	p.eat(token.QUOTED)
+	return quoted
+}
+
 // Number = [ "-" ] Digits .
 func (p *Parser) Number() int {
 	negative := p.match(token.MINUS)
@@ -104,6 +125,15 @@ func (p *Parser) Domain() *ast.Domain {
 	return domain
 }
 
+// Range = Number ".." Number .
+func (p *Parser) Range() *ast.Domain {
+	domain := &ast.Domain{}
+	domain.LowerBound = p.Number()
+	p.eat(token.RANGE)
+	domain.UpperBound = p.Number()
+	return domain
+}
+
 // Variable = Identifier | Letter "to" Letter .
 func (p *Parser) Variable() string {
 	first := p.Identifier()
@@ -112,25 +142,44 @@ func (p *Parser) Variable() string {
 	}
 	p.eat(token.TO)
 	second := p.Identifier()
-	require.Lenf(p, first, 1, "expected single letter, got %s", first)
-	require.Lenf(p, second, 1, "expected single letter, got %s", second)
+	if len(first) != 1 {
+		p.errorf("expected single letter, got %s", first)
+	}
+	if len(second) != 1 {
+		p.errorf("expected single letter, got %s", second)
+	}
 	return fmt.Sprintf("%s to %s", first, second)
 }
 
-// Interval = Identifier "as" "[" Identifier "," Identifier "|" Identifier "]" .
+// Interval = Identifier "as" "[" IntervalComponent "," IntervalComponent "|" IntervalComponent "]" .
 func (p *Parser) Interval() *ast.Interval {
 	interval := &ast.Interval{}
 	interval.Name = p.Identifier()
 	p.eat(token.AS, token.LBRACKET)
-	interval.Start = p.Identifier()
+	interval.Start, interval.StartDomain = p.intervalComponent(interval.Name, "start")
 	p.eat(token.COMMA)
-	interval.End = p.Identifier()
+	interval.End, interval.EndDomain = p.intervalComponent(interval.Name, "end")
 	p.eat(token.PIPE)
-	interval.Size = p.Identifier()
+	interval.Size, interval.SizeDomain = p.intervalComponent(interval.Name, "size")
 	p.eat(token.RBRACKET)
 	return interval
 }
 
+// IntervalComponent = Identifier | Range .
+//
+// Variables declared inline using a range are named after the interval
+// (<interval>.<component>).
+func (p *Parser) intervalComponent(interval, component string) (string, *ast.Domain) {
+	if p.match(token.WORD, token.QUOTED) {
+		return p.Identifier(), nil
+	}
+	name := fmt.Sprintf("%s.%s", ast.Name(interval), component)
+	if ast.Name(interval) != interval { // quoted
+		name = strconv.Quote(name)
+	}
+	return name, p.Range()
+}
+
 // LinearTerm = { Digits } Identifier | Digits .
 func (p *Parser) LinearTerm() *ast.LinearTerm {
 	term := &ast.LinearTerm{}
@@ -197,6 +246,32 @@ func (p *Parser) LinearExpr() *ast.LinearExpr {
 	return expr
 }
 
+// Relation = LinearExpr ( "<" | "<=" | ">" | ">=" | "==" | "!=" ) LinearExpr .
+func (p *Parser) Relation() *ast.Relation {
+	relation := &ast.Relation{}
+	relation.Left = p.LinearExpr()
+	if !p.match(token.LT, token.LE, token.GT, token.GE, token.EQ, token.NEQ) {
+		p.errorf("expected one of %s, %s, %s, %s, %s, or %s tokens, got %q (%s)",
+			token.LT, token.LE, token.GT, token.GE, token.EQ, token.NEQ, p.cur.Value, p.cur.Type)
+	}
+	relation.Op = p.cur.Value
+	p.eat(p.cur.Type)
+	relation.Right = p.LinearExpr()
+	return relation
+}
+
+// Operand = Identifier | Number .
+//
+// Integer literals are returned in their decimal form; they're
+// distinguishable from identifiers, which are made up of letters only (or are
+// quoted).
+func (p *Parser) Operand() string {
+	if p.match(token.WORD, token.QUOTED) {
+		return p.Identifier()
+	}
+	return strconv.Itoa(p.Number())
+}
+
 // IntervalDemand = Identifier ":" Identifier .
 func (p *Parser) IntervalDemand() *ast.IntervalDemand {
 	demand := &ast.IntervalDemand{}
@@ -277,7 +352,7 @@ func (p *Parser) Variables() []string {
 	letter := func(s string) rune { return []rune(s)[0] }
 	for _, v := range variables {
 		parts := strings.Split(v, " to ")
-		if len(parts) == 1 {
+		if len(parts) == 1 || ast.Name(v) != v { // quoted names aren't ranges
 			expanded = append(expanded, v)
 			continue
 		}
@@ -345,6 +420,23 @@ func (p *Parser) IntervalDemands() []*ast.IntervalDemand {
 	return demands
 }
 
+// Relations = Relation { "," Relation } .
+func (p *Parser) Relations() []*ast.Relation {
+	var relations []*ast.Relation
+	relations = append(relations, p.Relation())
+
+	for {
+		if !p.match(token.COMMA) {
+			break
+		}
+
+		p.eat(token.COMMA)
+		relations = append(relations, p.Relation())
+	}
+
+	return relations
+}
+
 // --------------------------------------------------------- List of list types.
 
 // NumbersList = "[" Numbers "]" { "∪" "[" Numbers "]" } .
@@ -395,7 +487,7 @@ func (p *Parser) AssignmentsArgument() ast.Argument {
 	argument.Variables = p.Variables()
 	p.eat(token.RBRACKET)
 	if !p.match(token.EXISTS, token.NEXISTS) {
-		p.Fatalf("expected either %s or %s token, got %q (%s)",
+		p.errorf("expected either %s or %s token, got %q (%s)",
 			token.EXISTS, token.NEXISTS, p.cur.Value, p.cur.Type)
 	}
 	argument.In = p.match(token.EXISTS)
@@ -411,20 +503,20 @@ func (p *Parser) AssignmentsArgument() ast.Argument {
 	return argument
 }
 
-// BinaryOpArgument = Identifier ( "/" | "%" | "*" ) Identifier "==" Identifier .
+// BinaryOpArgument = Operand ( "/" | "%" | "*" ) Operand "==" Operand .
 func (p *Parser) BinaryOpArgument() ast.Argument {
 	argument := &ast.BinaryOpArgument{}
-	argument.Left = p.Identifier()
+	argument.Left = p.Operand()
 
 	if !p.match(token.SLASH, token.MOD, token.ASTERISK) {
-		p.Fatalf("expected one of %s, %s, or %s tokens, got %q (%s)",
+		p.errorf("expected one of %s, %s, or %s tokens, got %q (%s)",
 			token.SLASH, token.MOD, token.ASTERISK, p.cur.Value, p.cur.Type)
 	}
 	argument.Op = p.cur.Value
 	p.eat(p.cur.Type)
-	argument.Right = p.Identifier()
+	argument.Right = p.Operand()
 	p.eat(token.EQ)
-	argument.Target = p.Identifier()
+	argument.Target = p.Operand()
 	return argument
 }
 
@@ -450,14 +542,19 @@ func (p *Parser) CumulativeArgument() ast.Argument {
 func (p *Parser) DomainArgument() ast.Argument {
 	argument := &ast.DomainArgument{}
 
+	// Linear expressions can start off looking like a list of variables (x + y
+	// in ...), so we only settle on the latter if it's followed by "in".
 	var variables []string
-	if p.try(func() { variables = p.Variables() }) {
+	if p.try(func() {
+		variables = p.Variables()
+		p.eat(token.IN)
+	}) {
 		argument.Variables = variables
 	} else {
 		argument.LinearExprs = p.LinearExprs()
+		p.eat(token.IN)
 	}
 
-	p.eat(token.IN)
 	argument.Domains = p.Domains()
 	return argument
 }
@@ -505,7 +602,7 @@ func (p *Parser) LinearEqualityArgument() ast.Argument {
 	argument.Target = p.LinearExpr()
 	p.eat(token.EQ)
 	if !p.match(token.MAX, token.MIN) {
-		p.Fatalf("expected either %s or %s token type, got %q (%s)",
+		p.errorf("expected either %s or %s token type, got %q (%s)",
 			token.MAX, token.MIN, p.cur.Value, p.cur.Type)
 	}
 	argument.Op = p.cur.Value
@@ -534,13 +631,20 @@ func (p *Parser) NonOverlapping2DArgument() ast.Argument {
 	return argument
 }
 
+// RelationsArgument = Relations .
+func (p *Parser) RelationsArgument() ast.Argument {
+	argument := &ast.RelationsArgument{}
+	argument.Relations = p.Relations()
+	return argument
+}
+
 // VariableEqualityArgument = Identifier "==" ("max" | "min" ) "(" Variables ")" .
 func (p *Parser) VariableEqualityArgument() ast.Argument {
 	argument := &ast.VariableEqualityArgument{}
 	argument.Target = p.Identifier()
 	p.eat(token.EQ)
 	if !p.match(token.MAX, token.MIN) {
-		p.Fatalf("expected either %s or %s token type, got %q (%s)",
+		p.errorf("expected either %s or %s token type, got %q (%s)",
 			token.MAX, token.MIN, p.cur.Value, p.cur.Type)
 	}
 	argument.Op = p.cur.Value
@@ -571,6 +675,7 @@ func (p *Parser) VariablesArgument() ast.Argument {
 //          | LinearEqualityArgument
 //          | LinearExprsArgument
 //          | NonOverlapping2DArgument
+//          | RelationsArgument
 //          | VariableEqualityArgument
 //          | VariablesArgument .
 func (p *Parser) Argument() ast.Argument {
@@ -586,6 +691,7 @@ func (p *Parser) Argument() ast.Argument {
 		p.KArgument,
 		p.LinearEqualityArgument,
 		p.NonOverlapping2DArgument,
+		p.RelationsArgument,
 		p.VariableEqualityArgument,
 
 		p.VariablesArgument, // there's ambiguity; give precedence to parsing variables argument
@@ -597,7 +703,7 @@ func (p *Parser) Argument() ast.Argument {
 		if p.try(func() {
 			argument = fn()
 			if !p.match(token.RPAREN) {
-				p.Fatalf("expected %s token, got %s (value=%q)",
+				p.errorf("expected %s token, got %s (value=%q)",
 					token.RPAREN.String(), p.cur.Type.String(), p.cur.Value)
 			}
 		}) {
@@ -605,7 +711,7 @@ func (p *Parser) Argument() ast.Argument {
 		}
 	}
 
-	p.Fatal("expected to match an argument type")
+	p.errorf("expected to match an argument type")
 	return nil
 }
 
@@ -627,7 +733,9 @@ func (p *Parser) Method() ast.Method {
 
 	methodStr := out.String()
 	method, ok := ast.LookupMethod(methodStr)
-	require.Truef(p, ok, "unrecognized method: %s", methodStr)
+	if !ok {
+		p.errorf("unrecognized method: %s", methodStr)
+	}
 	return method
 }
 
@@ -686,7 +794,9 @@ func (p *Parser) try(parse func()) (success bool) {
 // consuming them as it does. It moves the cursor over past the last token.
 func (p *Parser) eat(ts ...token.Type) {
 	for _, t := range ts {
-		require.Truef(p, p.match(t), "expected %s token, got %s (value=%s)", t.String(), p.cur.Type.String(), p.cur.Value)
+		if !p.match(t) {
+			p.errorf("expected %s token, got %s (value=%s)", t.String(), p.cur.Type.String(), p.cur.Value)
+		}
 		p.cur = p.lexer.Next()
 	}
 }
@@ -700,57 +810,20 @@ func (p *Parser) match(ts ...token.Type) bool {
 	return match
 }
 
-// testingT is n wrapper around testing.T; it's used by the parser to collect
-// intercept parsing errors with arbitrary look-ahead.
-type testingT interface {
-	Errorf(format string, args ...interface{})
-	Fatalf(format string, args ...interface{})
-	Fatal(args ...interface{})
-	Fail()
-	FailNow()
+// Err returns the first error encountered while parsing, if any.
+func (p *Parser) Err() error {
+	return p.err
 }
 
-var _ testingT = &Parser{}
-
-// Errorf is parting of the testingT interface.
-func (p *Parser) Errorf(format string, args ...interface{}) {
-	if !p.trying {
-		p.tb.Logf(format, args...)
-	}
-	p.Fail()
-}
-
-// Fatalf is parting of the testingT interface.
-func (p *Parser) Fatalf(format string, args ...interface{}) {
-	if !p.trying {
-		p.tb.Logf(format, args...)
-	}
-	p.FailNow()
-}
-
-// Fatal is parting of the testingT interface.
-func (p *Parser) Fatal(args ...interface{}) {
-	if !p.trying {
-		p.tb.Log(args...)
-	}
-	p.FailNow()
-}
-
-// Fail is parting of the testingT interface.
-func (p *Parser) Fail() {
+// errorf records a parsing error. Under a try closure, it simply marks the
+// attempt as having failed. Parsing continues past errors (the closures passed
+// to try rely on this too), so callers are expected to check Err once done.
+func (p *Parser) errorf(format string, args ...interface{}) {
 	if p.trying {
 		p.failed = true
 		return
 	}
-
-	p.tb.Fail()
-}
-
-// FailNow is parting of the testingT interface.
-func (p *Parser) FailNow() {
-	if p.trying {
-		p.failed = true
-		return
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
 	}
-	p.tb.FailNow()
 }
@@ -22,20 +22,20 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/datadriven"
+	"github.com/irfansharif/solver/internal/parser"
+	"github.com/irfansharif/solver/internal/parser/ast"
 	"github.com/irfansharif/solver/internal/testutils/bazel"
-	"github.com/irfansharif/solver/internal/testutils/parser"
-	"github.com/irfansharif/solver/internal/testutils/parser/ast"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/ebnf"
 )
 
 func TestDatadriven(t *testing.T) {
 	datadriven.Walk(t, "testdata", func(t *testing.T, path string) {
-		path, implant := bazel.WritableSandboxPathFor(t, "internal/testutils/parser", path)
+		path, implant := bazel.WritableSandboxPathFor(t, "internal/parser", path)
 		defer implant()
 
 		datadriven.RunTest(t, path, func(t *testing.T, d *datadriven.TestData) string {
-			p := parser.New(t, d.Input)
+			p := parser.New(d.Input)
 			var out string
 			switch d.Cmd {
 			case "receiver":
@@ -77,6 +77,16 @@ func TestDatadriven(t *testing.T) {
 					strs = append(strs, fmt.Sprintf("%d", number))
 				}
 				out = strings.Join(strs, ", ")
+			case "range":
+				r := p.Range()
+				out = fmt.Sprintf("%d..%d", r.LowerBound, r.UpperBound)
+			case "relations":
+				relations := p.Relations()
+				var strs []string
+				for _, relation := range relations {
+					strs = append(strs, relation.String())
+				}
+				out = strings.Join(strs, ", ")
 			case "intervals":
 				intervals := p.Intervals()
 				var strs []string
@@ -160,6 +170,7 @@ func TestDatadriven(t *testing.T) {
 				t.Errorf("unrecognized command: %s", d.Cmd)
 			}
 
+			require.NoError(t, p.Err())
 			if !p.EOF() {
 				return fmt.Sprintf("err: expected EOF; parsed %q", out)
 			}
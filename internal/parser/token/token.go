@@ -31,6 +31,7 @@ const (
 	// Words and digits.
 	WORD   // x, yyz, ...
 	DIGITS // 42, 1343, ...
+	QUOTED // "x y", "Nurse Ana — Mon", ...
 
 	// Operations.
 	PLUS     // +
@@ -42,6 +43,8 @@ const (
 	MOD      // %
 	LT       // <
 	GT       // >
+	LE       // <=
+	GE       // >=
 	EXISTS   // ∈
 	NEXISTS  // ∉
 	UNION    // ∪
@@ -50,6 +53,7 @@ const (
 
 	// Delimiters.
 	DOT      // .
+	RANGE    // ..
 	COLON    // :
 	COMMA    // ,
 	PIPE     // |
@@ -11,30 +11,22 @@ import (
 )
 
 // Scanner is a convenience wrapper around a bufio.Scanner that keeps track of
-// the last read line number. It also:
-// - captures an associated name for the reader (typically a file name) to
-//   generate positional error messages.
-// - embeds a *testing.T to automatically record errors with the position it
-//   corresponds to.
+// the last read line number. It also captures an associated name for the
+// reader (typically a file name) to generate positional error messages.
 type Scanner struct {
-	*testing.T
 	*bufio.Scanner
 	line int
 	name string
 }
 
-func NewScanner(t *testing.T, r io.Reader, name string, line int) *Scanner {
+// NewScanner returns a scanner over the given reader, starting off at the
+// given line number.
+func NewScanner(r io.Reader, name string, line int) *Scanner {
 	bufioScanner := bufio.NewScanner(r)
 	// We use a large max-token-size to account for lines in the output that far
 	// exceed the default bufio Scanner token size.
 	bufioScanner.Buffer(make([]byte, 100), 10*bufio.MaxScanTokenSize)
-	// TODO(irfansharif): Detect if we're running under bazel, and if so, strip
-	// out the sandbox path prefix.
-	if bazel.BuiltWithBazel() {
-		name = strings.TrimPrefix(name, bazel.ScratchDirectory(t))
-	}
 	return &Scanner{
-		T:       t,
 		Scanner: bufioScanner,
 		line:    line,
 		name:    name,
@@ -50,38 +42,67 @@ func (s *Scanner) Scan() bool {
 	return ok
 }
 
+// Line returns the line number of the last read line.
+func (s *Scanner) Line() int {
+	return s.line
+}
+
+// Errorf returns an error prefixed with the position of the last read line.
+func (s *Scanner) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", s.pos(), fmt.Sprintf(format, args...))
+}
+
+// pos is a file:line prefix for the input file, suitable for inclusion in logs
+// and error messages.
+func (s *Scanner) pos() string {
+	return fmt.Sprintf("%s:%d", s.name, s.line)
+}
+
+// TestingScanner is a Scanner for use in tests. It embeds a *testing.T to
+// automatically record errors with the position they correspond to.
+type TestingScanner struct {
+	*testing.T
+	*Scanner
+}
+
+// NewTestingScanner returns a testing scanner over the given reader, starting
+// off at the given line number.
+func NewTestingScanner(t *testing.T, r io.Reader, name string, line int) *TestingScanner {
+	if bazel.BuiltWithBazel() {
+		name = strings.TrimPrefix(name, bazel.ScratchDirectory(t))
+	}
+	return &TestingScanner{
+		T:       t,
+		Scanner: NewScanner(r, name, line),
+	}
+}
+
 // Fatal is thin wrapper around testing.T's interface.
-func (s *Scanner) Fatal(args ...interface{}) {
+func (s *TestingScanner) Fatal(args ...interface{}) {
 	s.T.Fatalf("%s: %s", s.pos(), fmt.Sprint(args...))
 }
 
 // Fatalf is thin wrapper around testing.T's interface.
-func (s *Scanner) Fatalf(format string, args ...interface{}) {
+func (s *TestingScanner) Fatalf(format string, args ...interface{}) {
 	s.T.Fatalf("%s: %s", s.pos(), fmt.Sprintf(format, args...))
 }
 
 // Error is thin wrapper around testing.T's interface.
-func (s *Scanner) Error(args ...interface{}) {
+func (s *TestingScanner) Error(args ...interface{}) {
 	s.T.Errorf("%s: %s", s.pos(), fmt.Sprint(args...))
 }
 
 // Errorf is thin wrapper around testing.T's interface.
-func (s *Scanner) Errorf(format string, args ...interface{}) {
+func (s *TestingScanner) Errorf(format string, args ...interface{}) {
 	s.T.Errorf("%s: %s", s.pos(), fmt.Sprintf(format, args...))
 }
 
 // Log is thin wrapper around testing.T's interface.
-func (s *Scanner) Log(args ...interface{}) {
+func (s *TestingScanner) Log(args ...interface{}) {
 	s.T.Logf("%s: %s", s.pos(), fmt.Sprint(args...))
 }
 
 // Logf is thin wrapper around testing.T's interface.
-func (s *Scanner) Logf(format string, args ...interface{}) {
-	s.T.Logf("%s: %s", s.pos(), fmt.Sprint(args...))
-}
-
-// pos is a file:line prefix for the input file, suitable for inclusion in logs
-// and error messages.
-func (s *Scanner) pos() string {
-	return fmt.Sprintf("%s:%d", s.name, s.line)
+func (s *TestingScanner) Logf(format string, args ...interface{}) {
+	s.T.Logf("%s: %s", s.pos(), fmt.Sprintf(format, args...))
 }
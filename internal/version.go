@@ -0,0 +1,32 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package internal
+
+// ORToolsMajorVersion and ORToolsMinorVersion identify the OR-Tools release
+// the package is linked against. They need to be kept in sync with the
+// ortools pin in WORKSPACE (and the c-deps/or-tools submodule).
+const (
+	ORToolsMajorVersion = 9
+	ORToolsMinorVersion = 1
+)
+
+// ORToolsVersionAtLeast returns true iff the linked OR-Tools release is at
+// least the given version.
+func ORToolsVersionAtLeast(major, minor int) bool {
+	if ORToolsMajorVersion != major {
+		return ORToolsMajorVersion > major
+	}
+	return ORToolsMinorVersion >= minor
+}
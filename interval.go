@@ -18,7 +18,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/pb"
 )
 
 // Interval represents an interval parameterized by a start, end, and
@@ -55,19 +55,19 @@ type interval struct {
 var _ Interval = &interval{}
 
 func newInterval(start, end, size IntVar, idx int32, name string) Interval {
+	ct := arenaFor(start).constraintProto()
+	ct.Name = name
+	ct.Constraint = &pb.ConstraintProto_Interval{
+		Interval: &pb.IntervalConstraintProto{
+			Start: start.index(),
+			End:   end.index(),
+			Size:  size.index(),
+		},
+	}
 	return &interval{
 		start: start, end: end, size: size,
 		idx: idx,
-		pb: &pb.ConstraintProto{
-			Name: name,
-			Constraint: &pb.ConstraintProto_Interval{
-				Interval: &pb.IntervalConstraintProto{
-					Start: start.index(),
-					End:   end.index(),
-					Size:  size.index(),
-				},
-			},
-		},
+		pb:  ct,
 	}
 }
 
@@ -79,7 +79,9 @@ func (i *interval) Parameters() (start, end, size IntVar) {
 // OnlyEnforceIf is part of the Interval interface.
 func (i *interval) OnlyEnforceIf(literals ...Literal) Constraint {
 	if len(literals) > 1 {
-		panic("intervals can only be enforced with a single literal")
+		modelFor(i.start).fail("interval %s: can only be enforced with a single literal, found %d (%s)",
+			i.name(), len(literals), asIntVars(literals).names())
+		return i
 	}
 	i.pb.EnforcementLiteral = asIntVars(literals).indexes()
 	if len(literals) == 1 {
@@ -117,6 +119,8 @@ func (i *interval) name() string {
 	name := i.pb.GetName()
 	if name == "" {
 		name = "<unnamed>"
+	} else {
+		name = quoteName(name)
 	}
 	return name
 }
@@ -135,3 +139,29 @@ func (is intervalList) indexes() []int32 {
 	}
 	return indexes
 }
+
+func (is intervalList) names() string {
+	var names []string
+	for _, iv := range is {
+		names = append(names, iv.name())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (is intervalList) starts() []IntVar {
+	var starts []IntVar
+	for _, iv := range is {
+		start, _, _ := iv.Parameters()
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+func (is intervalList) ends() []IntVar {
+	var ends []IntVar
+	for _, iv := range is {
+		_, end, _ := iv.Parameters()
+		ends = append(ends, end)
+	}
+	return ends
+}
@@ -16,8 +16,10 @@ package solver
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/pb"
 )
 
 // IntVar is an integer variable. It's typically constructed using a domain and
@@ -27,7 +29,25 @@ type IntVar interface {
 	// Stringer provides a printable format representation for the int var.
 	fmt.Stringer
 
+	// Plus returns a linear expression representing the sum of this variable
+	// and the given one, as in x.Plus(y).
+	Plus(v IntVar) LinearExpr
+	// Minus returns a linear expression representing this variable less the
+	// given constant, as in x.Minus(3). To subtract variables, use
+	// LinearExpr.Sub, as in Sum(x).Sub(Sum(y)).
+	Minus(c int64) LinearExpr
+	// Times returns a linear expression representing this variable
+	// multiplied by the given coefficient, as in x.Times(5).
+	Times(coeff int64) LinearExpr
+	// WithName sets a name for the variable, overriding the one it was
+	// instantiated with (if any); used for debugging/logging purposes, like
+	// with constraints (see Constraint.WithName). Names of auxiliary variables
+	// already derived from the variable's are left as is. Negated literals
+	// name the literals they negate.
+	WithName(name string) IntVar
+
 	name() string
+	label() string
 	index() int32
 	domain() Domain
 }
@@ -51,6 +71,17 @@ type intVar struct {
 	d   Domain
 
 	isLiteral, isConst bool
+
+	// negation is the literal's negated form, see Not. It's cached to not
+	// allocate on every call, and links negated literals back to the originals
+	// (the underlying proto is shared).
+	negation *intVar
+
+	// model is the model the variable was instantiated in, if any.
+	// Constraints are constructed independently of the models they're added
+	// to, so this is how we find our way back to it (to allocate protos from
+	// its arena, or to report construction errors).
+	model *Model
 }
 
 var _ IntVar = &intVar{}
@@ -75,9 +106,9 @@ func (i *intVar) String() string {
 	if i.isLiteral {
 		domainStr = ""
 	} else if i.isConst {
-		domainStr = fmt.Sprintf(" == %d", i.d.list(0)[0])
+		domainStr = fmt.Sprintf(" == %d", i.domain().list(0)[0])
 	} else {
-		domainStr = fmt.Sprintf(" in %s", i.d.String())
+		domainStr = fmt.Sprintf(" in %s", i.domain().String())
 	}
 
 	return fmt.Sprintf("%s%s", i.name(), domainStr)
@@ -85,9 +116,39 @@ func (i *intVar) String() string {
 
 // name is part of the IntVar interface.
 func (i *intVar) name() string {
+	if i.isNegated() {
+		return fmt.Sprintf("~%s", i.negation.name())
+	}
+
 	name := i.pb.GetName()
 	if name == "" {
 		name = "<unnamed>"
+	} else {
+		name = quoteName(name)
+	}
+	return name
+}
+
+// label is part of the IntVar interface. Unlike name, it's unquoted.
+func (i *intVar) label() string {
+	if i.isNegated() {
+		return fmt.Sprintf("~%s", i.negation.label())
+	}
+
+	name := i.pb.GetName()
+	if name == "" {
+		name = "<unnamed>"
+	}
+	return name
+}
+
+// quoteName quotes the given name if it isn't printable as is, which is the
+// case for names with whitespace, quotes, or delimiters used when printing
+// (brackets, commas, etc). Names are quoted using Go's rules for string
+// literals.
+func quoteName(name string) string {
+	if strings.ContainsAny(name, " \t\n\"\\,:|()[]{}") || strconv.Quote(name) != `"`+name+`"` {
+		return strconv.Quote(name)
 	}
 	return name
 }
@@ -102,19 +163,72 @@ func (i *intVar) isNegated() bool {
 }
 
 func (i *intVar) domain() Domain {
+	// The model may have since changed the domain of the proto we share (see
+	// Model.SetHorizon), including for variables it doesn't hold onto and so
+	// can't update.
+	if ls := i.pb.GetDomain(); !i.isNegated() && !equal(ls, i.d.(*domain).intervals) {
+		return &domain{intervals: ls}
+	}
 	return i.d
 }
 
-// Not is part of the Literal interface.
+// WithName is part of the IntVar interface.
+func (i *intVar) WithName(name string) IntVar {
+	i.pb.Name = name
+	return i
+}
+
+// Plus is part of the IntVar interface.
+func (i *intVar) Plus(v IntVar) LinearExpr {
+	return Sum(i).Add(Sum(v))
+}
+
+// Minus is part of the IntVar interface.
+func (i *intVar) Minus(c int64) LinearExpr {
+	return NewLinearExpr([]IntVar{i}, []int64{1}, -c)
+}
+
+// Times is part of the IntVar interface.
+func (i *intVar) Times(coeff int64) LinearExpr {
+	return NewLinearExpr([]IntVar{i}, []int64{coeff}, 0)
+}
+
+// Not is part of the Literal interface. Negating a literal twice returns the
+// original literal.
 func (i *intVar) Not() Literal {
-	return &intVar{
-		pb: &pb.IntegerVariableProto{
-			Name:   fmt.Sprintf("~%s", i.name()),
-			Domain: i.d.list(0),
-		},
-		idx: -i.idx - 1,
-		d:   i.d,
+	if i.negation == nil {
+		i.negation = &intVar{
+			pb:        i.pb,
+			idx:       -i.idx - 1,
+			d:         i.d,
+			isLiteral: true,
+			negation:  i,
+			model:     i.model,
+		}
+	}
+	return i.negation
+}
+
+// protoName returns the name the given variable was instantiated with, or the
+// empty string if unnamed. It's used to derive names for auxiliary variables
+// (see derivedName).
+func protoName(iv IntVar) string {
+	if v, ok := iv.(*intVar); ok {
+		return v.pb.GetName()
 	}
+	return ""
+}
+
+// Bounds returns the lower and upper bounds of the given variable's domain.
+// Empty domains have none, are reported as errors (see WithConstructionErrors),
+// and have zero returned for both.
+func Bounds(iv IntVar) (lb, ub int64) {
+	ls := iv.domain().list(0)
+	if len(ls) == 0 {
+		modelFor(iv).fail("bounds: %s has an empty domain", iv)
+		return 0, 0
+	}
+	return ls[0], ls[len(ls)-1]
 }
 
 // AsIntVars is a convenience function to convert a slice of Literals to
@@ -137,6 +251,22 @@ func (is intVarList) indexes() []int32 {
 	return indexes
 }
 
+func (is intVarList) names() string {
+	var names []string
+	for _, iv := range is {
+		names = append(names, iv.name())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (is intVarList) linearExprs() []LinearExpr {
+	var exprs []LinearExpr
+	for _, iv := range is {
+		exprs = append(exprs, Sum(iv))
+	}
+	return exprs
+}
+
 func asIntVars(literals []Literal) intVarList {
 	return AsIntVars(literals)
 }
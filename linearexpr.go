@@ -17,9 +17,10 @@ package solver
 import (
 	"fmt"
 	"math"
+	"math/bits"
 	"strings"
 
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/pb"
 )
 
 // LinearExpr represents a linear expression of the form:
@@ -34,6 +35,21 @@ type LinearExpr interface {
 	// expression is comprised of.
 	Parameters() (vars []IntVar, coeffs []int64, offset int64)
 
+	// Add returns a new linear expression representing the sum of this one
+	// and the given one. Variables appearing in both have their coefficients
+	// combined.
+	Add(e LinearExpr) LinearExpr
+	// Sub is like Add, for the difference of this linear expression and the
+	// given one.
+	Sub(e LinearExpr) LinearExpr
+	// Neg returns a new linear expression representing the negation of this
+	// one.
+	Neg() LinearExpr
+	// ScaleBy returns a new linear expression representing this one
+	// multiplied by the given factor. Like NewLinearExpr, it doesn't check for
+	// overflow.
+	ScaleBy(factor int64) LinearExpr
+
 	fmt.Stringer
 
 	vars() []int32
@@ -55,6 +71,30 @@ func (l *linearExpr) Parameters() (vars []IntVar, coeffs []int64, offset int64)
 
 var _ LinearExpr = &linearExpr{}
 
+// Add is part of the LinearExpr interface.
+func (l *linearExpr) Add(e LinearExpr) LinearExpr {
+	return difference(l, negate(e))
+}
+
+// Sub is part of the LinearExpr interface.
+func (l *linearExpr) Sub(e LinearExpr) LinearExpr {
+	return difference(l, e)
+}
+
+// Neg is part of the LinearExpr interface.
+func (l *linearExpr) Neg() LinearExpr {
+	return negate(l)
+}
+
+// ScaleBy is part of the LinearExpr interface.
+func (l *linearExpr) ScaleBy(factor int64) LinearExpr {
+	scaled := make([]int64, len(l.coeffs()))
+	for i, coeff := range l.coeffs() {
+		scaled[i] = coeff * factor
+	}
+	return NewLinearExpr(l.intVars, scaled, l.offset()*factor)
+}
+
 // Sum instantiates a new linear expression representing the sum of the given
 // variables. It's a shorthand for NewLinearExpr with no offset and coefficients
 // equal to one.
@@ -66,16 +106,83 @@ func Sum(vars ...IntVar) LinearExpr {
 	return NewLinearExpr(vars, coeffs, 0)
 }
 
-// TODO(irfansharif): We could instead construct a linear constraint bit-by-bit,
-// setting coefficient per int var, setting offset, etc.
+// ScalProd instantiates a new linear expression representing the scalar
+// product of the given variables and coefficients:
+//
+//   sum(coefficients[i] * vars[i])
 //
-// 	expr := NewLinearExpr(
-// 		WithVars(...),
-// 		WithOffset(),
-// 		WithCoeffs(),
-// 	)
-// 	expr.SetCoefficient(v, 2)
-// 	expr.SetOffset(2)
+// Unlike NewLinearExpr, it validates its inputs: it panics if the number of
+// variables and coefficients differ, or if the expression could overflow an
+// int64 given the domains of the variables involved (see
+// WithConstructionErrors for an alternative to panicking). The coefficients
+// are copied.
+func ScalProd(vars []IntVar, coeffs []int64) LinearExpr {
+	if len(vars) != len(coeffs) {
+		modelFor(vars...).fail("scalar product: mismatched number of variables (%d: %s) and coefficients (%d)",
+			len(vars), intVarList(vars).names(), len(coeffs))
+		return Sum()
+	}
+
+	var bound uint64 // the largest magnitude the expression can take on
+	for i, v := range vars {
+		ls := v.domain().list(0)
+		if len(ls) == 0 {
+			continue // takes on no values, so contributes nothing to the bound
+		}
+		lb, ub := ls[0], ls[len(ls)-1]
+		magnitude := abs(lb)
+		if m := abs(ub); m > magnitude {
+			magnitude = m
+		}
+
+		hi, term := bits.Mul64(abs(coeffs[i]), magnitude)
+		sum, carry := bits.Add64(bound, term, 0)
+		if hi != 0 || carry != 0 || sum > math.MaxInt64 {
+			modelFor(vars...).fail("scalar product: possible integer overflow (term #%d: %d*%s)", i, coeffs[i], v)
+			return Sum()
+		}
+		bound = sum
+	}
+	return NewLinearExpr(vars, append([]int64(nil), coeffs...), 0)
+}
+
+// Dot instantiates a new linear expression representing the total cost of the
+// given literals that are true, where costs[i] is the cost of literals[i]. It's
+// typically used with rows of literal matrices (assignments of workers to
+// tasks, say). Like ScalProd, it validates its inputs.
+func Dot(literals []Literal, costs []int64) LinearExpr {
+	return ScalProd(AsIntVars(literals), costs)
+}
+
+// linearBounds returns the bounds of the given linear expression, given the
+// domains of the variables involved. Variables with empty domains are reported
+// as errors, and contribute nothing to the bounds.
+func linearBounds(e LinearExpr) (lb, ub int64) {
+	vars, coeffs, offset := e.Parameters()
+	lb, ub = offset, offset
+	for i, v := range vars {
+		ls := v.domain().list(0)
+		if len(ls) == 0 {
+			modelFor(vars...).fail("linear expression %s: %s has an empty domain", e, v)
+			continue
+		}
+		vlb, vub := ls[0], ls[len(ls)-1]
+		if coeffs[i] < 0 {
+			vlb, vub = vub, vlb
+		}
+		lb, ub = lb+coeffs[i]*vlb, ub+coeffs[i]*vub
+	}
+	return lb, ub
+}
+
+// abs returns the magnitude of the given integer; unlike math.Abs it's exact,
+// including for math.MinInt64.
+func abs(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
 
 // NewLinearExpr instantiates a new linear expression, representing:
 //
@@ -91,6 +198,112 @@ func NewLinearExpr(vars []IntVar, coeffs []int64, offset int64) LinearExpr {
 	}
 }
 
+// LinearExprBuilder is used to construct linear expressions incrementally,
+// term by term, typically inside loops:
+//
+//   b := NewLinearExprBuilder()
+//   for i, v := range vars {
+//     b.AddTerm(v, costs[i])
+//   }
+//   b.AddConstant(42)
+//   expr := b.Build()
+//
+// Terms over the same variable are combined, in the order the variables were
+// first added.
+type LinearExprBuilder struct {
+	vars      []IntVar
+	coeffs    []int64
+	offset    int64
+	positions map[int32]int // variable index => position in vars
+}
+
+// NewLinearExprBuilder instantiates a new builder for a linear expression,
+// initially zero.
+func NewLinearExprBuilder() *LinearExprBuilder {
+	return &LinearExprBuilder{positions: make(map[int32]int)}
+}
+
+// AddTerm adds coeff*v to the expression being built.
+func (b *LinearExprBuilder) AddTerm(v IntVar, coeff int64) *LinearExprBuilder {
+	b.coeffs[b.position(v)] += coeff
+	return b
+}
+
+// AddConstant adds the given constant to the expression being built.
+func (b *LinearExprBuilder) AddConstant(c int64) *LinearExprBuilder {
+	b.offset += c
+	return b
+}
+
+// SetCoefficient sets the coefficient of the given variable in the expression
+// being built, overriding terms over it added so far. Setting it to zero
+// drops the variable from the expression.
+func (b *LinearExprBuilder) SetCoefficient(v IntVar, coeff int64) *LinearExprBuilder {
+	b.coeffs[b.position(v)] = coeff
+	return b
+}
+
+// Build returns the linear expression built so far; terms with zero
+// coefficients are left out. The builder can continue to be used
+// afterwards, without affecting the expressions already built.
+func (b *LinearExprBuilder) Build() LinearExpr {
+	var vars []IntVar
+	var coeffs []int64
+	for i, v := range b.vars {
+		if b.coeffs[i] != 0 {
+			vars, coeffs = append(vars, v), append(coeffs, b.coeffs[i])
+		}
+	}
+	return NewLinearExpr(vars, coeffs, b.offset)
+}
+
+// position returns the position of the given variable's term, adding a zero
+// one if there's none.
+func (b *LinearExprBuilder) position(v IntVar) int {
+	if pos, ok := b.positions[v.index()]; ok {
+		return pos
+	}
+	b.positions[v.index()] = len(b.vars)
+	b.vars, b.coeffs = append(b.vars, v), append(b.coeffs, 0)
+	return len(b.vars) - 1
+}
+
+// negate returns a new linear expression representing -e.
+func negate(e LinearExpr) LinearExpr {
+	vars, coeffs, offset := e.Parameters()
+	negated := make([]int64, len(coeffs))
+	for i, coeff := range coeffs {
+		negated[i] = -coeff
+	}
+	return NewLinearExpr(vars, negated, -offset)
+}
+
+// difference returns a new linear expression representing a - b. Variables
+// appearing in both have their coefficients combined.
+func difference(a, b LinearExpr) LinearExpr {
+	avars, acoeffs, aoffset := a.Parameters()
+	bvars, bcoeffs, boffset := b.Parameters()
+
+	var vars []IntVar
+	var coeffs []int64
+	positions := make(map[int32]int)
+	add := func(v IntVar, coeff int64) {
+		if pos, ok := positions[v.index()]; ok {
+			coeffs[pos] += coeff
+			return
+		}
+		positions[v.index()] = len(vars)
+		vars, coeffs = append(vars, v), append(coeffs, coeff)
+	}
+	for i, v := range avars {
+		add(v, acoeffs[i])
+	}
+	for i, v := range bvars {
+		add(v, -bcoeffs[i])
+	}
+	return NewLinearExpr(vars, coeffs, aoffset-boffset)
+}
+
 // String is part of the LinearExpr interface.
 func (l *linearExpr) String() string {
 	var b strings.Builder
@@ -120,7 +333,9 @@ func (l *linearExpr) String() string {
 		b.WriteString(fmt.Sprintf("%s%s", coeffStr, v.name()))
 	}
 
-	if offset := l.offset(); offset != 0 {
+	if offset := l.offset(); len(l.intVars) == 0 {
+		b.WriteString(fmt.Sprintf("%d", offset)) // constant expressions
+	} else if offset != 0 {
 		abs := int64(math.Abs(float64(offset)))
 		signStr := "+"
 		if offset < 0 {
@@ -161,3 +376,24 @@ func (le linearExprList) protos() []*pb.LinearExpressionProto {
 	}
 	return ls
 }
+
+// String returns a comma-separated representation of the linear expressions.
+func (le linearExprList) String() string {
+	var b strings.Builder
+	for i, expr := range le {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(expr.String())
+	}
+	return b.String()
+}
+
+func (le linearExprList) intVars() []IntVar {
+	var vars []IntVar
+	for _, expr := range le {
+		vs, _, _ := expr.Parameters()
+		vars = append(vars, vs...)
+	}
+	return vars
+}
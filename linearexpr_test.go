@@ -15,6 +15,7 @@
 package solver
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -32,4 +33,106 @@ func TestLinearExprString(t *testing.T) {
 	require.Equal(t, "0a - b + 42c + 32", NewLinearExpr([]IntVar{a, b, c}, []int64{0, -1, 42}, 32).String())
 	require.Equal(t, "-b + 42c", NewLinearExpr([]IntVar{b, c}, []int64{-1, 42}, 0).String())
 	require.Equal(t, "-b + 42c + 10", NewLinearExpr([]IntVar{b, c}, []int64{-1, 42}, 10).String())
+	require.Equal(t, "-4", NewLinearExpr(nil, nil, -4).String())
+}
+
+func TestLinearExprNegate(t *testing.T) {
+	model := NewModel("")
+	a := model.NewIntVar(0, 10, "a")
+	b := model.NewIntVar(0, 10, "b")
+
+	require.Equal(t, "-a + 2b - 3", negate(NewLinearExpr([]IntVar{a, b}, []int64{1, -2}, 3)).String())
+}
+
+func TestScalProd(t *testing.T) {
+	model := NewModel("")
+	a := model.NewIntVar(-10, 10, "a")
+	b := model.NewIntVar(0, 10, "b")
+	x, y := model.NewLiteral("x"), model.NewLiteral("y")
+
+	coeffs := []int64{2, -3}
+	expr := ScalProd([]IntVar{a, b}, coeffs)
+	require.Equal(t, "2a - 3b", expr.String())
+	coeffs[0] = 4 // coefficients are copied
+	require.Equal(t, "2a - 3b", expr.String())
+	require.Equal(t, "5x + 7y", Dot([]Literal{x, y}, []int64{5, 7}).String())
+
+	require.Panics(t, func() { ScalProd([]IntVar{a, b}, []int64{1}) })
+	require.Panics(t, func() { Dot([]Literal{x}, []int64{1, 2}) })
+
+	huge := model.NewIntVar(math.MinInt64+1, math.MaxInt64-1, "huge")
+	require.NotPanics(t, func() { ScalProd([]IntVar{huge}, []int64{1}) })
+	require.Panics(t, func() { ScalProd([]IntVar{huge}, []int64{2}) })
+	require.Panics(t, func() { ScalProd([]IntVar{huge, x}, []int64{1, 2}) })
+	require.NotPanics(t, func() { ScalProd([]IntVar{a}, []int64{math.MaxInt64 / 10}) })
+	require.Panics(t, func() { ScalProd([]IntVar{a}, []int64{math.MinInt64}) })
+
+	empty := model.NewIntVarFromDomain(&domain{}, "empty")
+	require.Equal(t, "2a + 3empty", ScalProd([]IntVar{a, empty}, []int64{2, 3}).String())
+}
+
+func TestBoundsOfEmptyDomains(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	a := model.NewIntVar(-10, 10, "a")
+	empty := model.NewIntVarFromDomain(&domain{}, "empty")
+
+	lb, ub := Bounds(empty)
+	require.Equal(t, [2]int64{0, 0}, [2]int64{lb, ub})
+	lb, ub = linearBounds(NewLinearExpr([]IntVar{a, empty}, []int64{2, 3}, 1))
+	require.Equal(t, [2]int64{-19, 21}, [2]int64{lb, ub})
+	require.Len(t, model.errs, 2)
+	require.Contains(t, model.errs[0].Error(), "bounds: empty")
+	require.Contains(t, model.errs[1].Error(), "linear expression 2a + 3empty + 1: empty")
+
+	require.Panics(t, func() { Bounds(NewModel("").NewIntVarFromDomain(&domain{}, "empty")) })
+}
+
+func TestLinearExprBuilder(t *testing.T) {
+	model := NewModel("")
+	a := model.NewIntVar(0, 10, "a")
+	b := model.NewIntVar(0, 10, "b")
+	c := model.NewIntVar(0, 10, "c")
+
+	builder := NewLinearExprBuilder()
+	require.Equal(t, "0", builder.Build().String())
+	for i, v := range []IntVar{a, b, a} {
+		builder.AddTerm(v, int64(i+1))
+	}
+	builder.AddConstant(5).AddConstant(-2)
+	expr := builder.Build()
+	require.Equal(t, "4a + 2b + 3", expr.String())
+
+	builder.SetCoefficient(b, 0).SetCoefficient(c, -7).AddTerm(a, -1)
+	require.Equal(t, "3a - 7c + 3", builder.Build().String())
+	require.Equal(t, "4a + 2b + 3", expr.String()) // already built
+}
+
+func TestLinearExprAlgebra(t *testing.T) {
+	model := NewModel("")
+	a := model.NewIntVar(0, 10, "a")
+	b := model.NewIntVar(0, 10, "b")
+	c := model.NewIntVar(0, 10, "c")
+
+	x := NewLinearExpr([]IntVar{a, b}, []int64{1, 2}, 3)
+	y := NewLinearExpr([]IntVar{b, c}, []int64{-2, 4}, -1)
+	require.Equal(t, "a + 0b + 4c + 2", x.Add(y).String())
+	require.Equal(t, "a + 4b - 4c + 4", x.Sub(y).String())
+	require.Equal(t, "-a - 2b - 3", x.Neg().String())
+	require.Equal(t, "3a + 6b + 9", x.ScaleBy(3).String())
+	require.Equal(t, "2a + 8b - 8c + 8", x.Sub(y).ScaleBy(2).Sub(x.Sub(x)).String())
+	require.Equal(t, "a + 2b + 3", x.String()) // unchanged
+}
+
+func TestIntVarArithmetic(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	l := model.NewLiteral("l")
+
+	require.Equal(t, "x + y", x.Plus(y).String())
+	require.Equal(t, "x - 3", x.Minus(3).String())
+	require.Equal(t, "x + 3", x.Minus(-3).String())
+	require.Equal(t, "5x", x.Times(5).String())
+	require.Equal(t, "2x + 3y - 2", x.Plus(y).ScaleBy(2).Add(y.Minus(2)).String())
+	require.Equal(t, "x + ~l", x.Plus(l.Not()).String())
 }
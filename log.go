@@ -0,0 +1,190 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/irfansharif/solver/internal"
+)
+
+// LogLevel determines which of the solver's log lines are emitted.
+type LogLevel int
+
+const (
+	// LogLevelDebug emits all log lines, including presolve details and
+	// per-worker statistics.
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo emits search progress (solutions found, bound
+	// improvements) and the final summary.
+	LogLevelInfo
+	// LogLevelSummary only emits the final summary.
+	LogLevelSummary
+	// LogLevelQuiet emits nothing at all.
+	LogLevelQuiet
+)
+
+// String implements the fmt.Stringer interface.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelSummary:
+		return "summary"
+	case LogLevelQuiet:
+		return "quiet"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// WithLogFilter configures the solver to only emit log lines at or above the
+// given level. It's only meaningful when used together with WithLogger.
+func WithLogFilter(level LogLevel) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.logFilter = level
+	}
+}
+
+// WithLogLevel configures how verbose the solver is. It's only meaningful when
+// used together with WithLogger or WithLogHandler. The underlying solver only
+// lets us turn its logging on or off as a whole, so LogLevelQuiet turns it
+// off, saving the work of generating it; other levels leave it on and drop
+// lines after the fact, like WithLogFilter does.
+func WithLogLevel(level LogLevel) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.logFilter = level
+		o.logLevel = &level
+	}
+}
+
+// setLogParams maps the configured log level, if any, onto the solver's
+// verbosity flag (it only has the one, log_search_progress). It's applied
+// after all other options, so it takes precedence over the defaults set up by
+// WithLogger and WithLogHandler.
+func (o *options) setLogParams() {
+	if o.logLevel == nil {
+		return
+	}
+	logSearchProgress := *o.logLevel < LogLevelQuiet
+	o.params.LogSearchProgress = &logSearchProgress
+}
+
+// enableLogging configures the solver to log its search progress to the
+// response proto, where we pick it up once the solve is done.
+func (o *options) enableLogging() {
+	logSearchProgress, logToResponse, logToStdout := true, true, false
+	o.params.LogSearchProgress = &logSearchProgress
+	o.params.LogToStdout = &logToStdout
+	o.params.LogToResponse = &logToResponse
+}
+
+// summaryHeader marks the start of the solver's final summary.
+const summaryHeader = "CpSolverResponse summary:"
+
+// logLine is a single line from the solver's log.
+type logLine struct {
+	level  LogLevel
+	worker string // empty if the line isn't attributable to a worker
+	text   string
+}
+
+// logHandler receives the lines of the solve log, one at a time.
+type logHandler func(logLine)
+
+// parseLog splits up the given solve log into individual lines, classifying
+// each one by level. Search progress lines are attributed to the worker that
+// generated them, since with parallel workers they're otherwise difficult to
+// tell apart.
+func parseLog(solveLog string) []logLine {
+	var lines []logLine
+	inSummary := false
+	for _, text := range strings.Split(solveLog, "\n") {
+		if strings.HasPrefix(text, summaryHeader) {
+			inSummary = true
+		}
+
+		line := logLine{level: LogLevelDebug, text: text}
+		switch {
+		case inSummary:
+			line.level = LogLevelSummary
+		case isProgressLine(text):
+			line.level = LogLevelInfo
+			line.worker = logWorker(text)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// emitLog writes out the given solve log to the logger, dropping the lines
+// below the given level. Each line is written out atomically (log.Logger
+// serializes writes), so the logs of concurrent solves sharing an io.Writer
+// don't interleave mid-line. Lines attributed to a worker are prefixed with
+// its name.
+func emitLog(logger *log.Logger, solveLog string, filter LogLevel) {
+	for _, line := range parseLog(solveLog) {
+		if line.level < filter {
+			continue
+		}
+		if line.worker != "" {
+			logger.Printf("[%s] %s", line.worker, line.text)
+			continue
+		}
+		logger.Print(line.text)
+	}
+}
+
+// isProgressLine returns true iff the given line is a search progress line,
+// one that looks like the following:
+//
+//   #1       0.01s best:30    next:[6,29]     core fixed_bools:0/11
+//
+// Other lines start with '#' too (#Variables: ..., for example), but only
+// progress lines are followed by a timestamp.
+func isProgressLine(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "#") || !strings.HasSuffix(fields[1], "s") {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "s"), 64)
+	return err == nil
+}
+
+// logWorker extracts the name of the worker from a search progress line, which
+// looks like one of the following:
+//
+//   #1       0.01s best:30    next:[6,29]     core fixed_bools:0/11
+//   #Bound   0.02s best:29    next:[8,28]     max_lp initial_propagation
+//   #Done    0.02s max_lp
+//
+// It returns the empty string if there's no worker name to be found.
+func logWorker(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ""
+	}
+	for _, field := range fields[2:] {
+		if !strings.Contains(field, ":") {
+			return field
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package solver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/irfansharif/solver/internal"
+)
+
+// WithLogHandler configures the solver to route its internal logging to the
+// given slog.Handler, as an alternative to WithLogger. Each line of the log is
+// emitted as its own record, at the level it was classified as (the final
+// summary is emitted at slog.LevelInfo). Search progress lines carry a
+// "worker" attribute naming the worker that generated them. What's logged can
+// be filtered using WithLogFilter or WithLogLevel, or by the handler itself.
+func WithLogHandler(h slog.Handler) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.enableLogging()
+		o.handler = slogHandler(h)
+	}
+}
+
+// slogHandler adapts the given slog.Handler to receive lines from the solve
+// log.
+func slogHandler(h slog.Handler) logHandler {
+	ctx := context.Background()
+	return func(line logLine) {
+		if line.text == "" {
+			return
+		}
+		level := slogLevel(line.level)
+		if !h.Enabled(ctx, level) {
+			return
+		}
+		record := slog.NewRecord(time.Now(), level, line.text, 0)
+		if line.worker != "" {
+			record.AddAttrs(slog.String("worker", line.worker))
+		}
+		_ = h.Handle(ctx, record)
+	}
+}
+
+// slogLevel maps the given log level to its slog equivalent.
+func slogLevel(l LogLevel) slog.Level {
+	if l == LogLevelDebug {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
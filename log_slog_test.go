@@ -0,0 +1,51 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package solver
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var b strings.Builder
+	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	handle := slogHandler(h)
+	for _, line := range parseLog(testSolveLog) {
+		handle(line)
+	}
+	require.Equal(t, strings.Join([]string{
+		`level=INFO msg="#1       0.01s best:30    next:[6,29]     core fixed_bools:0/11" worker=core`,
+		`level=INFO msg="#Bound   0.02s best:29    next:[8,28]     max_lp initial_propagation" worker=max_lp`,
+		`level=INFO msg="#Done    0.02s max_lp" worker=max_lp`,
+		`level=INFO msg="CpSolverResponse summary:"`,
+		`level=INFO msg="status: OPTIMAL"`,
+	}, "\n")+"\n", b.String())
+}
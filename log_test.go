@@ -0,0 +1,72 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSolveLog = `Starting CP-SAT solver v9.1.9490
+Parameters: log_search_progress: true num_search_workers: 4
+
+Initial optimization model '':
+#Variables: 3 (3 in objective)
+#1       0.01s best:30    next:[6,29]     core fixed_bools:0/11
+#Bound   0.02s best:29    next:[8,28]     max_lp initial_propagation
+#Done    0.02s max_lp
+CpSolverResponse summary:
+status: OPTIMAL`
+
+func TestEmitLog(t *testing.T) {
+	for _, tc := range []struct {
+		filter   LogLevel
+		expected []string
+	}{
+		{
+			filter: LogLevelQuiet,
+		},
+		{
+			filter: LogLevelSummary,
+			expected: []string{
+				"CpSolverResponse summary:",
+				"status: OPTIMAL",
+			},
+		},
+		{
+			filter: LogLevelInfo,
+			expected: []string{
+				"[core] #1       0.01s best:30    next:[6,29]     core fixed_bools:0/11",
+				"[max_lp] #Bound   0.02s best:29    next:[8,28]     max_lp initial_propagation",
+				"[max_lp] #Done    0.02s max_lp",
+				"CpSolverResponse summary:",
+				"status: OPTIMAL",
+			},
+		},
+	} {
+		t.Run(tc.filter.String(), func(t *testing.T) {
+			var b strings.Builder
+			emitLog(log.New(&b, "", 0), testSolveLog, tc.filter)
+			if len(tc.expected) == 0 {
+				require.Empty(t, b.String())
+				return
+			}
+			require.Equal(t, strings.Join(tc.expected, "\n")+"\n", b.String())
+		})
+	}
+}
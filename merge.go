@@ -0,0 +1,212 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Merged maps the variables, literals and intervals of a model merged into
+// another (see Model.Merge), or cloned (see Model.CloneWithMapping), to their
+// counterparts in the resulting model.
+type Merged struct {
+	from      *Model
+	vars      []*intVar
+	intervals map[int32]Interval
+}
+
+// Merge imports everything but the objective of the other model into this one,
+// prefixing the names therein with the given prefix. The other model's
+// variables are renumbered, and are to be referred to through the returned
+// Merged.
+func (m *Model) Merge(other *Model, prefix string) *Merged {
+	m.assertMutable()
+	src := other.finalized()
+
+	varOffset := int32(len(m.pb.GetVariables()))
+	ctOffset := int32(len(m.pb.GetConstraints()))
+	prefixed := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		return prefix + name
+	}
+	remap := func(ref int32) int32 {
+		if ref < 0 {
+			return ref - varOffset
+		}
+		return ref + varOffset
+	}
+
+	mg := &Merged{from: other, intervals: make(map[int32]Interval)}
+	for _, v := range src.variables() {
+		iv := *v.(*intVar)
+		iv.pb = proto.Clone(iv.pb).(*pb.IntegerVariableProto)
+		iv.pb.Name = prefixed(iv.pb.Name)
+		iv.idx += varOffset
+		iv.negation = nil
+		iv.model = m
+		m.pb.Variables = append(m.pb.Variables, iv.pb)
+		m.trackVariable(iv.idx)
+		mg.vars = append(mg.vars, &iv)
+
+		if !m.introspect() {
+			continue
+		}
+		switch {
+		case iv.isLiteral:
+			m.literals = append(m.literals, &iv)
+		case iv.isConst:
+			m.constants = append(m.constants, &iv)
+		default:
+			m.vars = append(m.vars, &iv)
+		}
+	}
+	// Variables the other model doesn't track (instantiated through a
+	// ModelBuilder, say) are reconstructed as integer variables; the ones with
+	// domain [0, 1] can still be used as literals.
+	for _, iv := range mg.vars {
+		ls := iv.d.list(0)
+		if !iv.isConst && len(ls) == 2 && ls[0] == 0 && ls[1] == 1 {
+			iv.isLiteral = true
+		}
+	}
+
+	// ref returns the merged variable for the given (remapped) reference.
+	ref := func(r int32) *intVar {
+		if r < 0 {
+			return mg.vars[-r-1-varOffset].Not().(*intVar)
+		}
+		return mg.vars[r-varOffset]
+	}
+	lo := len(m.pb.GetConstraints())
+	for i, ct := range src.pb.GetConstraints() {
+		ct = proto.Clone(ct).(*pb.ConstraintProto)
+		ct.Name = prefixed(ct.Name)
+		remapReferences(ct, remap)
+		switch c := ct.GetConstraint().(type) {
+		case *pb.ConstraintProto_NoOverlap:
+			offsetIntervals(c.NoOverlap.Intervals, ctOffset)
+		case *pb.ConstraintProto_NoOverlap_2D:
+			offsetIntervals(c.NoOverlap_2D.XIntervals, ctOffset)
+			offsetIntervals(c.NoOverlap_2D.YIntervals, ctOffset)
+		case *pb.ConstraintProto_Cumulative:
+			offsetIntervals(c.Cumulative.Intervals, ctOffset)
+		}
+		m.pb.Constraints = append(m.pb.Constraints, ct)
+
+		if arg := ct.GetInterval(); arg != nil {
+			itv := &interval{
+				pb:    ct,
+				idx:   ctOffset + int32(i),
+				start: ref(arg.GetStart()), end: ref(arg.GetEnd()), size: ref(arg.GetSize()),
+			}
+			if lits := ct.GetEnforcementLiteral(); len(lits) == 1 {
+				itv.enforcement = ref(lits[0])
+			}
+			mg.intervals[int32(i)] = itv
+			if m.introspect() {
+				m.intervals = append(m.intervals, itv)
+			}
+			continue
+		}
+		if m.introspect() {
+			m.constraints = append(m.constraints, &constraint{pb: ct, str: loadedDescription(ct, ref)})
+			if m.callers != nil {
+				m.callers.added = append(m.callers.added, caller())
+			}
+		}
+	}
+	m.trackConstraints(lo, len(m.pb.GetConstraints()))
+	for idx, priority := range src.priorities {
+		if m.priorities == nil {
+			m.priorities = make(map[int]int)
+		}
+		m.priorities[idx+int(ctOffset)] = priority
+	}
+
+	if hint := src.pb.GetSolutionHint(); hint != nil {
+		for i, idx := range hint.GetVars() {
+			m.AddHint(mg.vars[idx], hint.GetValues()[i])
+		}
+	}
+	for _, r := range src.pb.GetAssumptions() {
+		m.pb.Assumptions = append(m.pb.Assumptions, remap(r))
+		m.assumptions = append(m.assumptions, ref(remap(r)))
+	}
+	for _, s := range src.pb.GetSearchStrategy() {
+		s = proto.Clone(s).(*pb.DecisionStrategyProto)
+		for i, r := range s.Variables {
+			s.Variables[i] = remap(r)
+		}
+		m.pb.SearchStrategy = append(m.pb.SearchStrategy, s)
+	}
+	return mg
+}
+
+// Var returns the variable in the merged model corresponding to the given one
+// from the model merged in. Variables that aren't from the model merged in are
+// returned as is.
+func (mg *Merged) Var(v IntVar) IntVar {
+	iv, ok := v.(*intVar)
+	if !ok || iv.model != mg.from {
+		return v
+	}
+	if iv.isNegated() {
+		return mg.vars[-iv.idx-1].Not()
+	}
+	return mg.vars[iv.idx]
+}
+
+// Literal is like Var, for literals.
+func (mg *Merged) Literal(l Literal) Literal {
+	return mg.Var(l).(Literal)
+}
+
+// Interval is like Var, for intervals.
+func (mg *Merged) Interval(i Interval) Interval {
+	itv, ok := i.(*interval)
+	if !ok {
+		return i
+	}
+	if merged, ok := mg.intervals[itv.idx]; ok && mg.from.pb.GetConstraints()[itv.idx] == itv.pb {
+		return merged
+	}
+	return i
+}
+
+// Expr returns the linear expression in the merged model corresponding to the
+// given one, defined over variables from the model merged in (its objective,
+// say).
+func (mg *Merged) Expr(e LinearExpr) LinearExpr {
+	var vars []IntVar
+	for _, r := range e.vars() {
+		if r < 0 {
+			vars = append(vars, mg.vars[-r-1].Not())
+		} else {
+			vars = append(vars, mg.vars[r])
+		}
+	}
+	return NewLinearExpr(vars, e.coeffs(), e.offset())
+}
+
+// offsetIntervals shifts, in place, the given interval references by the
+// given offset.
+func offsetIntervals(refs []int32, offset int32) {
+	for i := range refs {
+		refs[i] += offset
+	}
+}
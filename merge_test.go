@@ -0,0 +1,87 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	newMachine := func() (*Model, IntVar, Interval) {
+		sub := NewModel("machine")
+		start := sub.NewIntVar(0, 10, "start")
+		a := sub.NewFixedSizeInterval(start, 3, "a")
+		b := sub.NewFixedSizeInterval(sub.NewIntVar(0, 10, "other"), 2, "b")
+		sub.AddConstraints(NewNonOverlappingConstraint(a, b))
+		sub.AddHint(start, 4)
+		sub.Minimize(Sum(start))
+		return sub, start, a
+	}
+
+	model := NewModel("plant")
+	x := model.NewIntVar(0, 10, "x")
+	m1, s1, a1 := newMachine()
+	m2, s2, _ := newMachine()
+	merged1 := model.Merge(m1, "m1.")
+	merged2 := model.Merge(m2, "m2.")
+
+	require.Len(t, model.pb.GetVariables(), 1+2*len(m1.pb.GetVariables()))
+	require.Len(t, model.pb.GetConstraints(), 2*len(m1.pb.GetConstraints()))
+	require.Equal(t, "m1.start in [0, 10]", merged1.Var(s1).String())
+	require.Equal(t, "m2.start in [0, 10]", merged2.Var(s2).String())
+	require.Equal(t, x, merged1.Var(x))
+	require.Equal(t, "m1.a", merged1.Interval(a1).(*interval).pb.GetName())
+	require.Equal(t, []int32{3, 4}, model.pb.GetConstraints()[5].GetNoOverlap().GetIntervals())
+	require.Contains(t, model.String(), "m2.start")
+
+	// The sub-models are left as is.
+	require.Len(t, m1.pb.GetVariables(), len(m2.pb.GetVariables()))
+	require.Equal(t, "start", m1.pb.GetVariables()[s1.index()].GetName())
+
+	// Constraints can tie the merged sub-models together, and the objectives
+	// are carried over explicitly.
+	model.AddConstraints(NewLinearConstraint(Sum(merged1.Var(s1), merged2.Var(s2)), NewDomain(15, 15)))
+	e, _, ok := m1.Objective()
+	require.True(t, ok)
+	model.Minimize(merged1.Expr(e))
+	require.Equal(t, []int32{merged1.Var(s1).index()}, model.pb.GetObjective().GetVars())
+	require.Equal(t, []int32{merged1.Var(s1).index(), merged2.Var(s2).index()}, model.pb.GetSolutionHint().GetVars())
+}
+
+func TestMergeBookkeeping(t *testing.T) {
+	sub := NewModel("")
+	x := sub.NewIntVar(0, 10, "x")
+	machine := sub.AddDisjunctiveResource("machine")
+	machine.Register(sub.NewFixedSizeInterval(x, 2, "a"), nil)
+	sub.AddConstraintsWithPriority(3, NewLinearConstraint(Sum(x), NewDomain(0, 5)))
+
+	model := NewModel("", WithCallerTracking())
+	model.AddConstraints(NewLinearConstraint(Sum(model.NewIntVar(0, 1, "y")), NewDomain(1, 1)))
+	mg := model.Merge(sub, "sub.")
+
+	// The other model's resources are merged in, without finalizing it.
+	require.Len(t, sub.pb.GetConstraints(), 2)
+	require.Len(t, model.pb.GetConstraints(), 4)
+	require.Equal(t, "sub.machine", model.pb.GetConstraints()[3].GetName())
+	machine.Register(sub.NewFixedSizeInterval(x, 3, "b"), nil)
+
+	// Priorities carry over, and constraints are attributed to the merge.
+	require.Equal(t, map[int]int{2: 3}, model.priorities)
+	require.Len(t, model.callers.added, len(model.constraints))
+	require.Contains(t, model.String(), "merge_test.go")
+	require.Equal(t, "sub.x", mg.Var(x).name())
+}
@@ -17,13 +17,17 @@ package solver
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/irfansharif/solver/internal"
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
 )
 
-// Model is a constraint programming problem. It's not safe for concurrent use.
+// Model is a constraint programming problem. It's not safe for concurrent use;
+// see Model.Concurrent for constructing models from multiple goroutines.
 type Model struct {
 	pb *pb.CpModelProto
 
@@ -34,40 +38,104 @@ type Model struct {
 	constraints     []Constraint
 	objective       LinearExpr
 	minimize        bool
+	coverage        *coverage // set iff the objective is a coverage one
+	objectiveScale  int64     // set iff the objective is a fractional one
+	soft            *softConstraints
+	assumptions     []Literal
+	priorities      map[int]int // constraint index => priority, if tagged
+	handles         []*ConstraintHandle
+
+	// deferred holds constructs (resources, for example) that emit their
+	// protos only once the full model is known; see Model.Finalize. They're
+	// handed the model to emit them into, this one or a clone of it.
+	deferred []func(*Model)
+	frozen   bool
+
+	// errs holds the errors encountered when constructing the model, if
+	// configured to accumulate them; see WithConstructionErrors.
+	errs             constructionErrors
+	accumulateErrors bool
+
+	degeneratePolicy DegeneratePolicy
+	// normalized maps the degenerate constraint protos normalized on their
+	// way into the model to the copies added in their place; see
+	// NormalizeDegenerate.
+	normalized map[*pb.ConstraintProto]*pb.ConstraintProto
+
+	// withoutIntrospection is set if the model doesn't hold onto the Go-side
+	// representations of what it's made up of; see WithoutIntrospection.
+	withoutIntrospection bool
+
+	// callers is set if the model records where its variables and constraints
+	// were added from; see WithCallerTracking.
+	callers *callers
+
+	// concurrent is set once the model is used concurrently; see
+	// Model.Concurrent.
+	concurrent *ConcurrentModel
+
+	arena *protoArena
+}
+
+// ModelOption is used to configure a model at instantiation time.
+type ModelOption func(m *Model)
+
+// WithoutIntrospection configures the model to not hold onto the variables,
+// literals, constants, intervals and constraints added to it, only the
+// underlying protos. The Go-side representations are still constructed (and
+// returned to the caller), descriptions included, but they can be garbage
+// collected once the caller is done with them. It makes for less memory
+// retained when building many (or large) models, at the cost of
+// introspection: the model's string representation only summarizes it.
+// Results still refer to variables by index, so they're unaffected; variables
+// surfaced by results (see Result.FixedVariables) are reconstructed from the
+// protos instead.
+func WithoutIntrospection() ModelOption {
+	return func(m *Model) {
+		m.withoutIntrospection = true
+	}
 }
 
 // TODO(irfansharif): Add assumption literals and examples for unsat debugging.
-// And add hints. Add some documentation from
+// Add some documentation from
 // https://github.com/google/or-tools/blob/stable/ortools/sat/doc/boolean_logic.md
 // (reification, channeling constraints). Export async handler to stop search
 // process. Probably part of enumerator?
-// TODO(irfansharif): Export solution hint/partial assignment, when previous one
-// is available.
-// TODO(irfansharif): Export model/result statistics. Export domain.complement
-// for reification.
+// TODO(irfansharif): Export model/result statistics. Export domain.complement.
 // TODO(irfansharif): Export verbose view of types (specifically -- include
 // internal indexes, so you could debug the validation error).
+// TODO(irfansharif): Surface unsat proofs (DRAT, say) so infeasibility claims
+// can be independently checked. The bundled version of OR-Tools can't produce
+// them; there are no proof logging parameters to configure.
 
 // NewModel instantiates a new model.
-func NewModel(name string) *Model {
-	return &Model{
+func NewModel(name string, opts ...ModelOption) *Model {
+	m := &Model{
 		pb: &pb.CpModelProto{
 			Name: name,
 		},
 	}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
 }
 
 // NewLiteral adds a new literal to the model.
 func (m *Model) NewLiteral(name string) Literal {
 	literal := m.newIntVarFromDomainInternal(NewDomain(0, 1), true, false, name).(Literal)
-	m.literals = append(m.literals, literal)
+	if m.introspect() {
+		m.literals = append(m.literals, literal)
+	}
 	return literal
 }
 
 // NewConstant adds a new constant to the model.
 func (m *Model) NewConstant(c int64, name string) IntVar {
 	constant := m.newIntVarFromDomainInternal(NewDomain(c, c), false, true, name)
-	m.constants = append(m.constants, constant)
+	if m.introspect() {
+		m.constants = append(m.constants, constant)
+	}
 	return constant
 }
 
@@ -81,7 +149,9 @@ func (m *Model) NewIntVar(lb int64, ub int64, name string) IntVar {
 // constrained to the given domain.
 func (m *Model) NewIntVarFromDomain(d Domain, name string) IntVar {
 	iv := m.newIntVarFromDomainInternal(d, false, false, name)
-	m.vars = append(m.vars, iv)
+	if m.introspect() {
+		m.vars = append(m.vars, iv)
+	}
 	return iv
 }
 
@@ -91,76 +161,342 @@ func (m *Model) NewInterval(start, end, size IntVar, name string) Interval {
 	idx := len(m.pb.GetConstraints())
 	itv := newInterval(start, end, size, int32(idx), name)
 	m.addConstraintsInternal(itv)
-	m.intervals = append(m.intervals, itv)
+	if m.introspect() {
+		m.intervals = append(m.intervals, itv)
+	}
 	return itv
 }
 
+// NewFixedSizeInterval adds a new interval to the model, one that's defined
+// using the given start and fixed size. The constant size and the end variable
+// (its domain being that of the start's, shifted by the size) are instantiated
+// internally. Sizes must be non-negative.
+func (m *Model) NewFixedSizeInterval(start IntVar, size int64, name string) Interval {
+	if size < 0 {
+		m.fail("interval %s: negative size (%d)", quoteName(name), size)
+		size = 0
+	}
+	end := m.NewIntVarFromDomain(&domain{intervals: start.domain().list(-size)}, derivedName(name, "end"))
+	return m.NewInterval(start, end, m.NewConstant(size, derivedName(name, "size")), name)
+}
+
+// NewFixedInterval adds a new interval to the model, one that spans the given
+// (fixed) start and end. The constants making it up are instantiated
+// internally.
+func (m *Model) NewFixedInterval(start, end int64, name string) Interval {
+	if end < start {
+		m.fail("interval %s: end (%d) precedes start (%d)", quoteName(name), end, start)
+		end = start
+	}
+	return m.NewInterval(
+		m.NewConstant(start, derivedName(name, "start")),
+		m.NewConstant(end, derivedName(name, "end")),
+		m.NewConstant(end-start, derivedName(name, "size")),
+		name,
+	)
+}
+
+// AddAlternative ensures that exactly one of the given intervals is scheduled,
+// returning the literals indicating whether each one is (in the same order).
+// It's the building block for optional resources: a task that can be performed
+// in one of many modes (on one of many machines, say) is modeled using one
+// interval per mode, of which exactly one is realized.
+//
+// Intervals already enforced using a literal (see Interval.OnlyEnforceIf) are
+// scheduled iff that literal is true; the rest are made optional using newly
+// created literals.
+func (m *Model) AddAlternative(intervals []Interval) (presences []Literal) {
+	for _, itv := range intervals {
+		i := itv.(*interval)
+		if i.enforcement == nil {
+			i.OnlyEnforceIf(m.NewLiteral(derivedName(i.pb.GetName(), "present")))
+		}
+		presences = append(presences, i.enforcement)
+	}
+	m.AddConstraints(NewExactlyKConstraint(1, presences...))
+	return presences
+}
+
 // AddConstraints adds constraints to the model. When deciding on a solution,
 // these constraints will need to be satisfied.
 func (m *Model) AddConstraints(cs ...Constraint) {
 	m.addConstraintsInternal(cs...)
-	m.constraints = append(m.constraints, cs...)
+	if m.introspect() {
+		m.constraints = append(m.constraints, cs...)
+		if m.callers != nil {
+			loc := caller()
+			for range cs {
+				m.callers.added = append(m.callers.added, loc)
+			}
+		}
+	}
 }
 
 // Minimize sets a minimization objective for the model.
 func (m *Model) Minimize(e LinearExpr) {
+	m.assertMutable()
 	m.pb.Objective = m.toObjectiveProto(e)
 	m.objective, m.minimize = e, true
+	m.coverage = nil
+	m.objectiveScale = 0
 }
 
 // Maximize sets a maximization objective for the model.
 func (m *Model) Maximize(e LinearExpr) {
-	// For maximization objectives, we want to negate all the coefficients and
-	// set the scaling factor to -1.
-	proto := m.toObjectiveProto(e)
-	for i, coeff := range proto.Coeffs {
-		proto.Coeffs[i] = -coeff
+	m.assertMutable()
+	m.pb.Objective = m.toMaximizationProto(e)
+	m.objective, m.minimize = e, false
+	m.coverage = nil
+	m.objectiveScale = 0
+}
+
+// MaximizeCoverage sets an objective maximizing the total weight of the given
+// literals that are true, for "cover as many requests as possible" problems.
+// The weights are retained for reporting; see Result.Coverage.
+func (m *Model) MaximizeCoverage(literals []Literal, weights []int64) {
+	if len(literals) != len(weights) {
+		m.fail("coverage objective: mismatched number of literals (%d: %s) and weights (%d)",
+			len(literals), asIntVars(literals).names(), len(weights))
+		return
 	}
-	proto.Offset = -proto.Offset
-	proto.ScalingFactor = -1
-	m.pb.Objective = proto
 
-	m.objective, m.minimize = e, false
+	m.Maximize(NewLinearExpr(AsIntVars(literals), weights, 0))
+	m.coverage = &coverage{ // copied, since results retain them
+		literals: append([]Literal(nil), literals...),
+		weights:  append([]int64(nil), weights...),
+	}
 }
 
-// Validate checks whether the model is valid. If not, a descriptive error
-// message is returned.
+// MaximizeLiterals sets an objective maximizing the total weight of the given
+// literals that are true. Negated literals are expressed in terms of the
+// literals they negate.
+func (m *Model) MaximizeLiterals(weights map[Literal]int64) {
+	m.Maximize(literalObjective(weights))
+}
+
+// MinimizeLiterals sets an objective minimizing the total weight of the given
+// literals that are true. Negated literals are expressed in terms of the
+// literals they negate.
+func (m *Model) MinimizeLiterals(weights map[Literal]int64) {
+	m.Minimize(literalObjective(weights))
+}
+
+// MinimizeMax sets an objective minimizing the maximum of the given
+// expressions (the makespan of a schedule, or the load of the busiest
+// machine), returning the variable the maximum is captured in.
+func (m *Model) MinimizeMax(exprs ...LinearExpr) IntVar {
+	target := m.newExtremum("minimize-max", true, exprs)
+	if target == nil {
+		return nil
+	}
+	m.AddConstraints(NewLinearMaximumConstraint(Sum(target), exprs...))
+	m.Minimize(Sum(target))
+	return target
+}
+
+// MaximizeMin sets an objective maximizing the minimum of the given
+// expressions (the load of the least loaded machine, say), returning the
+// variable the minimum is captured in.
+func (m *Model) MaximizeMin(exprs ...LinearExpr) IntVar {
+	target := m.newExtremum("maximize-min", false, exprs)
+	if target == nil {
+		return nil
+	}
+	m.AddConstraints(NewLinearMinimumConstraint(Sum(target), exprs...))
+	m.Maximize(Sum(target))
+	return target
+}
+
+// newExtremum instantiates the variable capturing the maximum (or minimum) of
+// the given expressions, its domain spanning the possible values thereof. It's
+// left unnamed, as there's no name to derive one from.
+func (m *Model) newExtremum(objective string, max bool, exprs []LinearExpr) IntVar {
+	if len(exprs) == 0 {
+		m.fail("%s objective: no expressions", objective)
+		return nil
+	}
+
+	lb, ub := linearBounds(exprs[0])
+	for _, e := range exprs[1:] {
+		elb, eub := linearBounds(e)
+		switch {
+		case max && elb > lb, !max && elb < lb:
+			lb = elb
+		}
+		switch {
+		case max && eub > ub, !max && eub < ub:
+			ub = eub
+		}
+	}
+	return m.NewIntVar(lb, ub, "")
+}
+
+// literalObjective returns the linear expression for the total weight of the
+// given literals that are true. The weight w of a negated literal ~l is
+// rewritten as w - w*l, and weights of the same underlying literal are
+// combined. Terms are ordered by variable index, for determinism.
+func literalObjective(weights map[Literal]int64) LinearExpr {
+	var offset int64
+	combined := make(map[int32]int64)
+	literals := make(map[int32]Literal)
+	for l, w := range weights {
+		if l.isNegated() {
+			offset += w
+			w = -w
+			l = l.Not()
+		}
+		combined[l.index()] += w
+		literals[l.index()] = l
+	}
+
+	indexes := make([]int32, 0, len(literals))
+	for idx := range literals {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	var vars []IntVar
+	var coeffs []int64
+	for _, idx := range indexes {
+		vars = append(vars, literals[idx])
+		coeffs = append(coeffs, combined[idx])
+	}
+	return NewLinearExpr(vars, coeffs, offset)
+}
+
+// Objective returns the model's objective, if set, and whether it's being
+// maximized.
+func (m *Model) Objective() (e LinearExpr, maximize bool, ok bool) {
+	if m.objective == nil {
+		return nil, false, false
+	}
+	return m.objective, !m.minimize, true
+}
+
+// ClearObjective removes the model's objective, if any, turning it into a pure
+// feasibility problem.
+func (m *Model) ClearObjective() {
+	m.assertMutable()
+	m.pb.Objective = nil
+	m.objective, m.minimize = nil, false
+	m.coverage = nil
+	m.objectiveScale = 0
+}
+
+// AddHint hints to the solver that the given variable should take on the
+// given value. Hints are used as a starting point for the search, and needn't
+// be complete (or even feasible).
+func (m *Model) AddHint(iv IntVar, value int64) {
+	if m.pb.SolutionHint == nil {
+		m.pb.SolutionHint = &pb.PartialVariableAssignment{}
+	}
+
+	idx := iv.index()
+	if idx < 0 {
+		// Negated literals are hinted using the literal they negate.
+		idx, value = -idx-1, 1-value
+	}
+	m.pb.SolutionHint.Vars = append(m.pb.SolutionHint.Vars, idx)
+	m.pb.SolutionHint.Values = append(m.pb.SolutionHint.Values, value)
+}
+
+// Finalize emits the protos for all deferred constructs (resources, for
+// example) and freezes the model. Deferred constructs need the full model to
+// be known, so they're only emitted once; after finalizing, the model can no
+// longer be changed (with the exception of hints) and attempts to do so panic.
 //
-// TODO(irfansharif): This validation message refers to things using indexes,
-// which is not really usable.
+// Finalizing is optional. Models are implicitly finalized when solved, but
+// doing so only emits the pending deferred constructs without freezing the
+// model. Validating, exporting, or otherwise inspecting the model emits them
+// into a copy instead, leaving the model as is.
+func (m *Model) Finalize() {
+	m.finalize()
+	m.frozen = true
+}
+
+// Finalized returns whether the model was finalized, see Model.Finalize.
+func (m *Model) Finalized() bool {
+	return m.frozen
+}
+
+// Validate checks whether the model is valid. If not, a descriptive error
+// message is returned. The underlying solver refers to variables and
+// constraints by index; they're annotated with their names (or descriptions,
+// for constraints) in the message.
 func (m *Model) Validate() (ok bool, _ error) {
-	validation := internal.CpSatHelperValidateModel(*m.pb)
+	if err := m.Err(); err != nil {
+		return false, err
+	}
+	f := m.finalized()
+	validation := internal.CpSatHelperValidateModel(*f.pb)
 	if validation == "" {
 		return true, nil
 	}
 
-	return false, errors.New(validation)
+	return false, errors.New(f.annotate(f.describe(validation)))
+}
+
+// ProtoSize returns the size, in bytes, of the serialized model. This is what's
+// handed off to the underlying solver.
+func (m *Model) ProtoSize() int {
+	return proto.Size(m.finalized().pb)
+}
+
+// EstimatedMemory returns a rough estimate, in bytes, of the memory the
+// underlying solver will need to solve the model with a single search worker.
+// It can be used to refuse solving models that will obviously exceed available
+// memory. Each additional worker requires (roughly) as much memory again.
+//
+// NB: The estimate is a heuristic derived from the size of the model; the
+// actual usage depends heavily on the structure of the model, the search
+// parameters, and the amount of presolve that happens.
+func (m *Model) EstimatedMemory() int64 {
+	const (
+		// The solver holds onto multiple copies of the model (the original,
+		// the presolved one, the mapping model), each in an expanded in-memory
+		// representation.
+		protoMultiplier = 8
+		// Per variable search state: domains, watchers, trail, etc.
+		bytesPerVariable = 512
+		// Per constraint propagator state.
+		bytesPerConstraint = 256
+	)
+
+	f := m.finalized()
+	return int64(proto.Size(f.pb))*protoMultiplier +
+		int64(len(f.pb.GetVariables()))*bytesPerVariable +
+		int64(len(f.pb.GetConstraints()))*bytesPerConstraint
 }
 
 // String provides a string representation of the model.
 func (m *Model) String() string {
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("model=%s\n", m.name()))
+	if !m.introspect() {
+		b.WriteString(fmt.Sprintf("  variables (num = %d)\n", len(m.pb.GetVariables())))
+		b.WriteString(fmt.Sprintf("  constraints (num = %d)\n", len(m.pb.GetConstraints())))
+		b.WriteString("  (built without introspection)\n")
+		return b.String()
+	}
 
 	for i, v := range m.vars {
 		if i == 0 {
 			b.WriteString(fmt.Sprintf("  variables (num = %d)\n", len(m.vars)))
 		}
-		b.WriteString(fmt.Sprintf("    %s\n", v.String()))
+		b.WriteString(fmt.Sprintf("    %s%s\n", v.String(), m.variableLocation(v.index())))
 	}
 
 	for i, c := range m.constants {
 		if i == 0 {
 			b.WriteString(fmt.Sprintf("  constants (num = %d)\n", len(m.constants)))
 		}
-		b.WriteString(fmt.Sprintf("    %s\n", c.String()))
+		b.WriteString(fmt.Sprintf("    %s%s\n", c.String(), m.variableLocation(c.index())))
 	}
 
 	for i, l := range m.literals {
 		if i == 0 {
 			b.WriteString(fmt.Sprintf("  literals (num = %d)\n", len(m.literals)))
 		}
-		b.WriteString(fmt.Sprintf("    %s\n", l.String()))
+		b.WriteString(fmt.Sprintf("    %s%s\n", l.String(), m.variableLocation(l.index())))
 	}
 
 	for i, iv := range m.intervals {
@@ -170,11 +506,22 @@ func (m *Model) String() string {
 		b.WriteString(fmt.Sprintf("    %s\n", iv.String()))
 	}
 
+	// Constraints disabled through their handles aren't printed.
+	var cs []int
 	for i, c := range m.constraints {
-		if i == 0 {
-			b.WriteString(fmt.Sprintf("  constraints (num = %d)\n", len(m.constraints)))
+		if !m.disabled(c) {
+			cs = append(cs, i)
 		}
-		b.WriteString(fmt.Sprintf("    %s\n", c.String()))
+	}
+	for j, i := range cs {
+		if j == 0 {
+			b.WriteString(fmt.Sprintf("  constraints (num = %d)\n", len(cs)))
+		}
+		var loc string
+		if m.callers != nil {
+			loc = fmt.Sprintf(" [%s]", m.callers.added[i])
+		}
+		b.WriteString(fmt.Sprintf("    %s%s\n", m.constraints[i].String(), loc))
 	}
 
 	if o := m.objective; o != nil {
@@ -193,8 +540,18 @@ func (m *Model) String() string {
 // optimal result if an objective function is declared. If not, it returns
 // the first found result that satisfies the model.
 //
-// The solve process itself can be configured with various options.
+// The solve process itself can be configured with various options. If the
+// options are incompatible with one another (or with the model), the model
+// isn't solved; the result is Invalid() and Err() describes why.
 func (m *Model) Solve(os ...Option) Result {
+	m.finalize()
+	return m.solve(m.pb, os...)
+}
+
+// solve is like Solve, except it solves the given model proto (some variant
+// of the model's own) instead.
+func (m *Model) solve(model *pb.CpModelProto, os ...Option) Result {
+	start := time.Now()
 	solver := internal.NewSolveWrapper()
 	defer func() { internal.DeleteSolveWrapper(solver) }()
 
@@ -202,49 +559,358 @@ func (m *Model) Solve(os ...Option) Result {
 	for _, o := range os {
 		o(&opts, solver)
 	}
-	if ok, err := opts.validate(); !ok {
-		panic(err)
-	}
+	opts.setLogParams()
 	if opts.solution != nil {
+		opts.solution.objectiveScale = m.objectiveScale
 		defer func() { internal.DeleteDirectorSolutionCallback(opts.solution.hook) }()
 	}
+	if opts.handle != nil {
+		opts.handle.callback.objectiveScale = m.objectiveScale
+		opts.handle.attach(solver)
+		defer opts.handle.detach()
+	}
+	if opts.accountant != nil {
+		opts.accountant.callback.objectiveScale = m.objectiveScale
+		defer opts.accountant.start(solver)()
+	}
+	if ok, err := opts.validate(m); !ok {
+		return Result{
+			pb:  &pb.CpSolverResponse{Status: pb.CpSolverStatus_MODEL_INVALID},
+			err: err,
+		}
+	}
 
+	if opts.hintOnly {
+		model = withHintsFixed(model)
+	}
+	if opts.cutoff != nil {
+		model = withObjectiveCutoff(model, opts.cutoff.value)
+	}
+	if opts.order != nil {
+		model = withVariableOrder(model, opts.order)
+	}
 	solver.SetParameters(opts.params)
-	resp := solver.Solve(*m.pb)
+	var resp pb.CpSolverResponse
+	var native time.Duration
+	key, cached := opts.cache.lookup(model, &opts, &resp)
+	if !cached {
+		native = runNative(opts.profiling, model, func() { resp = solver.Solve(*model) })
+		if opts.canonical && resp.Status == pb.CpSolverStatus_OPTIMAL {
+			start := time.Now()
+			b := &budget{params: &opts.params, start: start}
+			b.spend(&resp)
+			resp.Solution = m.canonicalize(solver, b, model, resp.Solution)
+			native += time.Since(start) // dominated by the native solves within
+		}
+		opts.cache.record(key, model, &resp)
+	}
 
 	if opts.logger != nil {
-		for _, line := range strings.Split(resp.SolveLog, "\n") {
-			opts.logger.Print(line)
+		emitLog(opts.logger, resp.SolveLog, opts.logFilter)
+	}
+	if opts.handler != nil {
+		for _, line := range parseLog(resp.SolveLog) {
+			if line.level >= opts.logFilter {
+				opts.handler(line)
+			}
 		}
 	}
-	return Result{pb: &resp}
+	result := Result{pb: &resp, coverage: m.coverage, assumptions: m.assumptions, objectiveScale: m.objectiveScale, cached: cached}
+	if opts.params.GetFillTightenedDomainsInResponse() {
+		result.vars = m.variables()
+	}
+	if opts.solution != nil {
+		result.solutions = opts.solution.solutions
+	} else if result.solved() {
+		result.solutions = 1 // the solver doesn't report how many it found
+	}
+	result.timings = SolveTimings{Go: time.Since(start) - native, Native: native}
+	return result
+}
+
+// withObjectiveCutoff returns a copy of the given model proto with the
+// objective's domain restricted to values strictly better than the given one.
+// The domain applies to the objective's linear terms, before the offset and
+// scaling factor are applied.
+func withObjectiveCutoff(model *pb.CpModelProto, cutoff int64) *pb.CpModelProto {
+	model = proto.Clone(model).(*pb.CpModelProto)
+	objective := model.GetObjective()
+	sign := int64(1)
+	if objective.GetScalingFactor() < 0 {
+		sign = -1
+	}
+	ub := sign*cutoff - int64(objective.GetOffset()) - 1
+
+	lb := int64(0) // the smallest value the linear terms can take on
+	for i, ref := range objective.GetVars() {
+		var d []int64
+		if ref >= 0 {
+			d = model.GetVariables()[ref].GetDomain()
+		} else {
+			d = model.GetVariables()[-ref-1].GetDomain()
+			d = []int64{1 - d[len(d)-1], 1 - d[0]}
+		}
+		lo, hi := objective.GetCoeffs()[i]*d[0], objective.GetCoeffs()[i]*d[len(d)-1]
+		if hi < lo {
+			lo = hi
+		}
+		lb += lo
+	}
+	if lb > ub {
+		lb = ub // there are no better solutions, we're infeasible regardless
+	}
+	objective.Domain = []int64{lb, ub}
+	return model
+}
+
+// withHintsFixed returns a copy of the given model proto with every hinted variable
+// constrained to its hinted value. This emulates CP-SAT's
+// fix_variables_to_their_hinted_value, which the bundled version of OR-Tools
+// predates. We use constraints instead of narrowing domains so that a hint
+// that lies outside its variable's domain renders the model infeasible.
+func withHintsFixed(model *pb.CpModelProto) *pb.CpModelProto {
+	model = proto.Clone(model).(*pb.CpModelProto)
+	hint := model.GetSolutionHint()
+	for i, v := range hint.GetVars() {
+		model.Constraints = append(model.Constraints, &pb.ConstraintProto{
+			Constraint: &pb.ConstraintProto_Linear{
+				Linear: &pb.LinearConstraintProto{
+					Vars:   []int32{v},
+					Coeffs: []int64{1},
+					Domain: []int64{hint.Values[i], hint.Values[i]},
+				},
+			},
+		})
+	}
+	return model
+}
+
+// canonicalize returns the lexicographically smallest optimal solution of the
+// given model, starting off at the given optimal one. It does so by fixing the
+// objective to its optimal value, and then minimizing each variable in turn,
+// fixing it to its minimum before moving on to the next. The re-solves share
+// what's left of the given budget.
+func (m *Model) canonicalize(solver internal.SolveWrapper, b *budget, model *pb.CpModelProto, solution []int64) []int64 {
+	value := func(ref int32) int64 {
+		if ref < 0 { // negated literal
+			return 1 - solution[-ref-1]
+		}
+		return solution[ref]
+	}
+	fix := func(vars []int32, coeffs []int64, v int64) {
+		model.Constraints = append(model.Constraints, &pb.ConstraintProto{
+			Constraint: &pb.ConstraintProto_Linear{
+				Linear: &pb.LinearConstraintProto{
+					Vars:   vars,
+					Coeffs: coeffs,
+					Domain: []int64{v, v},
+				},
+			},
+		})
+	}
+
+	model = proto.Clone(model).(*pb.CpModelProto)
+	if objective := model.GetObjective(); objective != nil {
+		var v int64
+		for i, ref := range objective.GetVars() {
+			v += objective.Coeffs[i] * value(ref)
+		}
+		fix(objective.Vars, objective.Coeffs, v)
+	}
+
+	for i := range model.GetVariables() {
+		idx := int32(i)
+		model.Objective = &pb.CpObjectiveProto{Vars: []int32{idx}, Coeffs: []int64{1}}
+		model.SolutionHint = &pb.PartialVariableAssignment{}
+		for j, v := range solution {
+			model.SolutionHint.Vars = append(model.SolutionHint.Vars, int32(j))
+			model.SolutionHint.Values = append(model.SolutionHint.Values, v)
+		}
+
+		params, ok := b.remaining()
+		if !ok {
+			break // settle for what we have so far
+		}
+		solver.SetParameters(*params)
+		resp := solver.Solve(*model)
+		b.spend(&resp)
+		if resp.Status != pb.CpSolverStatus_OPTIMAL {
+			// We've run out of time (or some other limit); settle for what we
+			// have so far.
+			break
+		}
+		solution = resp.Solution
+		fix([]int32{idx}, []int64{1}, value(idx))
+	}
+	return solution
+}
+
+// budget tracks what's left of the limits a solve was configured with (see
+// WithTimeout, WithConflictLimit and WithDeterministicTimeLimit), for solves
+// made up of multiple native ones; the limits apply to the solve as a whole.
+type budget struct {
+	params *pb.SatParameters
+	start  time.Time
+
+	deterministicTime float64
+	conflicts         int64
+}
+
+// spend records the resources used by a native solve.
+func (b *budget) spend(resp *pb.CpSolverResponse) {
+	b.deterministicTime += resp.GetDeterministicTime()
+	b.conflicts += resp.GetNumConflicts()
+}
+
+// remaining returns the parameters for the next native solve, with its limits
+// lowered by what's been spent so far, and whether there's anything left.
+func (b *budget) remaining() (*pb.SatParameters, bool) {
+	params := proto.Clone(b.params).(*pb.SatParameters)
+	if b.params.MaxTimeInSeconds != nil {
+		left := b.params.GetMaxTimeInSeconds() - time.Since(b.start).Seconds()
+		if left <= 0 {
+			return nil, false
+		}
+		params.MaxTimeInSeconds = &left
+	}
+	if b.params.MaxDeterministicTime != nil {
+		left := b.params.GetMaxDeterministicTime() - b.deterministicTime
+		if left <= 0 {
+			return nil, false
+		}
+		params.MaxDeterministicTime = &left
+	}
+	if b.params.MaxNumberOfConflicts != nil {
+		left := b.params.GetMaxNumberOfConflicts() - b.conflicts
+		if left <= 0 {
+			return nil, false
+		}
+		params.MaxNumberOfConflicts = &left
+	}
+	return params, true
+}
+
+// finalize emits the protos for all pending deferred constructs. Constructs
+// are free to defer further ones when emitted.
+func (m *Model) finalize() {
+	for len(m.deferred) > 0 {
+		fn := m.deferred[0]
+		m.deferred = m.deferred[1:]
+		fn(m)
+	}
+	m.penalize()
+}
+
+// finalized returns the model with all pending deferred constructs emitted,
+// for use by methods that only look at the model (validating or exporting it,
+// say). If there are any, they're emitted into a clone so the model itself is
+// left as is; it can still be added to afterwards.
+func (m *Model) finalized() *Model {
+	if len(m.deferred) == 0 && m.soft == nil {
+		return m
+	}
+	c, _ := m.clone()
+	c.finalize()
+	return c
+}
+
+// onFinalize defers the given function until the model is next finalized.
+func (m *Model) onFinalize(fn func(*Model)) {
+	m.assertMutable()
+	m.deferred = append(m.deferred, fn)
+}
+
+// introspect returns whether the model holds onto the Go-side
+// representations of what it's made up of.
+func (m *Model) introspect() bool {
+	return !m.withoutIntrospection
+}
+
+func (m *Model) assertMutable() {
+	if m.frozen {
+		panic("model already finalized")
+	}
+}
+
+// variables returns all the model's variables, ordered by index. Variables
+// instantiated through a ModelBuilder aren't tracked by the model, so they're
+// reconstructed from the underlying protos.
+func (m *Model) variables() []IntVar {
+	vars := make([]IntVar, len(m.pb.GetVariables()))
+	for _, iv := range m.vars {
+		vars[iv.index()] = iv
+	}
+	for _, c := range m.constants {
+		vars[c.index()] = c
+	}
+	for _, l := range m.literals {
+		vars[l.index()] = l
+	}
+	for i, v := range m.pb.GetVariables() {
+		if vars[i] == nil {
+			d := &domain{intervals: v.GetDomain()}
+			vars[i] = newIntVar(d, int32(i), false, false, v.GetName())
+		}
+	}
+	return vars
+}
+
+// derivedName returns the name of an auxiliary variable instantiated on behalf
+// of the named construct, or the empty string if it's unnamed.
+func derivedName(name, suffix string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", name, suffix)
 }
 
 func (m *Model) name() string {
 	name := m.pb.GetName()
 	if name == "" {
 		name = "<unnamed>"
+	} else {
+		name = quoteName(name)
 	}
 	return name
 }
 
 func (m *Model) newIntVarFromDomainInternal(d Domain, isLiteral, isConst bool, name string) IntVar {
+	m.assertMutable()
 	idx := len(m.pb.GetVariables())
 	iv := newIntVar(d, int32(idx), isLiteral, isConst, name)
+	iv.model = m
 	m.pb.Variables = append(m.pb.Variables, iv.pb)
+	m.trackVariable(int32(idx))
 	return iv
 }
 
 func (m *Model) addConstraintsInternal(cs ...Constraint) {
+	m.assertMutable()
+	lo := len(m.pb.GetConstraints())
 	for _, c := range cs {
-		m.pb.Constraints = append(m.pb.Constraints, c.protos()...)
+		m.pb.Constraints = append(m.pb.Constraints, m.admit(c)...)
 	}
+	m.trackConstraints(lo, len(m.pb.GetConstraints()))
 }
 
 func (m *Model) toObjectiveProto(e LinearExpr) *pb.CpObjectiveProto {
+	// We copy out the coefficients, which Maximize negates in place; the
+	// expression itself is left as is.
 	return &pb.CpObjectiveProto{
 		Vars:   e.vars(),
-		Coeffs: e.coeffs(),
+		Coeffs: append([]int64(nil), e.coeffs()...),
 		Offset: float64(e.offset()),
 	}
 }
+
+func (m *Model) toMaximizationProto(e LinearExpr) *pb.CpObjectiveProto {
+	// For maximization objectives, we want to negate all the coefficients and
+	// set the scaling factor to -1.
+	proto := m.toObjectiveProto(e)
+	for i, coeff := range proto.Coeffs {
+		proto.Coeffs[i] = -coeff
+	}
+	proto.Offset = -proto.Offset
+	proto.ScalingFactor = -1
+	return proto
+}
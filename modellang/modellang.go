@@ -0,0 +1,193 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package modellang compiles text model descriptions into solver models. It's
+// the same language used by this repo's datadriven tests, allowing models to be
+// authored (and loaded at runtime) without writing any Go.
+//
+// A model description consists of one statement per line. Blank lines and
+// lines starting with '#' are ignored. For example:
+//
+//	# Three distinct variables, summing to 4.
+//	model.name(example)
+//	model.vars(x to z in [0, 2])
+//	constrain.all-different(x, y, z)
+//	constrain.linear-exprs(x + y + z in [4, 4])
+//	model.maximize(x)
+//
+// Statements either declare variables (model.vars, model.literals,
+// model.constants, model.intervals), set the model's name or objective
+// (model.name, model.minimize, model.maximize), or constrain variables
+// declared earlier (constrain.all-different, constrain.all-same,
+// constrain.assignments, constrain.at-least-k, constrain.at-most-k,
+// constrain.binary-op, constrain.boolean-and, constrain.boolean-or,
+// constrain.boolean-xor, constrain.cumulative, constrain.element,
+// constrain.equality, constrain.exactly-k, constrain.implication,
+// constrain.linear-exprs, constrain.minimum-gap, constrain.non-overlapping,
+// constrain.non-overlapping-2D). Statements are written using the following
+// grammar, in EBNF:
+//
+//	Digits   = Digit { Digit } .
+//	Word     = Letter { Letter } .
+//	Boolean  = "true" | "false" .
+//	Quoted   = `"` { Char } `"` .
+//
+//	Identifier     = Word | Quoted .
+//	Number         = [ "-" ] Digits .
+//	Domain         = "[" Number "," Number "]" .
+//	Range          = Number ".." Number .
+//	Variable       = Identifier | Letter "to" Letter .
+//	Interval       = Identifier "as" "[" IntervalComponent "," IntervalComponent "|" IntervalComponent "]" .
+//	IntervalComponent = Identifier | Range .
+//	LinearTerm     = ( [ Digits ] Identifier ) | Digits .
+//	LinearExpr     = [ "-" ] LinearTerm { ( "+" | "-" ) LinearTerm } | "Σ" "(" Variables ")" .
+//	IntervalDemand = Identifier ":" Identifier .
+//	Operand        = Identifier | Number .
+//	Relation       = LinearExpr ( "<" | "<=" | ">" | ">=" | "==" | "!=" ) LinearExpr .
+//
+//	Booleans        = Boolean { "," Boolean } .
+//	Numbers         = Number { "," Number } .
+//	Domains         = Domain { "∪" Domain } .
+//	Variables       = Variable { "," Variable } .
+//	Intervals       = Interval { "," Interval } .
+//	LinearExprs     = LinearExpr { "," LinearExpr } .
+//	IntervalDemands = IntervalDemand {"," IntervalDemand } .
+//	Relations       = Relation { "," Relation } .
+//
+//	NumbersList = "[" Numbers "]" { "∪" "[" Numbers "]" } .
+//	BooleanList = "[" Booleans "]" { "∪" "[" Booleans "]" } .
+//
+//	AssignmentsArgument      = "[" Variables "]" ( "∈" | "∉" ) ( NumbersList | BooleanList ) .
+//	BinaryOpArgument         = Operand ( "/" | "%" | "*" ) Operand "==" Operand .
+//	ConstantsArgument        = Variables "==" Number .
+//	CumulativeArgument       = IntervalDemands "|" Identifier .
+//	DomainArgument           = ( Variables | LinearExprs ) "in" Domains .
+//	ElementArgument          = Identifier "==" "[" Variables "]" "[" Identifier "]" .
+//	ImplicationArgument      = Identifier "→"  Identifier .
+//	IntervalsArgument        = Intervals .
+//	KArgument                = Variables "|" Digits .
+//	LinearEqualityArgument   = LinearExpr "==" ( "max" | "min" ) "(" LinearExprs ")" .
+//	LinearExprsArgument      = LinearExprs .
+//	NonOverlapping2DArgument = "[" Variables "]" "," "[" Variables "]" "," Boolean .
+//	RelationsArgument        = Relations .
+//	VariableEqualityArgument = Identifier "==" ( "max" | "min" ) "(" Variables ")" .
+//	VariablesArgument        = Variables .
+//
+//	Argument = AssignmentsArgument | BinaryOpArgument | ConstantsArgument
+//	         | CumulativeArgument | DomainArgument | ElementArgument
+//	         | ImplicationArgument | IntervalsArgument | KArgument
+//	         | LinearEqualityArgument | LinearExprsArgument
+//	         | NonOverlapping2DArgument | RelationsArgument
+//	         | VariableEqualityArgument | VariablesArgument .
+//
+//	Method      = Identifier { "-" | Identifier | Digits } .
+//	Receiver    = Identifier .
+//	Enforcement = "if" Variables .
+//	Statement   = Receiver "." Method "(" [ Argument ] ")" [ Enforcement ] .
+//
+// The "if" enforcement clause is only supported for model.intervals (with a
+// single literal), constrain.at-least-k, constrain.at-most-k,
+// constrain.boolean-and, constrain.boolean-or, constrain.exactly-k and
+// constrain.linear-exprs.
+//
+// Names that aren't words (ones with spaces or digits, say) are written as
+// quoted strings, escaped using Go's rules for string literals:
+//
+//	model.literals("Nurse Ana — Mon", "Nurse Ana — Tue")
+package modellang
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/irfansharif/solver"
+	"github.com/irfansharif/solver/internal/compiler"
+	"github.com/irfansharif/solver/internal/parser"
+	"github.com/irfansharif/solver/internal/parser/ast"
+)
+
+// Model is a compiled model description. Alongside the model itself, it
+// captures the variables declared in the description, keyed by name, so that
+// they can be referred to when inspecting results.
+type Model struct {
+	*solver.Model
+
+	Vars      map[string]solver.IntVar // variables and constants
+	Literals  map[string]solver.Literal
+	Intervals map[string]solver.Interval
+}
+
+// Error is a compilation error, positioned at the offending statement.
+type Error struct {
+	Filename string
+	Line     int
+	Msg      string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Filename, e.Line, e.Msg)
+}
+
+// Compile compiles the model description read from r. The filename is only
+// used to position errors; errors in the description itself are of type
+// *Error.
+func Compile(filename string, r io.Reader) (*Model, error) {
+	type positioned struct {
+		stmt *ast.Statement
+		line int
+	}
+
+	var stmts []positioned
+	name := ""
+	scanner := bufio.NewScanner(r)
+	// Descriptions can have lines far exceeding the default token size (long
+	// lists of variables, say).
+	scanner.Buffer(make([]byte, 100), 10*bufio.MaxScanTokenSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		stmt, err := parser.Compile(text)
+		if err != nil {
+			return nil, &Error{Filename: filename, Line: line, Msg: err.Error()}
+		}
+		if stmt.Receiver == "model" && stmt.Method == ast.NameMethod {
+			// Models are named at instantiation, so we hoist this out.
+			name = ast.Name(stmt.Argument.(*ast.VariablesArgument).Variables[0])
+			continue
+		}
+		stmts = append(stmts, positioned{stmt: stmt, line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	c := compiler.New(solver.NewModel(name))
+	for _, s := range stmts {
+		if err := c.Compile(s.stmt); err != nil {
+			return nil, &Error{Filename: filename, Line: s.line, Msg: err.Error()}
+		}
+	}
+	return &Model{
+		Model:     c.Model,
+		Vars:      c.Vars,
+		Literals:  c.Literals,
+		Intervals: c.Intervals,
+	}, nil
+}
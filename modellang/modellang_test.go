@@ -0,0 +1,126 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package modellang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	const input = `
+# Three distinct variables, summing to 4.
+model.name(example)
+model.vars(x to z in [0, 2])
+model.literals(a, b)
+constrain.all-different(x, y, z)
+constrain.linear-exprs(x + y + z in [4, 4])
+constrain.boolean-or(a, b) if a
+`
+	m, err := Compile("example.model", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, m.Vars, 3)
+	require.Len(t, m.Literals, 2)
+	require.Equal(t, `model=example
+  variables (num = 3)
+    x in [0, 2]
+    y in [0, 2]
+    z in [0, 2]
+  literals (num = 2)
+    a
+    b
+  constraints (num = 3)
+    all-different: x, y, z
+    linear-constraint: x + y + z in [4, 4]
+    boolean-or: a, b if (a)
+`, m.String())
+}
+
+func TestCompileInlineIntervals(t *testing.T) {
+	const input = `
+model.name(example)
+model.vars(s in [0, 10])
+model.intervals(i as [s, 0..10 | 2..4])
+`
+	m, err := Compile("example.model", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, m.Vars, 3)
+	require.Contains(t, m.Vars, "i.end")
+	require.Contains(t, m.Vars, "i.size")
+	require.Len(t, m.Intervals, 1)
+}
+
+func TestCompileQuotedNames(t *testing.T) {
+	const input = `
+model.name("rota — week 1")
+model.literals("Nurse Ana — Mon", "Nurse Ana — Tue")
+model.vars("hours worked" in [0, 40])
+model.intervals("shift one" as ["hours worked", 0..40 | 8..8])
+constrain.boolean-or("Nurse Ana — Mon", "Nurse Ana — Tue")
+`
+	m, err := Compile("example.model", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Contains(t, m.Literals, "Nurse Ana — Mon")
+	require.Contains(t, m.Vars, "hours worked")
+	require.Contains(t, m.Vars, "shift one.end")
+	require.Contains(t, m.Intervals, "shift one")
+
+	// The names are quoted when printed, so they can be re-parsed.
+	str := m.String()
+	require.Contains(t, str, `model="rota — week 1"`)
+	require.Contains(t, str, `boolean-or: "Nurse Ana — Mon", "Nurse Ana — Tue"`)
+	require.Contains(t, str, `["hours worked", "shift one.end" | "shift one.size"]`)
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{
+			input: "model.vars(x in [0, 2])\n\nconstrain.all-same(x, y)",
+			err:   "test.model:3: constrain.all-same: unrecognized variable: y",
+		},
+		{
+			input: "model.vars(x in [0, 2])\nmodel.vars(x in [0, 4])",
+			err:   "test.model:2: model.vars: x already declared",
+		},
+		{
+			input: "model.vars(x in [0, 2])\nmodel.vars(\"x\" in [0, 4])",
+			err:   "test.model:2: model.vars: \"x\" already declared",
+		},
+		{
+			input: "model.vars(s in [0, 2])\nmodel.intervals(i as [s, 0..4 | 2..2], i as [s, 0..4 | 2..2])",
+			err:   "test.model:2: model.intervals: i already declared",
+		},
+		{
+			input: "model.vars(x in [0, 2])\nconstrain.unknown(x)",
+			err:   "test.model:2: unrecognized method: unknown",
+		},
+		{
+			input: "model.vars(x in [0, 2])\nmodel.solve()",
+			err:   "test.model:2: model.solve: unsupported in model descriptions",
+		},
+		{
+			input: "model.vars(x in [0, 2])\nresult.values(x)",
+			err:   "test.model:2: result.values: results can't be inspected in model descriptions",
+		},
+	} {
+		_, err := Compile("test.model", strings.NewReader(tc.input))
+		require.EqualError(t, err, tc.err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NewNValueConstraint ensures that the target is equal to the number of
+// distinct values taken on by the given variables.
+//
+// It's expressed using literals indicating whether each variable takes on each
+// of the values in its domain, and whether each value is taken on at all, all
+// instantiated in the model right away; the returned constraint still needs
+// to be added to the model. The variables' domains should be small: it fails
+// if they collectively contain more than 4096 values (see
+// WithConstructionErrors for an alternative to panicking).
+func NewNValueConstraint(target IntVar, vars ...IntVar) Constraint {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("nvalue: %s == |{", target.name()))
+	printVars(&b, vars...)
+	b.WriteString("}|")
+	str := b.String()
+
+	all := append([]IntVar{target}, vars...)
+	m := modelFor(all...)
+	if m == nil {
+		return invalidConstraint(all, "%s: variables not instantiated in a model", str)
+	}
+
+	var size uint64
+	for _, v := range vars {
+		ls := v.domain().list(0)
+		for i := 0; i < len(ls); i += 2 {
+			span := uint64(ls[i+1]) - uint64(ls[i]) // can't overflow, unlike int64s
+			if span >= maxEncodedDomainSize || size+span+1 > maxEncodedDomainSize {
+				return invalidConstraint(all, "%s: domains too large (more than %d values)", str, maxEncodedDomainSize)
+			}
+			size += span + 1
+		}
+	}
+
+	var cs []Constraint
+	candidates := make(map[int64][]Literal) // value => literals indicating whether each var takes it on
+	for _, v := range vars {
+		values := domainValues(v.domain())
+		takes, channels := m.valueLiterals(v, values)
+		for i, value := range values {
+			candidates[value] = append(candidates[value], takes[i])
+		}
+		cs = append(cs, channels...)
+	}
+
+	values := make([]int64, 0, len(candidates))
+	for value := range candidates {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	name := protoName(target)
+	var used []IntVar
+	for _, value := range values {
+		lit := m.newAuxLiteral(derivedName(name, fmt.Sprintf("used%d", value)))
+		cs = append(cs, NewBooleanOrConstraint(candidates[value]...).OnlyEnforceIf(lit))
+		for _, takes := range candidates[value] {
+			cs = append(cs, NewImplicationConstraint(takes, lit))
+		}
+		used = append(used, lit)
+	}
+	cs = append(cs, NewEqualityConstraint(Sum(target), Sum(used...)))
+	return constraints{cs: cs, str: str}
+}
@@ -15,26 +15,88 @@
 package solver
 
 import (
-	"fmt"
+	"errors"
 	"io"
 	"log"
 	"time"
 
 	"github.com/irfansharif/solver/internal"
-	"github.com/irfansharif/solver/internal/pb"
+	"github.com/irfansharif/solver/pb"
 )
 
 type Option func(o *options, s internal.SolveWrapper)
 
+// TODO(irfansharif): Expose per-subsolver parameter overrides
+// (SatParameters.subsolver_params, and the extra_subsolvers/ignore_subsolvers
+// that go along with them) once we've upgraded past OR-Tools v9.1; the bundled
+// sat_parameters.proto predates them. See pb/README.md.
+
 type options struct {
-	params   pb.SatParameters
-	logger   *log.Logger
-	solution *solutionCallback
+	params     pb.SatParameters
+	logger     *log.Logger
+	logFilter  LogLevel
+	logLevel   *LogLevel
+	handler    logHandler
+	hintOnly   bool
+	canonical  bool
+	cutoff     *objectiveCutoff
+	order      *variableOrder
+	solution   *solutionCallback
+	handle     *SolveHandle
+	accountant *accountant
+	profiling  []profilingHook
+	cache      *Cache
+}
+
+// objectiveCutoff is a bound on the objective value; see WithObjectiveCutoff.
+type objectiveCutoff struct {
+	value int64
+	sense ObjectiveSense
 }
 
-func (o *options) validate() (bool, error) {
-	if o.params.GetEnumerateAllSolutions() && o.params.GetNumSearchWorkers() > 1 {
-		return false, fmt.Errorf("cannot enumerate with parallelism > 1")
+var (
+	// ErrEnumerationWithParallelism is returned when solving with both
+	// WithEnumeration and WithParallelism > 1.
+	ErrEnumerationWithParallelism = errors.New("cannot enumerate with parallelism > 1")
+	// ErrEnumerationWithObjective is returned when solving a model with an
+	// objective using WithEnumeration.
+	ErrEnumerationWithObjective = errors.New("cannot enumerate over a model with an objective")
+	// ErrEnumerationWithCanonicalSolution is returned when solving with both
+	// WithEnumeration and WithCanonicalSolution.
+	ErrEnumerationWithCanonicalSolution = errors.New("cannot enumerate when canonicalizing solutions")
+	// ErrCutoffWithoutObjective is returned when solving a model without an
+	// objective using WithObjectiveCutoff.
+	ErrCutoffWithoutObjective = errors.New("cannot apply an objective cutoff to a model without an objective")
+	// ErrCutoffSenseMismatch is returned when solving a model using
+	// WithObjectiveCutoff, with a sense other than the objective's.
+	ErrCutoffSenseMismatch = errors.New("objective cutoff sense doesn't match the objective's")
+)
+
+// validate checks whether the options are compatible with one another, and
+// with the model being solved.
+func (o *options) validate(m *Model) (bool, error) {
+	if err := m.Err(); err != nil {
+		return false, err // invalid constraints were left out
+	}
+	if o.params.GetEnumerateAllSolutions() {
+		if o.params.GetNumSearchWorkers() > 1 {
+			return false, ErrEnumerationWithParallelism
+		}
+		if m.pb.GetObjective() != nil { // includes penalties of soft constraints
+			return false, ErrEnumerationWithObjective
+		}
+		if o.canonical {
+			return false, ErrEnumerationWithCanonicalSolution
+		}
+	}
+	if o.cutoff != nil {
+		objective := m.pb.GetObjective()
+		if objective == nil {
+			return false, ErrCutoffWithoutObjective
+		}
+		if maximize := objective.GetScalingFactor() < 0; maximize != (o.cutoff.sense == Maximization) {
+			return false, ErrCutoffSenseMismatch
+		}
 	}
 	return true, nil
 }
@@ -47,14 +109,32 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithConflictLimit configures the solver to stop after the given number of
+// conflicts. Unlike WithTimeout, this bounds the solver's effort in a way
+// that's independent of the machine it's running on.
+//
+// NB: With parallelism > 1, the limit applies to each worker individually.
+func WithConflictLimit(n int64) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.params.MaxNumberOfConflicts = &n
+	}
+}
+
+// WithDeterministicTimeLimit configures the solver to stop after the given
+// amount of deterministic time (see Result.DeterministicTime), a measure of
+// work done that's reproducible across runs and machines.
+func WithDeterministicTimeLimit(limit float64) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.params.MaxDeterministicTime = &limit
+	}
+}
+
 // WithLogger configures the solver to route its internal logging to the given
-// io.Writer, using the given prefix.
+// io.Writer, using the given prefix. What's logged can be filtered using
+// WithLogFilter or WithLogLevel.
 func WithLogger(w io.Writer, prefix string) Option {
 	return func(o *options, s internal.SolveWrapper) {
-		logSearchProgress, logToResponse, logToStdout := true, true, false
-		o.params.LogSearchProgress = &logSearchProgress
-		o.params.LogToStdout = &logToStdout
-		o.params.LogToResponse = &logToResponse
+		o.enableLogging()
 
 		// TODO(irfansharif): Right now we're simply logging to the response
 		// proto, which isn't being streamed during the search process and not
@@ -67,6 +147,52 @@ func WithLogger(w io.Writer, prefix string) Option {
 	}
 }
 
+// WithHintOnly configures the solver to fix all hinted variables (see
+// Model.AddHint) to their hinted values. If they don't satisfy the model, the
+// result is Infeasible().
+func WithHintOnly() Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.hintOnly = true
+	}
+}
+
+// WithObjectiveCutoff configures the solver to only look for solutions
+// strictly better than the given objective value, typically that of a
+// solution found heuristically. The sense is that of the model's objective
+// (it's used to make sure the cutoff is interpreted as intended). If there
+// are no better solutions, the result is Infeasible(). For fractional
+// objectives (see Model.MinimizeFloat), the value is in scaled units, like
+// ObjectiveValue's (see Result.ObjectiveScale).
+func WithObjectiveCutoff(value int64, sense ObjectiveSense) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.cutoff = &objectiveCutoff{value: value, sense: sense}
+	}
+}
+
+// WithCanonicalSolution configures the solver to break ties between equally
+// good solutions lexicographically: of all optimal solutions, it returns the one
+// that minimizes the first variable instantiated into the model, then the
+// second, and so on. Results are then stable across solver versions and
+// parameters. It's expensive -- the model is
+// re-solved once for every variable -- and only applies to results that are
+// Optimal(). Limits (see WithTimeout, say) apply to the re-solves as a whole,
+// not each one; if reached, the tie-breaking is left incomplete.
+func WithCanonicalSolution() Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.canonical = true
+	}
+}
+
+// WithTightenedDomains configures the solver to report the variable domains
+// it was able to tighten during search, see Result.TightenedDomains and
+// Result.FixedVariables. It sets fill_tightened_domains_in_response.
+func WithTightenedDomains() Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		fill := true
+		o.params.FillTightenedDomainsInResponse = &fill
+	}
+}
+
 // WithParallelism configures the solver to use the given number of parallel
 // workers during search. If the number provided is <= 1, there will be no
 // parallelism.
@@ -79,6 +205,11 @@ func WithParallelism(parallelism int) Option {
 
 // WithEnumeration configures the solver to enumerate over all solutions without
 // objective. This option is incompatible with a parallelism greater than 1.
+//
+// Each result passed to the callback carries the search statistics (bounds,
+// solution count, wall and user time) as of when the solution was found. The
+// deterministic time isn't exposed mid-search, and is always zero (see
+// Result.DeterministicTime).
 func WithEnumeration(f func(Result)) Option {
 	return func(o *options, s internal.SolveWrapper) {
 		enumerate := true
@@ -95,9 +226,28 @@ func WithEnumeration(f func(Result)) Option {
 type solutionCallback struct {
 	f    func(Result)
 	hook internal.SolutionCallback
+
+	// solutions is the number of solutions found so far.
+	solutions int64
+
+	// objectiveScale is that of the model being solved, if any; see
+	// Model.MinimizeFloat.
+	objectiveScale int64
 }
 
 func (p *solutionCallback) OnSolutionCallback() {
+	p.solutions++
+
+	// The response proto we get back doesn't have the search statistics
+	// populated, so we fill them in using what's exposed by the callback
+	// itself. The deterministic time isn't, so it's left as zero.
 	proto := p.hook.Response()
-	p.f(Result{pb: &proto})
+	proto.ObjectiveValue = p.hook.ObjectiveValue()
+	proto.BestObjectiveBound = p.hook.BestObjectiveBound()
+	proto.NumBooleans = p.hook.NumBooleans()
+	proto.NumConflicts = p.hook.NumConflicts()
+	proto.NumBranches = p.hook.NumBranches()
+	proto.WallTime = p.hook.WallTime()
+	proto.UserTime = p.hook.UserTime()
+	p.f(Result{pb: &proto, solutions: p.solutions, objectiveScale: p.objectiveScale})
 }
@@ -0,0 +1,147 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"github.com/irfansharif/solver/internal"
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// ValueSelection determines the values tried first when deciding variables;
+// see WithVariableOrder and Model.AddDecisionStrategy.
+type ValueSelection int
+
+const (
+	// MinValueFirst tries the smallest value in the variable's domain first.
+	MinValueFirst ValueSelection = iota
+	// MaxValueFirst tries the largest value in the variable's domain first.
+	MaxValueFirst
+	// LowerHalfFirst tries the lower half of the variable's domain first.
+	LowerHalfFirst
+	// UpperHalfFirst tries the upper half of the variable's domain first.
+	UpperHalfFirst
+	// MedianValueFirst tries the median value of the variable's domain first.
+	MedianValueFirst
+)
+
+// VariableSelection determines the variable decided next, amongst those in a
+// decision strategy not yet fixed; see Model.AddDecisionStrategy.
+type VariableSelection int
+
+const (
+	// ChooseFirst chooses the first variable, in the order given.
+	ChooseFirst VariableSelection = iota
+	// ChooseLowestMin chooses the variable with the smallest lower bound.
+	ChooseLowestMin
+	// ChooseHighestMax chooses the variable with the largest upper bound.
+	ChooseHighestMax
+	// ChooseMinDomainSize chooses the variable with the smallest domain.
+	ChooseMinDomainSize
+	// ChooseMaxDomainSize chooses the variable with the largest domain.
+	ChooseMaxDomainSize
+)
+
+func (v VariableSelection) proto() pb.DecisionStrategyProto_VariableSelectionStrategy {
+	switch v {
+	case ChooseLowestMin:
+		return pb.DecisionStrategyProto_CHOOSE_LOWEST_MIN
+	case ChooseHighestMax:
+		return pb.DecisionStrategyProto_CHOOSE_HIGHEST_MAX
+	case ChooseMinDomainSize:
+		return pb.DecisionStrategyProto_CHOOSE_MIN_DOMAIN_SIZE
+	case ChooseMaxDomainSize:
+		return pb.DecisionStrategyProto_CHOOSE_MAX_DOMAIN_SIZE
+	default:
+		return pb.DecisionStrategyProto_CHOOSE_FIRST
+	}
+}
+
+func (v ValueSelection) proto() pb.DecisionStrategyProto_DomainReductionStrategy {
+	switch v {
+	case MaxValueFirst:
+		return pb.DecisionStrategyProto_SELECT_MAX_VALUE
+	case LowerHalfFirst:
+		return pb.DecisionStrategyProto_SELECT_LOWER_HALF
+	case UpperHalfFirst:
+		return pb.DecisionStrategyProto_SELECT_UPPER_HALF
+	case MedianValueFirst:
+		return pb.DecisionStrategyProto_SELECT_MEDIAN_VALUE
+	default:
+		return pb.DecisionStrategyProto_SELECT_MIN_VALUE
+	}
+}
+
+// AddDecisionStrategy adds a strategy for the solver to decide the given
+// variables with: which variable to decide next, and which values to try
+// first. Strategies are followed in the order they're added. They're followed
+// exactly with fixed search (see WithFixedSearch), with variables not covered
+// by any then decided in order, smallest value first; otherwise, they only
+// guide the search. Guiding the search this way (deciding the earliest
+// startable tasks first, say) is often necessary for hard scheduling
+// instances.
+func (m *Model) AddDecisionStrategy(vars []IntVar, variable VariableSelection, value ValueSelection) {
+	m.assertMutable()
+	m.pb.SearchStrategy = append(m.pb.SearchStrategy, &pb.DecisionStrategyProto{
+		Variables:                 intVarList(vars).indexes(),
+		VariableSelectionStrategy: variable.proto(),
+		DomainReductionStrategy:   value.proto(),
+	})
+}
+
+// WithFixedSearch configures the solver to follow the model's decision
+// strategies (see Model.AddDecisionStrategy) exactly.
+func WithFixedSearch() Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		search := pb.SatParameters_FIXED_SEARCH
+		o.params.SearchBranching = &search
+	}
+}
+
+// variableOrder is the order in which to decide variables; see
+// WithVariableOrder.
+type variableOrder struct {
+	vars  []int32
+	value ValueSelection
+}
+
+// WithVariableOrder configures the solver with a decision strategy, ahead of
+// the model's own (see Model.AddDecisionStrategy), deciding the given variables
+// in the given order and trying values as per the given selection. It's a
+// lightweight way to pass on domain knowledge about which decisions matter
+// most, like scheduling the longest tasks first, without changing the model.
+// Like the model's strategies, it's only a hint to the search unless combined
+// with WithFixedSearch; the search branching configured otherwise is left as
+// is.
+func WithVariableOrder(vars []IntVar, value ValueSelection) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		if len(vars) == 0 {
+			return
+		}
+		o.order = &variableOrder{vars: intVarList(vars).indexes(), value: value}
+	}
+}
+
+// withVariableOrder returns a copy of the given model proto with a search
+// strategy, ahead of any others, deciding variables in the given order.
+func withVariableOrder(model *pb.CpModelProto, order *variableOrder) *pb.CpModelProto {
+	model = proto.Clone(model).(*pb.CpModelProto)
+	model.SearchStrategy = append([]*pb.DecisionStrategyProto{{
+		Variables:                 order.vars,
+		VariableSelectionStrategy: pb.DecisionStrategyProto_CHOOSE_FIRST,
+		DomainReductionStrategy:   order.value.proto(),
+	}}, model.SearchStrategy...)
+	return model
+}
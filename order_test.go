@@ -0,0 +1,74 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableOrder(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	l := model.NewLiteral("l")
+
+	var o options
+	WithVariableOrder(nil, MinValueFirst)(&o, nil)
+	require.Nil(t, o.order)
+	WithVariableOrder([]IntVar{y, l.Not(), x}, MaxValueFirst)(&o, nil)
+	require.Nil(t, o.params.SearchBranching) // left as is
+
+	ordered := withVariableOrder(model.pb, o.order)
+	require.Len(t, model.pb.GetSearchStrategy(), 0)
+	require.Len(t, ordered.GetSearchStrategy(), 1)
+	strategy := ordered.GetSearchStrategy()[0]
+	require.Equal(t, []int32{y.index(), l.Not().index(), x.index()}, strategy.GetVariables())
+	require.Equal(t, pb.DecisionStrategyProto_CHOOSE_FIRST, strategy.GetVariableSelectionStrategy())
+	require.Equal(t, pb.DecisionStrategyProto_SELECT_MAX_VALUE, strategy.GetDomainReductionStrategy())
+
+	// Without an objective, the first solution found with fixed search follows
+	// the order.
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 12)))
+	result := model.Solve(WithVariableOrder([]IntVar{y, x}, MaxValueFirst), WithFixedSearch(), WithParallelism(1))
+	require.True(t, result.Feasible() || result.Optimal())
+	require.Equal(t, int64(10), result.Value(y))
+	require.Equal(t, int64(2), result.Value(x))
+}
+
+func TestDecisionStrategy(t *testing.T) {
+	model := NewModel("")
+	x, y, z := model.NewIntVar(0, 10, "x"), model.NewIntVar(3, 10, "y"), model.NewIntVar(0, 10, "z")
+	model.AddConstraints(NewAllDifferentConstraint(x, y, z))
+	model.AddDecisionStrategy([]IntVar{x, y}, ChooseLowestMin, MaxValueFirst)
+	model.AddDecisionStrategy([]IntVar{z}, ChooseFirst, MedianValueFirst)
+
+	strategies := model.pb.GetSearchStrategy()
+	require.Len(t, strategies, 2)
+	require.Equal(t, []int32{x.index(), y.index()}, strategies[0].GetVariables())
+	require.Equal(t, pb.DecisionStrategyProto_CHOOSE_LOWEST_MIN, strategies[0].GetVariableSelectionStrategy())
+	require.Equal(t, pb.DecisionStrategyProto_SELECT_MAX_VALUE, strategies[0].GetDomainReductionStrategy())
+	require.Equal(t, pb.DecisionStrategyProto_SELECT_MEDIAN_VALUE, strategies[1].GetDomainReductionStrategy())
+
+	// Orders given when solving come first.
+	ordered := withVariableOrder(model.pb, &variableOrder{vars: []int32{z.index()}})
+	require.Len(t, ordered.GetSearchStrategy(), 3)
+	require.Equal(t, []int32{z.index()}, ordered.GetSearchStrategy()[0].GetVariables())
+
+	result := model.Solve(WithFixedSearch())
+	require.True(t, result.Optimal() || result.Feasible())
+	require.Equal(t, int64(10), result.Value(x)) // the first decision made
+}
@@ -0,0 +1,20 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package pb contains the types generated from OR-Tools' CP-SAT protos (see
+// README.md). They're used by the solver package's escape hatches, for
+// features not otherwise exposed (see solver.Model.Proto). Unlike the rest of
+// the API, they follow upstream, and may change in incompatible ways when
+// upgrading OR-Tools.
+package pb
@@ -0,0 +1,96 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Phase is one step of a phased solve, see Model.SolvePhased.
+type Phase struct {
+	// MinPriority is the lowest priority of constraints included in the phase;
+	// constraints with lower priorities are left out.
+	MinPriority int
+	// Options configure the solve process for the phase.
+	Options []Option
+}
+
+// AddConstraintsWithPriority is like AddConstraints, except the constraints
+// are tagged with the given priority. Priorities only matter when solving in
+// phases, see Model.SolvePhased; otherwise all constraints are treated alike.
+func (m *Model) AddConstraintsWithPriority(priority int, cs ...Constraint) {
+	lo := len(m.pb.GetConstraints())
+	m.AddConstraints(cs...)
+	hi := len(m.pb.GetConstraints())
+
+	if m.priorities == nil {
+		m.priorities = make(map[int]int)
+	}
+	for idx := lo; idx < hi; idx++ {
+		m.priorities[idx] = priority
+	}
+}
+
+// SolvePhased solves the model in phases, a pragmatic strategy for very hard
+// models. Each phase only includes constraints tagged with a priority of at
+// least the phase's MinPriority (constraints added without one are always
+// included), and is hinted using the solution found in the phase before it.
+// Phases are typically ordered by decreasing MinPriority, with the last one
+// including every constraint.
+//
+// It returns the result of the last phase, or that of the first phase that
+// was found to be infeasible (or invalid) -- later phases only add
+// constraints, so there's no point continuing.
+func (m *Model) SolvePhased(phases []Phase) Result {
+	if len(phases) == 0 {
+		panic("no phases specified")
+	}
+
+	m.finalize()
+	var result Result
+	var hint []int64
+	for _, phase := range phases {
+		result = m.solve(m.phaseModel(phase.MinPriority, hint), phase.Options...)
+		if result.Infeasible() || result.Invalid() {
+			return result
+		}
+		if result.solved() {
+			hint = result.pb.GetSolution()
+		}
+	}
+	return result
+}
+
+// phaseModel returns a copy of the model proto that only includes constraints
+// with at least the given priority, hinted with the given solution (if any).
+// Left out constraints are cleared instead of removed, to not disturb the
+// indexes intervals are referred to by.
+func (m *Model) phaseModel(minPriority int, hint []int64) *pb.CpModelProto {
+	model := proto.Clone(m.pb).(*pb.CpModelProto)
+	for idx, priority := range m.priorities {
+		if priority < minPriority {
+			model.Constraints[idx] = &pb.ConstraintProto{}
+		}
+	}
+	if hint != nil {
+		model.SolutionHint = &pb.PartialVariableAssignment{}
+		for i, v := range hint {
+			model.SolutionHint.Vars = append(model.SolutionHint.Vars, int32(i))
+			model.SolutionHint.Values = append(model.SolutionHint.Values, v)
+		}
+	}
+	return model
+}
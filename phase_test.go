@@ -0,0 +1,64 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseModel(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewAllDifferentConstraint(x, y))
+	model.AddConstraintsWithPriority(2, NewLinearConstraint(Sum(x, y), NewDomain(5, 5)))
+	model.AddConstraintsWithPriority(1, NewAllSameConstraint(x, y))
+
+	phase := model.phaseModel(2, nil)
+	require.Len(t, phase.Constraints, 3)
+	require.NotNil(t, phase.Constraints[0].GetAllDiff())
+	require.NotNil(t, phase.Constraints[1].GetLinear())
+	require.Nil(t, phase.Constraints[2].GetConstraint())
+	require.Nil(t, phase.GetSolutionHint())
+	require.NotNil(t, model.pb.Constraints[2].GetConstraint())
+
+	phase = model.phaseModel(1, []int64{1, 4})
+	for _, ct := range phase.Constraints {
+		require.NotNil(t, ct.GetConstraint())
+	}
+	require.Equal(t, []int32{0, 1}, phase.GetSolutionHint().GetVars())
+	require.Equal(t, []int64{1, 4}, phase.GetSolutionHint().GetValues())
+}
+
+func TestSolvePhased(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraintsWithPriority(2, NewLinearConstraint(Sum(x, y), NewDomain(10, 10)))
+	model.AddConstraintsWithPriority(1, NewLinearConstraint(NewLinearExpr([]IntVar{x, y}, []int64{1, -1}, 0), NewDomain(4, 4)))
+	model.Maximize(Sum(x))
+
+	result := model.SolvePhased([]Phase{{MinPriority: 2}, {MinPriority: 1}})
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(7), result.Value(x))
+	require.Equal(t, int64(3), result.Value(y))
+
+	// Phases that are infeasible cut the process short.
+	model.AddConstraintsWithPriority(3, NewLinearConstraint(Sum(x, y), NewDomain(11, 11)))
+	result = model.SolvePhased([]Phase{{MinPriority: 3}, {MinPriority: 1}})
+	require.True(t, result.Infeasible())
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"time"
+
+	"github.com/irfansharif/solver/internal"
+)
+
+// The profiles below bundle solver parameters for common trade-offs between
+// latency and solution quality. They're applied like any other option, and
+// options listed after a profile override what it configures:
+//
+//	model.Solve(solver.ProfileFast(), solver.WithTimeout(500*time.Millisecond))
+
+// ProfileFast configures the solver for low latency, for interactive use or
+// when solving many small models. The search is cut short after a second, or
+// once a solution is found within 5% of the best possible objective value.
+// Presolve is kept light.
+func ProfileFast() Option {
+	return profile(
+		WithTimeout(time.Second),
+		withRelativeGapLimit(0.05),
+		func(o *options, _ internal.SolveWrapper) {
+			probing, iterations := int32(0), int32(1)
+			o.params.CpModelProbingLevel = &probing
+			o.params.MaxPresolveIterations = &iterations
+		},
+	)
+}
+
+// ProfileBalanced configures the solver to trade latency for solution quality,
+// searching for up to ten seconds (or until a solution is found within 1% of
+// the best possible objective value) using a portfolio of parallel workers.
+// Like WithParallelism, it's incompatible with WithEnumeration.
+func ProfileBalanced() Option {
+	return profile(
+		WithTimeout(10*time.Second),
+		withRelativeGapLimit(0.01),
+		WithParallelism(8),
+	)
+}
+
+// ProfileExhaustive configures the solver to search for (and prove) optimal
+// solutions, stopping only at the given deadline. It uses a portfolio of
+// parallel workers, with more expensive presolve, linear relaxations, and
+// symmetry detection. Like WithParallelism, it's incompatible with
+// WithEnumeration.
+func ProfileExhaustive(deadline time.Duration) Option {
+	return profile(
+		WithTimeout(deadline),
+		withRelativeGapLimit(0),
+		WithParallelism(8),
+		func(o *options, _ internal.SolveWrapper) {
+			linearization, probing, symmetry := int32(2), int32(2), int32(2)
+			o.params.LinearizationLevel = &linearization
+			o.params.CpModelProbingLevel = &probing
+			o.params.SymmetryLevel = &symmetry
+		},
+	)
+}
+
+// profile bundles the given options into one.
+func profile(os ...Option) Option {
+	return func(o *options, s internal.SolveWrapper) {
+		for _, opt := range os {
+			opt(o, s)
+		}
+	}
+}
+
+// withRelativeGapLimit configures the solver to stop once the gap between the
+// objective value and the best bound is within the given fraction of the
+// latter.
+func withRelativeGapLimit(limit float64) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.params.RelativeGapLimit = &limit
+	}
+}
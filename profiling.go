@@ -0,0 +1,91 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"time"
+
+	"github.com/irfansharif/solver/internal"
+	"github.com/irfansharif/solver/pb"
+)
+
+// SolveTimings attributes the time spent solving a model between Go and the
+// native solver; see Result.Timings.
+type SolveTimings struct {
+	// Go is the time spent in Go, preparing the model for the native solver
+	// (applying options, rewriting the model proto) and processing its
+	// response.
+	Go time.Duration
+	// Native is the time spent in the native solve call. It includes the
+	// search itself (see Result.WallTime), passing protos across the language
+	// boundary, and the additional solves made for WithCanonicalSolution.
+	Native time.Duration
+}
+
+// WithProfilerLabels configures the solver to run the native solve with pprof
+// labels identifying the model being solved: its name ("solver.model") and
+// size ("solver.variables", "solver.constraints"). Labels already present in
+// the given context are retained. CPU profiles then attribute samples taken
+// while the calling goroutine is in the native solve to the model; samples
+// from additional search workers, which run on native threads, aren't
+// labeled.
+func WithProfilerLabels(ctx context.Context) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.profiling = append(o.profiling, func(model *pb.CpModelProto, solve func()) {
+			labels := pprof.Labels(
+				"solver.model", model.GetName(),
+				"solver.variables", fmt.Sprintf("%d", len(model.GetVariables())),
+				"solver.constraints", fmt.Sprintf("%d", len(model.GetConstraints())),
+			)
+			pprof.Do(ctx, labels, func(context.Context) { solve() })
+		})
+	}
+}
+
+// WithProfilingHook configures the solver to invoke the native solve through
+// the given function, which must call solve exactly once. It lets applications
+// wrap the native solve with profiling or tracing machinery of their own,
+// like runtime/trace regions. Hooks are nested in the order they're
+// specified, the first being outermost.
+func WithProfilingHook(f func(solve func())) Option {
+	return func(o *options, _ internal.SolveWrapper) {
+		o.profiling = append(o.profiling, func(_ *pb.CpModelProto, solve func()) {
+			f(solve)
+		})
+	}
+}
+
+// profilingHook wraps the native solve of the given model proto.
+type profilingHook func(model *pb.CpModelProto, solve func())
+
+// runNative invokes the given native solve through the given hooks, returning
+// the time spent in the native solve alone.
+func runNative(hooks []profilingHook, model *pb.CpModelProto, solve func()) time.Duration {
+	var native time.Duration
+	run := func() {
+		start := time.Now()
+		solve()
+		native = time.Since(start)
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook, inner := hooks[i], run
+		run = func() { hook(model, inner) }
+	}
+	run()
+	return native
+}
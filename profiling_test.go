@@ -0,0 +1,66 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilingHooks(t *testing.T) {
+	var o options
+	var trace []string
+	for _, opt := range []Option{
+		WithProfilingHook(func(solve func()) {
+			trace = append(trace, "outer:start")
+			solve()
+			trace = append(trace, "outer:end")
+		}),
+		WithProfilerLabels(context.Background()),
+		WithProfilingHook(func(solve func()) {
+			trace = append(trace, "inner:start")
+			solve()
+			trace = append(trace, "inner:end")
+		}),
+	} {
+		opt(&o, nil)
+	}
+	require.Len(t, o.profiling, 3)
+
+	model := &pb.CpModelProto{Name: "m"}
+	native := runNative(o.profiling, model, func() {
+		trace = append(trace, "solve")
+		time.Sleep(time.Millisecond)
+	})
+	require.Equal(t, []string{"outer:start", "inner:start", "solve", "inner:end", "outer:end"}, trace)
+	require.True(t, native >= time.Millisecond)
+}
+
+func TestSolveTimings(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.Maximize(Sum(x))
+	result := model.Solve(WithProfilerLabels(context.Background()))
+	require.True(t, result.Optimal())
+
+	timings := result.Timings()
+	require.True(t, timings.Native > 0)
+	require.True(t, timings.Native >= result.WallTime())
+	require.True(t, timings.Go >= 0)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Proto returns a copy of the model's underlying CP-SAT protobuf
+// representation. It's an escape hatch for features not otherwise exposed: the
+// copy can be inspected, or changed and loaded back as a separate model (see
+// LoadModel), without affecting this one. It's the model as is: deferred
+// constructs yet to be emitted (see Model.Finalize) aren't included; use
+// Export to get hold of the finalized model instead.
+//
+// The types used are those generated from OR-Tools' own protos (see package
+// pb). Unlike the rest of the API, they follow upstream, and may change in
+// incompatible ways when upgrading OR-Tools.
+func (m *Model) Proto() *pb.CpModelProto {
+	return proto.Clone(m.pb).(*pb.CpModelProto)
+}
+
+// VariableProto returns a copy of the given variable's underlying CP-SAT
+// protobuf representation, for features not otherwise exposed (see
+// Model.Proto). Changes to it don't affect the variable. Negated literals
+// return that of the literal they negate.
+func VariableProto(v IntVar) *pb.IntegerVariableProto {
+	return proto.Clone(v.(*intVar).pb).(*pb.IntegerVariableProto)
+}
+
+// ConstraintProtos returns copies of the given constraint's underlying CP-SAT
+// protobuf representations, for features not otherwise exposed (see
+// Model.Proto). Changes to them don't affect the constraint.
+func ConstraintProtos(c Constraint) []*pb.ConstraintProto {
+	return cloneConstraintProtos(c.protos())
+}
+
+// Proto returns a copy of the result's underlying CP-SAT protobuf
+// representation, for details not otherwise exposed (search statistics, say).
+func (r Result) Proto() *pb.CpSolverResponse {
+	if r.pb == nil {
+		return &pb.CpSolverResponse{}
+	}
+	return proto.Clone(r.pb).(*pb.CpSolverResponse)
+}
+
+// cloneConstraintProtos returns copies of the given constraint protos.
+func cloneConstraintProtos(protos []*pb.ConstraintProto) []*pb.ConstraintProto {
+	clones := make([]*pb.ConstraintProto, len(protos))
+	for i, ct := range protos {
+		clones[i] = proto.Clone(ct).(*pb.ConstraintProto)
+	}
+	return clones
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProtoAccessors(t *testing.T) {
+	model := NewModel("protos")
+	x := model.NewIntVar(0, 10, "x")
+	l := model.NewLiteral("l")
+	c := NewLinearConstraint(Sum(x), NewDomain(2, 4)).OnlyEnforceIf(l)
+	model.AddConstraints(c)
+
+	p := model.Proto()
+	require.True(t, proto.Equal(model.pb, p))
+	p.Variables[0].Domain = []int64{0, 5}
+	require.Equal(t, []int64{0, 10}, model.pb.GetVariables()[0].GetDomain())
+
+	// The copy can be loaded back as a separate model.
+	buf, err := proto.Marshal(p)
+	require.NoError(t, err)
+	loaded, err := LoadModel(bytes.NewReader(buf))
+	require.NoError(t, err)
+	require.Equal(t, "x in [0, 5]", loaded.Variables()[0].String())
+
+	vp := VariableProto(x)
+	require.Equal(t, "x", vp.GetName())
+	vp.Name = "y"
+	require.Equal(t, "x in [0, 10]", x.String())
+	require.Equal(t, "l", VariableProto(l.Not()).GetName())
+
+	cps := ConstraintProtos(c)
+	require.Len(t, cps, 1)
+	require.Equal(t, []int32{1}, cps[0].GetEnforcementLiteral())
+	cps[0].EnforcementLiteral = nil
+	require.Equal(t, []int32{1}, c.protos()[0].GetEnforcementLiteral())
+
+	result := Result{pb: &pb.CpSolverResponse{Status: pb.CpSolverStatus_OPTIMAL, NumBranches: 42}}
+	rp := result.Proto()
+	require.Equal(t, int64(42), rp.GetNumBranches())
+	rp.NumBranches = 0
+	require.Equal(t, int64(42), result.pb.GetNumBranches())
+	require.NotNil(t, Result{}.Proto())
+}
@@ -0,0 +1,228 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// ErrInvalidReference is returned by Model.CheckReferences when the model
+// refers to variables or intervals that don't exist, or uses non-boolean
+// variables as literals.
+var ErrInvalidReference = errors.New("invalid reference")
+
+// CheckReferences checks that every variable and interval referred to by the
+// model's constraints, objective and hints exist, and that literals refer to
+// boolean variables. These are checked by Validate too, but the errors here
+// are more readable. The returned error, if any, wraps ErrInvalidReference.
+func (m *Model) CheckReferences() error {
+	m = m.finalized()
+	c := &referenceChecker{model: m.pb}
+	for i, ct := range m.pb.GetConstraints() {
+		if err := c.constraint(ct); err != nil {
+			return fmt.Errorf("%w: constraint #%d%s (%s): %v",
+				ErrInvalidReference, i, m.constraintLocation(i), constraintDescription(ct), err)
+		}
+	}
+	if err := c.variables(m.pb.GetObjective().GetVars()...); err != nil {
+		return fmt.Errorf("%w: objective: %v", ErrInvalidReference, err)
+	}
+	if err := c.variables(m.pb.GetSolutionHint().GetVars()...); err != nil {
+		return fmt.Errorf("%w: hint: %v", ErrInvalidReference, err)
+	}
+	if err := c.literals(m.pb.GetAssumptions()...); err != nil {
+		return fmt.Errorf("%w: assumptions: %v", ErrInvalidReference, err)
+	}
+	return nil
+}
+
+// referenceChecker checks the variable and interval references in a model.
+type referenceChecker struct {
+	model *pb.CpModelProto
+}
+
+// variables checks that the variables with the given indexes exist. Negative
+// indexes refer to negated variables.
+func (c *referenceChecker) variables(refs ...int32) error {
+	for _, ref := range refs {
+		idx := ref
+		if ref < 0 {
+			idx = -ref - 1
+		}
+		if n := len(c.model.GetVariables()); int(idx) >= n {
+			return fmt.Errorf("variable #%d doesn't exist (%d variables)", idx, n)
+		}
+	}
+	return nil
+}
+
+// literals checks that the variables with the given indexes exist, and are
+// boolean.
+func (c *referenceChecker) literals(refs ...int32) error {
+	if err := c.variables(refs...); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		idx := ref
+		if ref < 0 {
+			idx = -ref - 1
+		}
+		variable := c.model.GetVariables()[idx]
+		d := variable.GetDomain()
+		if len(d) == 0 || d[0] < 0 || d[len(d)-1] > 1 {
+			return fmt.Errorf("literal refers to non-boolean variable %s (#%d) with domain %s",
+				variable.GetName(), idx, &domain{intervals: d})
+		}
+	}
+	return nil
+}
+
+// linear checks the variables referred to by the given linear expressions.
+func (c *referenceChecker) linear(exprs ...*pb.LinearExpressionProto) error {
+	for _, e := range exprs {
+		if err := c.variables(e.GetVars()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// intervals checks that the constraints with the given indexes exist, and are
+// intervals.
+func (c *referenceChecker) intervals(idxs ...int32) error {
+	for _, idx := range idxs {
+		if n := len(c.model.GetConstraints()); idx < 0 || int(idx) >= n {
+			return fmt.Errorf("interval #%d doesn't exist (%d constraints)", idx, n)
+		}
+		if c.model.GetConstraints()[idx].GetInterval() == nil {
+			return fmt.Errorf("constraint #%d isn't an interval", idx)
+		}
+	}
+	return nil
+}
+
+// constraint checks all the references in the given constraint.
+func (c *referenceChecker) constraint(ct *pb.ConstraintProto) error {
+	refs := referencesOf(ct)
+	if err := c.literals(refs.literals...); err != nil {
+		return err
+	}
+	if err := c.variables(refs.variables...); err != nil {
+		return err
+	}
+	return c.intervals(refs.intervals...)
+}
+
+// references are the variables, literals and intervals referred to by a
+// constraint. Negative variable and literal indexes refer to negated
+// variables; interval indexes refer to interval constraints.
+type references struct {
+	variables, literals, intervals []int32
+}
+
+// referencesOf returns the references in the given constraint, including its
+// enforcement literals.
+func referencesOf(ct *pb.ConstraintProto) references {
+	refs := references{literals: ct.GetEnforcementLiteral()}
+	vars := func(vs ...int32) {
+		refs.variables = append(refs.variables, vs...)
+	}
+	lits := func(ls ...int32) {
+		refs.literals = append(append([]int32(nil), refs.literals...), ls...)
+	}
+	linear := func(exprs ...*pb.LinearExpressionProto) {
+		for _, e := range exprs {
+			vars(e.GetVars()...)
+		}
+	}
+	intervals := func(is ...int32) {
+		refs.intervals = append(refs.intervals, is...)
+	}
+
+	switch ct.GetConstraint().(type) {
+	case *pb.ConstraintProto_BoolOr:
+		lits(ct.GetBoolOr().GetLiterals()...)
+	case *pb.ConstraintProto_BoolAnd:
+		lits(ct.GetBoolAnd().GetLiterals()...)
+	case *pb.ConstraintProto_AtMostOne:
+		lits(ct.GetAtMostOne().GetLiterals()...)
+	case *pb.ConstraintProto_ExactlyOne:
+		lits(ct.GetExactlyOne().GetLiterals()...)
+	case *pb.ConstraintProto_BoolXor:
+		lits(ct.GetBoolXor().GetLiterals()...)
+	case *pb.ConstraintProto_IntDiv:
+		arg := ct.GetIntDiv()
+		vars(append([]int32{arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_IntMod:
+		arg := ct.GetIntMod()
+		vars(append([]int32{arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_IntMax:
+		arg := ct.GetIntMax()
+		vars(append([]int32{arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_IntMin:
+		arg := ct.GetIntMin()
+		vars(append([]int32{arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_IntProd:
+		arg := ct.GetIntProd()
+		vars(append([]int32{arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_LinMax:
+		arg := ct.GetLinMax()
+		linear(append([]*pb.LinearExpressionProto{arg.GetTarget()}, arg.GetExprs()...)...)
+	case *pb.ConstraintProto_LinMin:
+		arg := ct.GetLinMin()
+		linear(append([]*pb.LinearExpressionProto{arg.GetTarget()}, arg.GetExprs()...)...)
+	case *pb.ConstraintProto_Linear:
+		vars(ct.GetLinear().GetVars()...)
+	case *pb.ConstraintProto_AllDiff:
+		vars(ct.GetAllDiff().GetVars()...)
+	case *pb.ConstraintProto_Element:
+		arg := ct.GetElement()
+		vars(append([]int32{arg.GetIndex(), arg.GetTarget()}, arg.GetVars()...)...)
+	case *pb.ConstraintProto_Circuit:
+		lits(ct.GetCircuit().GetLiterals()...)
+	case *pb.ConstraintProto_Routes:
+		lits(ct.GetRoutes().GetLiterals()...)
+	case *pb.ConstraintProto_Table:
+		vars(ct.GetTable().GetVars()...)
+	case *pb.ConstraintProto_Automaton:
+		vars(ct.GetAutomaton().GetVars()...)
+	case *pb.ConstraintProto_Inverse:
+		arg := ct.GetInverse()
+		vars(arg.GetFDirect()...)
+		vars(arg.GetFInverse()...)
+	case *pb.ConstraintProto_Reservoir:
+		arg := ct.GetReservoir()
+		vars(arg.GetTimes()...)
+		lits(arg.GetActives()...)
+	case *pb.ConstraintProto_Interval:
+		arg := ct.GetInterval()
+		vars(arg.GetStart(), arg.GetEnd(), arg.GetSize())
+		linear(arg.GetStartView(), arg.GetEndView(), arg.GetSizeView())
+	case *pb.ConstraintProto_NoOverlap:
+		intervals(ct.GetNoOverlap().GetIntervals()...)
+	case *pb.ConstraintProto_NoOverlap_2D:
+		arg := ct.GetNoOverlap_2D()
+		intervals(arg.GetXIntervals()...)
+		intervals(arg.GetYIntervals()...)
+	case *pb.ConstraintProto_Cumulative:
+		arg := ct.GetCumulative()
+		vars(append([]int32{arg.GetCapacity()}, arg.GetDemands()...)...)
+		intervals(arg.GetIntervals()...)
+	}
+	return refs
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCheckReferences(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	x := model.NewIntVar(0, 10, "x")
+	i := model.NewInterval(x, x, model.NewConstant(0, "zero"), "i")
+	model.AddConstraints(
+		NewBooleanOrConstraint(a, a.Not()),
+		NewNonOverlappingConstraint(i),
+		NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(a.Not()),
+	)
+	model.Minimize(Sum(x))
+	model.AddHint(x, 4)
+	require.NoError(t, model.CheckReferences())
+
+	for _, tc := range []struct {
+		mutate func(m *pb.CpModelProto)
+		err    string
+	}{
+		{
+			mutate: func(m *pb.CpModelProto) { m.Constraints[2].GetNoOverlap().Intervals[0] = 7 },
+			err:    "invalid reference: constraint #2 (*pb.ConstraintProto_NoOverlap): interval #7 doesn't exist (4 constraints)",
+		},
+		{
+			mutate: func(m *pb.CpModelProto) { m.Constraints[2].GetNoOverlap().Intervals[0] = 1 },
+			err:    "invalid reference: constraint #2 (*pb.ConstraintProto_NoOverlap): constraint #1 isn't an interval",
+		},
+		{
+			mutate: func(m *pb.CpModelProto) { m.Constraints[1].GetBoolOr().Literals[0] = 1 },
+			err:    "invalid reference: constraint #1 (*pb.ConstraintProto_BoolOr): literal refers to non-boolean variable x (#1) with domain [0, 10]",
+		},
+		{
+			mutate: func(m *pb.CpModelProto) {
+				m.Constraints[3].Name = "enforced"
+				m.Constraints[3].EnforcementLiteral[0] = -4
+			},
+			err: "invalid reference: constraint #3 (enforced): variable #3 doesn't exist (3 variables)",
+		},
+		{
+			mutate: func(m *pb.CpModelProto) { m.Objective.Vars[0] = 42 },
+			err:    "invalid reference: objective: variable #42 doesn't exist (3 variables)",
+		},
+		{
+			mutate: func(m *pb.CpModelProto) { m.SolutionHint.Vars[0] = 42 },
+			err:    "invalid reference: hint: variable #42 doesn't exist (3 variables)",
+		},
+	} {
+		m := &Model{pb: proto.Clone(model.pb).(*pb.CpModelProto)}
+		tc.mutate(m.pb)
+		err := m.CheckReferences()
+		require.True(t, errors.Is(err, ErrInvalidReference))
+		require.EqualError(t, err, tc.err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// Reify ties the given constraint to the literal: the constraint is enforced
+// iff the literal is true, and its negation iff the literal is false. This is
+// also called full reification; OnlyEnforceIf (half-reification) only
+// provides the first half of it.
+//
+// Only constraints that can be negated are supported:
+//   - NewLinearConstraint (and the comparison constraints built on it, like
+//     NewEqualityConstraint)
+//   - NewBooleanAndConstraint, NewBooleanOrConstraint and NewImplicationConstraint
+//   - NewAtMostKConstraint, NewAtLeastKConstraint and NewExactlyKConstraint
+//
+// The constraint must not already be enforced by other literals.
+func Reify(c Constraint, lit Literal) Constraint {
+	str := fmt.Sprintf("reified: %s ⇔ %s", lit.name(), c.String())
+	cc, ok := c.(*constraint)
+	if !ok {
+		return invalidConstraint([]IntVar{lit}, "%s: unsupported constraint", str)
+	}
+	if len(cc.enforcement) != 0 {
+		return invalidConstraint([]IntVar{lit}, "%s: constraint already enforced", str)
+	}
+
+	cc.linearizeForEnforcement()
+	negated, ok := negation(cc, lit)
+	if !ok {
+		return invalidConstraint([]IntVar{lit}, "%s: constraint can't be negated", str)
+	}
+	return constraints{
+		cs:  []Constraint{cc.OnlyEnforceIf(lit), negated},
+		str: str,
+	}
+}
+
+// NewDomainLiteral returns a new literal that's true iff the given variable's
+// value lies in the given domain.
+func (m *Model) NewDomainLiteral(x IntVar, d Domain) Literal {
+	lit := m.NewLiteral(derivedName(protoName(x), "inDomain"))
+	m.AddConstraints(Reify(NewLinearConstraint(Sum(x), d), lit))
+	return lit
+}
+
+// negation returns the negation of the given constraint, enforced iff the given
+// literal is false, and whether the constraint could be negated.
+func negation(c *constraint, lit Literal) (Constraint, bool) {
+	ct := arenaFor(lit).constraintProto()
+	switch c.pb.Constraint.(type) {
+	case *pb.ConstraintProto_Linear:
+		arg := c.pb.GetLinear()
+		d := (&domain{intervals: arg.GetDomain()}).complement()
+		if len(d.intervals) == 0 {
+			// The constraint always holds, so the literal can't be false.
+			return NewBooleanOrConstraint(lit), true
+		}
+		ct.Constraint = &pb.ConstraintProto_Linear{
+			Linear: &pb.LinearConstraintProto{
+				Vars:   arg.GetVars(),
+				Coeffs: arg.GetCoeffs(),
+				Domain: d.intervals,
+			},
+		}
+	case *pb.ConstraintProto_BoolAnd:
+		ct.Constraint = &pb.ConstraintProto_BoolOr{
+			BoolOr: &pb.BoolArgumentProto{
+				Literals: negatedLiterals(c.pb.GetBoolAnd().GetLiterals()),
+			},
+		}
+	case *pb.ConstraintProto_BoolOr:
+		ct.Constraint = &pb.ConstraintProto_BoolAnd{
+			BoolAnd: &pb.BoolArgumentProto{
+				Literals: negatedLiterals(c.pb.GetBoolOr().GetLiterals()),
+			},
+		}
+	default:
+		return nil, false
+	}
+
+	negated := &constraint{pb: ct, str: fmt.Sprintf("¬(%s)", c.str)}
+	return negated.OnlyEnforceIf(lit.Not()), true
+}
+
+// negatedLiterals returns the indexes of the negations of the literals with the
+// given indexes.
+func negatedLiterals(refs []int32) []int32 {
+	negated := make([]int32, len(refs))
+	for i, ref := range refs {
+		negated[i] = -ref - 1
+	}
+	return negated
+}
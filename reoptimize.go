@@ -0,0 +1,150 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"math"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// ModelChange is a structured change to a model, applied when re-solving it;
+// see Model.Reoptimize.
+type ModelChange func(m *Model)
+
+// AddConstraintsChange adds the given constraints to the model.
+func AddConstraintsChange(cs ...Constraint) ModelChange {
+	return func(m *Model) {
+		m.AddConstraints(cs...)
+	}
+}
+
+// RemoveConstraintChange removes the given constraint, previously added to the
+// model, from it. Intervals can't be removed, since other constraints refer to
+// them.
+func RemoveConstraintChange(c Constraint) ModelChange {
+	return func(m *Model) {
+		m.removeConstraint(c)
+	}
+}
+
+// SetDomainChange changes the domain of the given variable. Negated literals
+// are rejected (see WithConstructionErrors); change the domain of the literal
+// they negate instead.
+func SetDomainChange(v IntVar, d Domain) ModelChange {
+	return func(m *Model) {
+		m.assertMutable()
+		if v.index() < 0 {
+			m.fail("cannot set the domain of negated literal %s", v.name())
+			return
+		}
+		d := d.(*domain)
+		m.pb.GetVariables()[v.index()].Domain = d.intervals
+		if iv, ok := v.(*intVar); ok {
+			iv.d = d
+		}
+	}
+}
+
+// Reoptimize applies the given changes to the model and re-solves it, picking
+// up from the previous result: its solution is used to hint the solver (see
+// Model.AddHint), replacing existing hints. If the previous solution still
+// satisfies the changed model, it's also used to cut off worse solutions
+// (see WithObjectiveCutoff), the result then being at least as good. It
+// packages up the loop of re-planning as the model changes over time. Changes
+// persist in the model, so they're built upon by subsequent calls.
+func (m *Model) Reoptimize(prev Result, changes []ModelChange, os ...Option) Result {
+	for _, change := range changes {
+		change(m)
+	}
+	m.finalize()
+
+	solution := prev.pb.GetSolution()
+	if !prev.solved() || len(solution) == 0 {
+		return m.solve(m.pb, os...)
+	}
+
+	hint := &pb.PartialVariableAssignment{}
+	for i := range m.pb.GetVariables() {
+		if i >= len(solution) {
+			break // variables added since
+		}
+		hint.Vars = append(hint.Vars, int32(i))
+		hint.Values = append(hint.Values, solution[i])
+	}
+	m.pb.SolutionHint = hint
+
+	if objective := m.pb.GetObjective(); objective != nil && m.verify(solution) == nil {
+		// The previous solution is still feasible; look for ones at least as
+		// good. Cutoffs are strict, hence the ±1. Options specified by the
+		// caller take precedence.
+		value := int64(math.Round(prev.ObjectiveValue()))
+		cutoff := WithObjectiveCutoff(value+1, Minimization)
+		if objective.GetScalingFactor() < 0 {
+			cutoff = WithObjectiveCutoff(value-1, Maximization)
+		}
+		os = append([]Option{cutoff}, os...)
+	}
+	return m.solve(m.pb, os...)
+}
+
+// removeConstraint removes the given constraint from the model, returning
+// whether it did. Its protos are replaced in place with trivially satisfied
+// ones, since intervals are referred to by their position in the model.
+func (m *Model) removeConstraint(c Constraint) bool {
+	m.assertMutable()
+	if _, ok := c.(Interval); ok {
+		m.fail("cannot remove interval %s", c.String())
+		return false
+	}
+
+	removed := make(map[*pb.ConstraintProto]bool)
+	for _, ct := range m.protosOf(c) {
+		removed[ct] = true
+	}
+	var found bool
+	for i, ct := range m.pb.GetConstraints() {
+		if removed[ct] {
+			m.pb.Constraints[i] = &pb.ConstraintProto{
+				Constraint: &pb.ConstraintProto_BoolAnd{BoolAnd: &pb.BoolArgumentProto{}},
+			}
+			found = true
+		}
+	}
+	if !found {
+		m.fail("cannot remove constraint %s: not part of the model", c.String())
+		return false
+	}
+
+	// Stop holding onto the constraint for String(), checking by its protos
+	// since constraints aren't necessarily comparable.
+	var constraints []Constraint
+	var added []string
+	for i, existing := range m.constraints {
+		protos := m.protosOf(existing)
+		if len(protos) > 0 && removed[protos[0]] {
+			continue
+		}
+		constraints = append(constraints, existing)
+		if m.callers != nil {
+			added = append(added, m.callers.added[i])
+		}
+	}
+	m.constraints = constraints
+	if m.callers != nil {
+		m.callers.added = added
+	}
+	return true
+}
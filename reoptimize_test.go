@@ -0,0 +1,82 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelChanges(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	itv := model.NewInterval(x, y, model.NewConstant(2, "size"), "itv")
+	c := NewAllDifferentConstraint(x, y)
+	model.AddConstraints(c, NewLinearConstraint(Sum(x, y), NewDomain(0, 12)))
+
+	RemoveConstraintChange(c)(model)
+	require.Len(t, model.pb.GetConstraints(), 3)
+	require.NotNil(t, model.pb.GetConstraints()[1].GetBoolAnd())
+	require.Len(t, model.constraints, 1)
+	require.NotContains(t, model.String(), "all-different")
+
+	SetDomainChange(x, NewDomain(3, 5))(model)
+	require.Equal(t, []int64{3, 5}, model.pb.GetVariables()[x.index()].GetDomain())
+	require.Equal(t, "x in [3, 5]", x.String())
+
+	l := model.NewLiteral("l")
+	SetDomainChange(l.Not(), NewDomain(1, 1))(model)
+	require.Equal(t, []int64{0, 1}, model.pb.GetVariables()[l.index()].GetDomain())
+
+	RemoveConstraintChange(itv)(model)
+	RemoveConstraintChange(NewAllSameConstraint(x, y))(model)
+	require.EqualError(t, model.Err(), "3 construction errors: "+
+		"cannot set the domain of negated literal ~l; "+
+		"cannot remove interval [x, y | size]; "+
+		"cannot remove constraint all-same: x, y: not part of the model")
+}
+
+func TestReoptimize(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	limit := NewLinearConstraint(Sum(x, y), NewDomain(0, 12))
+	model.AddConstraints(limit)
+	model.Maximize(NewLinearExpr([]IntVar{x, y}, []int64{2, 1}, 0))
+
+	// A previous plan, with x = 10 and y = 2.
+	prev := Result{pb: &pb.CpSolverResponse{
+		Status:         pb.CpSolverStatus_OPTIMAL,
+		Solution:       []int64{10, 2},
+		ObjectiveValue: 22,
+	}}
+
+	// Loosening the limit keeps the previous plan feasible, and lets us do
+	// better.
+	result := model.Reoptimize(prev, []ModelChange{
+		RemoveConstraintChange(limit),
+		AddConstraintsChange(NewLinearConstraint(Sum(x, y), NewDomain(0, 15))),
+	})
+	require.Equal(t, []int64{10, 2}, model.pb.GetSolutionHint().GetValues())
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(25), result.ObjectiveValue())
+
+	// Capping x renders the previous plan infeasible; there's no cutoff.
+	result = model.Reoptimize(result, []ModelChange{SetDomainChange(x, NewDomain(0, 4))})
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(18), result.ObjectiveValue())
+	require.NoError(t, model.verify(result.pb.GetSolution()))
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// Resource is a pool that intervals are registered against, one that can only
+// ever be used by a bounded number of them at a time. Disjunctive resources
+// are used by at most one interval at any point in time; cumulative ones are
+// shared by intervals, each with a demand, as long as their total demand
+// never exceeds the resource's capacity.
+//
+// Resources let model-building code register tasks in any order without
+// needing to carry around slices of intervals. The underlying no-overlap or
+// cumulative constraint is only added once the model is finalized (see
+// Model.Finalize), which happens when it's first solved. It's not possible to
+// register intervals with a resource after that point. Validating, exporting
+// or otherwise inspecting the model doesn't finalize it.
+type Resource struct {
+	model    *Model
+	name     string
+	capacity IntVar // nil for disjunctive resources
+
+	intervals []Interval
+	demands   []IntVar
+	finalized bool
+}
+
+// AddDisjunctiveResource adds a new resource to the model, one that can be
+// used by at most one of its registered intervals at a time.
+func (m *Model) AddDisjunctiveResource(name string) *Resource {
+	r := &Resource{model: m, name: name}
+	m.onFinalize(r.finalize)
+	return r
+}
+
+// AddCumulativeResource adds a new resource to the model, one where the total
+// demand of its registered intervals never exceeds the given capacity at any
+// point in time.
+func (m *Model) AddCumulativeResource(name string, capacity IntVar) *Resource {
+	r := &Resource{model: m, name: name, capacity: capacity}
+	m.onFinalize(r.finalize)
+	return r
+}
+
+// Register registers the given interval with the resource. The demand is what
+// the interval consumes of a cumulative resource's capacity; it's ignored for
+// (and can be nil with) disjunctive resources.
+func (r *Resource) Register(itv Interval, demand IntVar) {
+	if r.finalized {
+		panic("resource already finalized")
+	}
+	if r.capacity != nil && demand == nil {
+		r.model.fail("resource %q: cumulative resources need a demand for each interval, found none for %s",
+			r.name, itv.name())
+		return
+	}
+	r.intervals = append(r.intervals, itv)
+	r.demands = append(r.demands, demand)
+}
+
+// Intervals returns the intervals registered with the resource.
+func (r *Resource) Intervals() []Interval {
+	return r.intervals
+}
+
+// finalize adds the constraint backing the resource to the given model (the
+// resource's own, or a clone of it), if any intervals were registered with it.
+// Only finalizing the resource's own model closes it to further registrations.
+func (r *Resource) finalize(m *Model) {
+	if m == r.model {
+		r.finalized = true
+	}
+	if len(r.intervals) == 0 {
+		return
+	}
+
+	var c Constraint
+	if r.capacity == nil {
+		c = NewNonOverlappingConstraint(r.intervals...)
+	} else {
+		c = NewCumulativeConstraint(r.capacity, r.intervals, r.demands)
+	}
+	if r.name != "" {
+		c = c.WithName(r.name)
+	}
+	m.AddConstraints(c)
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResource(t *testing.T) {
+	model := NewModel("")
+	machine := model.AddDisjunctiveResource("machine")
+	crew := model.AddCumulativeResource("crew", model.NewConstant(3, "capacity"))
+
+	var starts []IntVar
+	for i, size := range []int64{2, 3, 4} {
+		start := model.NewIntVar(0, 10, fmt.Sprintf("s%d", i))
+		end := model.NewIntVar(0, 10, fmt.Sprintf("e%d", i))
+		itv := model.NewInterval(start, end, model.NewConstant(size, ""), fmt.Sprintf("i%d", i))
+
+		// Register tasks against resources in whatever order.
+		crew.Register(itv, model.NewConstant(2, ""))
+		if i != 1 {
+			machine.Register(itv, nil)
+		}
+		starts = append(starts, start)
+	}
+	require.Len(t, machine.Intervals(), 2)
+	require.Len(t, crew.Intervals(), 3)
+
+	// Constraints are only added when the model is finalized.
+	require.Len(t, model.pb.GetConstraints(), 3)
+	model.Minimize(Sum(starts...))
+	result := model.Solve()
+	require.Len(t, model.pb.GetConstraints(), 5)
+	require.Equal(t, "machine", model.pb.GetConstraints()[3].GetName())
+	require.Panics(t, func() { machine.Register(machine.Intervals()[0], nil) })
+
+	// With a demand of 2 against a capacity of 3, the crew can only work on
+	// one task at a time.
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(0+2+5), result.ObjectiveValue())
+}
+
+func TestResourceWithoutIntervals(t *testing.T) {
+	model := NewModel("")
+	model.AddDisjunctiveResource("unused")
+	model.AddCumulativeResource("unused", model.NewConstant(1, ""))
+	model.Finalize()
+
+	require.Len(t, model.pb.GetConstraints(), 0)
+}
+
+func TestResourceObservedBeforeSolving(t *testing.T) {
+	model := NewModel("")
+	machine := model.AddDisjunctiveResource("machine")
+	v := model.NewIntVar(0, 10, "")
+	machine.Register(model.NewInterval(v, v, model.NewConstant(0, ""), "a"), nil)
+
+	// Inspecting the model accounts for the resource, without closing it.
+	// Proto returns the model as is, without it.
+	require.Len(t, model.Proto().GetConstraints(), 1)
+	require.Contains(t, model.ExportTextProto(), `name: "machine"`)
+	require.Equal(t, 2, model.UsageCounts()[0].Count)
+	require.Len(t, model.pb.GetConstraints(), 1)
+
+	machine.Register(model.NewInterval(v, v, model.NewConstant(0, ""), "b"), nil)
+	model.Finalize()
+	require.Len(t, model.pb.GetConstraints(), 3)
+	require.Len(t, model.pb.GetConstraints()[2].GetNoOverlap().GetIntervals(), 2)
+}
+
+func TestResourceRequiresDemand(t *testing.T) {
+	model := NewModel("")
+	crew := model.AddCumulativeResource("crew", model.NewConstant(1, ""))
+	v := model.NewIntVar(0, 10, "")
+	itv := model.NewInterval(v, v, model.NewConstant(0, ""), "")
+	require.Panics(t, func() { crew.Register(itv, nil) })
+}
@@ -15,12 +15,114 @@
 package solver
 
 import (
-	"github.com/irfansharif/solver/internal/pb"
+	"fmt"
+	"time"
+
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
 )
 
-// Result is what's returned after attempting to solve a model.
+// Result is what's returned after attempting to solve a model. It's a Go copy
+// of what the underlying solver returned, decoupled from native memory, and so
+// it's safe to retain after the solve and to read from multiple goroutines.
+// Results are not to be modified; use Clone to get a copy that can be.
 type Result struct {
 	pb *pb.CpSolverResponse
+
+	// solutions is the number of solutions found at the time the result was
+	// generated; see SolutionCount.
+	solutions int64
+
+	// err is set if the model wasn't solved due to invalid options.
+	err error
+
+	// coverage is set if the model was solved with a coverage objective.
+	coverage *coverage
+
+	// objectiveScale is set if the model was solved with a fractional
+	// objective; see Model.MinimizeFloat.
+	objectiveScale int64
+
+	// vars is set if the model was solved with WithTightenedDomains.
+	vars []IntVar
+
+	// assumptions are the model's assumptions, if any; see
+	// Model.AddAssumptions.
+	assumptions []Literal
+
+	// timings captures where the time was spent solving the model.
+	timings SolveTimings
+
+	// cached is set if the result was retrieved from a cache; see WithCache.
+	cached bool
+}
+
+// coverage captures the literals (and their weights) making up a coverage
+// objective. See Model.MaximizeCoverage.
+type coverage struct {
+	literals []Literal
+	weights  []int64
+}
+
+// Status is the outcome of an attempt to solve a model.
+type Status int
+
+//go:generate stringer -type=Status
+const (
+	// Unknown is the status of results for which the search terminated (due to
+	// time limits, say) before finding a solution or proving that there are
+	// none.
+	Unknown Status = iota
+	// Optimal is the status of results with optimal solutions; see
+	// Result.Optimal.
+	Optimal
+	// Feasible is the status of results with feasible solutions; see
+	// Result.Feasible.
+	Feasible
+	// Infeasible is the status of results for models proven to be infeasible.
+	Infeasible
+	// Invalid is the status of results for invalid models, or ones solved with
+	// incompatible options; see Result.Err.
+	Invalid
+)
+
+// Clone returns a deep copy of the result.
+func (r Result) Clone() Result {
+	c := r
+	if r.pb != nil {
+		c.pb = proto.Clone(r.pb).(*pb.CpSolverResponse)
+	}
+	if r.coverage != nil {
+		c.coverage = &coverage{
+			literals: append([]Literal(nil), r.coverage.literals...),
+			weights:  append([]int64(nil), r.coverage.weights...),
+		}
+	}
+	if r.vars != nil {
+		c.vars = append([]IntVar(nil), r.vars...)
+	}
+	if r.assumptions != nil {
+		c.assumptions = append([]Literal(nil), r.assumptions...)
+	}
+	return c
+}
+
+// Status returns the outcome of the attempt to solve the model. Unlike the
+// Optimal, Feasible, Infeasible and Invalid predicates, it also captures
+// searches that terminated without a conclusive outcome.
+func (r Result) Status() Status {
+	switch r.pb.GetStatus() {
+	case pb.CpSolverStatus_OPTIMAL:
+		return Optimal
+	case pb.CpSolverStatus_FEASIBLE:
+		return Feasible
+	case pb.CpSolverStatus_INFEASIBLE:
+		return Infeasible
+	case pb.CpSolverStatus_MODEL_INVALID:
+		return Invalid
+	default:
+		return Unknown
+	}
 }
 
 // Optimal is true iff a feasible solution has been found.
@@ -51,20 +153,147 @@ func (r Result) Invalid() bool {
 	return r.pb.Status == pb.CpSolverStatus_MODEL_INVALID
 }
 
+// solved returns whether the result has a solution.
+func (r Result) solved() bool {
+	return r.Optimal() || r.Feasible()
+}
+
+// Err returns the error that prevented the model from being solved, if any.
+// It's set when the options provided to Solve are incompatible with one
+// another, in which case the result is also Invalid().
+func (r Result) Err() error {
+	return r.err
+}
+
 // Value returns the decided value of the given IntVar. This is only valid to
 // use if the result is optimal or feasible.
 func (r Result) Value(iv IntVar) int64 {
+	if l, ok := iv.(Literal); ok && l.isNegated() {
+		return 1 - r.Value(l.Not())
+	}
 	return r.pb.GetSolution()[iv.index()]
 }
 
 // BooleanValue returns the decided value of the given Literal. This is only
 // valid to use if the result is optimal or feasible.
 func (r Result) BooleanValue(l Literal) bool {
-	if l.isNegated() {
-		return r.Value(l.Not()) == 0
+	return r.Value(l) == 1
+}
+
+// IsValueInDomain returns true iff the decided value of the given IntVar lies
+// in the given domain. This is only valid to use if the result is optimal or
+// feasible.
+func (r Result) IsValueInDomain(iv IntVar, d Domain) bool {
+	return d.contains(r.Value(iv))
+}
+
+// AsBoolMap returns the decided values of the given literals, keyed by their
+// names. It panics if distinct literals share a name (unnamed ones included),
+// as they'd be indistinguishable. This is only valid to use if the result is
+// optimal or feasible.
+func (r Result) AsBoolMap(literals []Literal) map[string]bool {
+	m := make(map[string]bool, len(literals))
+	seen := make(map[string]int32, len(literals))
+	for _, l := range literals {
+		label := l.label()
+		if idx, ok := seen[label]; ok && idx != l.index() {
+			panic(fmt.Sprintf("distinct literals named %s", l))
+		}
+		seen[label] = l.index()
+		m[label] = r.BooleanValue(l)
 	}
+	return m
+}
 
-	return r.Value(l) == 1
+// Coverage returns, for models solved with a coverage objective (see
+// Model.MaximizeCoverage), the total weight of the literals that are true
+// alongside the total weight of all of them. The uncovered literals, the ones
+// that are false, are also returned. Results that aren't optimal or feasible
+// have nothing covered, and no uncovered literals to report, only the total.
+func (r Result) Coverage() (covered, total int64, uncovered []Literal) {
+	if r.coverage == nil {
+		panic("result not from a model with a coverage objective")
+	}
+
+	for i, l := range r.coverage.literals {
+		total += r.coverage.weights[i]
+		if !r.solved() {
+			continue
+		}
+		if r.BooleanValue(l) {
+			covered += r.coverage.weights[i]
+		} else {
+			uncovered = append(uncovered, l)
+		}
+	}
+	return covered, total, uncovered
+}
+
+// TightenedDomain returns the domain of the given variable, as tightened by
+// the solver, for models solved using WithTightenedDomains. It's a superset
+// of the values the variable takes on in feasible solutions (for
+// optimization problems, in optimal ones).
+func (r Result) TightenedDomain(iv IntVar) Domain {
+	if r.vars == nil {
+		panic("result not from a model solved with tightened domains")
+	}
+	if l, ok := iv.(Literal); ok && l.isNegated() {
+		d := r.TightenedDomain(l.Not()).list(0)
+		negated := make([]int64, len(d))
+		for i := range d {
+			negated[len(d)-1-i] = 1 - d[i]
+		}
+		return &domain{intervals: negated}
+	}
+	return &domain{intervals: r.pb.GetTightenedVariables()[iv.index()].GetDomain()}
+}
+
+// TightenedDomains returns the variables whose domains the solver tightened
+// (narrower than what they were declared with), along with their tightened
+// domains, for models solved using WithTightenedDomains. They're ordered by
+// index.
+func (r Result) TightenedDomains() (vars []IntVar, domains []Domain) {
+	if r.vars == nil {
+		panic("result not from a model solved with tightened domains")
+	}
+
+	for i, v := range r.pb.GetTightenedVariables() {
+		d := &domain{intervals: v.GetDomain()}
+		if d.String() == r.vars[i].domain().String() {
+			continue
+		}
+		vars = append(vars, r.vars[i])
+		domains = append(domains, d)
+	}
+	return vars, domains
+}
+
+// FixedVariables returns the variables the solver proved to be fixed, along
+// with their values, for models solved using WithTightenedDomains. Variables
+// that were already fixed in the model (constants, for example) aren't
+// included.
+//
+// For feasibility problems, these values hold for all feasible solutions. For
+// optimization problems, they're only guaranteed to hold for optimal ones.
+//
+// TODO(irfansharif): Also surface variables proven equivalent to one another,
+// once the bundled version of OR-Tools reports them.
+func (r Result) FixedVariables() (vars []IntVar, values []int64) {
+	if r.vars == nil {
+		panic("result not from a model solved with tightened domains")
+	}
+
+	for i, v := range r.pb.GetTightenedVariables() {
+		d := v.GetDomain()
+		if len(d) != 2 || d[0] != d[1] {
+			continue
+		}
+		if iv := r.vars[i]; !iv.domain().fixed(d[0]) {
+			vars = append(vars, iv)
+			values = append(values, d[0])
+		}
+	}
+	return vars, values
 }
 
 // ObjectiveValue is the result of evaluating a model's objective function if
@@ -76,6 +305,68 @@ func (r Result) ObjectiveValue() float64 {
 	return r.pb.GetObjectiveValue()
 }
 
+// BestObjectiveBound is the best proven bound on the objective value at the
+// time the result was generated. For a minimization problem this is a
+// lower-bound, and for a maximization problem an upper-bound. If the result is
+// optimal, it's equal to the objective value.
+func (r Result) BestObjectiveBound() float64 {
+	return r.pb.GetBestObjectiveBound()
+}
+
+// SolutionCount is the number of solutions found at the time the result was
+// generated. When enumerating through solutions, the i-th result passed to the
+// callback has a count of i. Solutions are only counted when enumerating (see
+// WithEnumeration); the solver doesn't report how many it found otherwise, so
+// other results have a count of one if solved (for the solution returned), and
+// zero if not.
+func (r Result) SolutionCount() int64 {
+	return r.solutions
+}
+
+// NumConflicts is the number of conflicts encountered during search, at the
+// time the result was generated.
+func (r Result) NumConflicts() int64 {
+	return r.pb.GetNumConflicts()
+}
+
+// NumBranches is the number of search branches explored, at the time the
+// result was generated.
+func (r Result) NumBranches() int64 {
+	return r.pb.GetNumBranches()
+}
+
+// WallTime is the wall clock time spent searching, at the time the result was
+// generated.
+func (r Result) WallTime() time.Duration {
+	return seconds(r.pb.GetWallTime())
+}
+
+// UserTime is the user time spent searching, at the time the result was
+// generated.
+func (r Result) UserTime() time.Duration {
+	return seconds(r.pb.GetUserTime())
+}
+
+// DeterministicTime is the deterministic time spent searching, a
+// machine-independent measure of the work done by the solver (roughly
+// equivalent to seconds). It's only available for the final result of a
+// solve, not for the intermediate ones generated during enumeration.
+func (r Result) DeterministicTime() float64 {
+	return r.pb.GetDeterministicTime()
+}
+
+// Timings returns where the time was spent solving the model, between Go and
+// the native solver, so performance work can target the right layer. It's
+// only available for the final result of a solve.
+func (r Result) Timings() SolveTimings {
+	return r.timings
+}
+
 func (r Result) String() string {
 	return "unimplemented" // XXX:
 }
+
+// seconds converts the given number of (fractional) seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
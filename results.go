@@ -0,0 +1,94 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ObjectiveSense is the direction in which an objective is optimized.
+type ObjectiveSense int
+
+const (
+	// Minimization is the sense of objectives set using Model.Minimize.
+	Minimization ObjectiveSense = iota
+	// Maximization is the sense of objectives set using Model.Maximize (or
+	// Model.MaximizeCoverage).
+	Maximization
+)
+
+// Results is a collection of results, as gathered when enumerating solutions
+// (see WithEnumeration and Results.Collect) or solving a model repeatedly.
+type Results []Result
+
+// Collect appends the given result to the collection. It's typically used as
+// the handler when enumerating solutions:
+//
+//	var results solver.Results
+//	model.Solve(solver.WithEnumeration(results.Collect))
+func (rs *Results) Collect(r Result) {
+	*rs = append(*rs, r)
+}
+
+// Best returns the result with the best objective value, given the sense in
+// which the objective is optimized. Only results with solutions (optimal or
+// feasible ones) are considered; if there are none, it returns false. Ties
+// are broken in favor of earlier results.
+func (rs Results) Best(sense ObjectiveSense) (Result, bool) {
+	var best Result
+	var found bool
+	for _, r := range rs {
+		if !r.solved() {
+			continue
+		}
+		if !found ||
+			(sense == Minimization && r.ObjectiveValue() < best.ObjectiveValue()) ||
+			(sense == Maximization && r.ObjectiveValue() > best.ObjectiveValue()) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// SortByObjective sorts the results in place by increasing objective value.
+// Results without solutions are ordered last; the sort is otherwise stable.
+func (rs Results) SortByObjective() {
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].solved() != rs[j].solved() {
+			return rs[i].solved()
+		}
+		return rs[i].ObjectiveValue() < rs[j].ObjectiveValue()
+	})
+}
+
+// Deduplicate returns the results with distinct solutions, retaining the
+// first of each. Results without solutions are dropped.
+func (rs Results) Deduplicate() Results {
+	var res Results
+	seen := make(map[string]bool)
+	for _, r := range rs {
+		if !r.solved() {
+			continue
+		}
+		key := fmt.Sprint(r.pb.GetSolution())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, r)
+	}
+	return res
+}
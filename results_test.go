@@ -0,0 +1,151 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResults(t *testing.T) {
+	result := func(status pb.CpSolverStatus, objective float64, solution ...int64) Result {
+		return Result{pb: &pb.CpSolverResponse{
+			Status:         status,
+			ObjectiveValue: objective,
+			Solution:       solution,
+		}}
+	}
+	results := Results{
+		result(pb.CpSolverStatus_FEASIBLE, 5, 1, 2),
+		result(pb.CpSolverStatus_UNKNOWN, 0),
+		result(pb.CpSolverStatus_FEASIBLE, 3, 2, 1),
+		result(pb.CpSolverStatus_OPTIMAL, 7, 1, 2),
+		result(pb.CpSolverStatus_FEASIBLE, 3, 0, 0),
+	}
+
+	best, ok := results.Best(Minimization)
+	require.True(t, ok)
+	require.Equal(t, []int64{2, 1}, best.pb.GetSolution())
+	best, ok = results.Best(Maximization)
+	require.True(t, ok)
+	require.Equal(t, []int64{1, 2}, best.pb.GetSolution())
+	require.Equal(t, float64(7), best.ObjectiveValue())
+
+	_, ok = Results{result(pb.CpSolverStatus_INFEASIBLE, 0)}.Best(Minimization)
+	require.False(t, ok)
+
+	deduped := results.Deduplicate()
+	require.Len(t, deduped, 3)
+	require.Equal(t, float64(5), deduped[0].ObjectiveValue())
+
+	results.SortByObjective()
+	var objectives []float64
+	for _, r := range results {
+		objectives = append(objectives, r.ObjectiveValue())
+	}
+	require.Equal(t, []float64{3, 3, 5, 7, 0}, objectives)
+	require.Equal(t, []int64{2, 1}, results[0].pb.GetSolution())
+	require.Equal(t, Unknown, results[4].Status())
+}
+
+func TestCollectResults(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 2, "x")
+	model.NewIntVar(0, 1, "y")
+	model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(1, 2)))
+
+	var results Results
+	_ = model.Solve(WithEnumeration(results.Collect))
+	require.Len(t, results, 4)
+	require.Len(t, results.Deduplicate(), 4)
+}
+
+func TestResultClone(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewLiteral("x"), model.NewLiteral("y")
+	literals, weights := []Literal{x, y}, []int64{2, 3}
+	model.MaximizeCoverage(literals, weights)
+
+	// Results retain copies of what they were given.
+	literals[0], weights[0] = y, 42
+	result := Result{
+		pb:       &pb.CpSolverResponse{Status: pb.CpSolverStatus_OPTIMAL, Solution: []int64{1, 0}},
+		coverage: model.coverage,
+	}
+	covered, total, uncovered := result.Coverage()
+	require.Equal(t, int64(2), covered)
+	require.Equal(t, int64(5), total)
+	require.Equal(t, []Literal{y}, uncovered)
+
+	clone := result.Clone()
+	clone.pb.Solution[0] = 0
+	clone.coverage.weights[0] = 7
+	require.False(t, clone.BooleanValue(x))
+	require.True(t, result.BooleanValue(x))
+	covered, _, _ = result.Coverage()
+	require.Equal(t, int64(2), covered)
+	require.Equal(t, Result{}, Result{}.Clone())
+}
+
+func TestResultLifetime(t *testing.T) {
+	const n = 8
+	var vars [n][]IntVar
+	var results [n]Result
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model := NewModel("")
+			for j := 0; j < 10; j++ {
+				vars[i] = append(vars[i], model.NewIntVar(0, 100, fmt.Sprintf("x%d", j)))
+			}
+			for j := 0; j < 10; j++ {
+				model.AddConstraints(NewLinearConstraint(Sum(vars[i][j]), NewDomain(int64(i*j), int64(i*j))))
+			}
+			results[i] = model.Solve(WithEnumeration(func(Result) {}))
+		}()
+	}
+	wg.Wait()
+
+	// The underlying solvers are long gone; the results, read concurrently,
+	// are unaffected.
+	for round := 0; round < 3; round++ {
+		runtime.GC()
+		for i := 0; i < n; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if !results[i].Optimal() {
+					t.Errorf("result #%d: expected optimal, found %s", i, results[i].Status())
+					return
+				}
+				for j, v := range vars[i] {
+					if got := results[i].Value(v); got != int64(i*j) {
+						t.Errorf("result #%d: expected x%d == %d, found %d", i, j, i*j, got)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
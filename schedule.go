@@ -0,0 +1,90 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// Scheduled partitions the given intervals into the ones that are present in
+// the solution and the ones that were dropped, i.e. optional intervals whose
+// presence literal is false. Intervals without a presence literal are always
+// scheduled. This is only valid to use if the result is optimal or feasible.
+func (r Result) Scheduled(intervals ...Interval) (scheduled, dropped []Interval) {
+	for _, itv := range intervals {
+		if r.isPresent(itv) {
+			scheduled = append(scheduled, itv)
+		} else {
+			dropped = append(dropped, itv)
+		}
+	}
+	return scheduled, dropped
+}
+
+// ScheduledDuration returns the total size of the given intervals that are
+// present in the solution. This is only valid to use if the result is optimal
+// or feasible.
+func (r Result) ScheduledDuration(intervals ...Interval) int64 {
+	var total int64
+	for _, itv := range intervals {
+		if !r.isPresent(itv) {
+			continue
+		}
+		_, _, size := itv.Parameters()
+		total += r.Value(size)
+	}
+	return total
+}
+
+// Utilization returns the fraction of the resource's capacity used by its
+// scheduled intervals, over the span from the earliest start to the latest end
+// amongst them. Each interval uses its demand (or the entirety of a
+// disjunctive resource) for its duration. It's zero if none of the registered
+// intervals are scheduled. This is only valid to use if the result is optimal
+// or feasible.
+func (r Result) Utilization(res *Resource) float64 {
+	var used, min, max int64
+	var found bool
+	for i, itv := range res.intervals {
+		if !r.isPresent(itv) {
+			continue
+		}
+		start, end, size := itv.Parameters()
+		demand := int64(1)
+		if res.capacity != nil {
+			demand = r.Value(res.demands[i])
+		}
+		used += r.Value(size) * demand
+
+		if s := r.Value(start); !found || s < min {
+			min = s
+		}
+		if e := r.Value(end); !found || e > max {
+			max = e
+		}
+		found = true
+	}
+
+	capacity := int64(1)
+	if res.capacity != nil {
+		capacity = r.Value(res.capacity)
+	}
+	if !found || max <= min || capacity <= 0 {
+		return 0
+	}
+	return float64(used) / float64((max-min)*capacity)
+}
+
+// isPresent returns whether the given interval is present in the solution.
+func (r Result) isPresent(itv Interval) bool {
+	presence := itv.(*interval).enforcement
+	return presence == nil || r.BooleanValue(presence)
+}
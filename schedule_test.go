@@ -0,0 +1,70 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/irfansharif/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleKPIs(t *testing.T) {
+	model := NewModel("")
+	crew := model.AddCumulativeResource("crew", model.NewConstant(2, "capacity"))
+	machine := model.AddDisjunctiveResource("machine")
+
+	var intervals []Interval
+	for _, name := range []string{"a", "b", "c"} {
+		start := model.NewIntVar(0, 10, name+".start")
+		end := model.NewIntVar(0, 10, name+".end")
+		size := model.NewIntVar(0, 10, name+".size")
+		present := model.NewLiteral(name + ".present")
+		intervals = append(intervals, model.NewInterval(start, end, size, name).OnlyEnforceIf(present).(Interval))
+	}
+	mandatory := model.NewInterval(model.NewConstant(8, ""), model.NewConstant(10, ""), model.NewConstant(2, ""), "d")
+	intervals = append(intervals, mandatory)
+	for _, itv := range intervals {
+		crew.Register(itv, model.NewConstant(1, ""))
+	}
+	machine.Register(intervals[0], nil)
+	machine.Register(intervals[1], nil)
+
+	// Variables: capacity, then (start, end, size, present) for each optional
+	// interval, then the mandatory interval's constants, then the demands.
+	solution := []int64{
+		2,
+		0, 4, 4, 1, // a: [0, 4)
+		2, 5, 3, 0, // b: dropped
+		4, 8, 4, 1, // c: [4, 8)
+		8, 10, 2,
+		1, 1, 1, 1,
+	}
+	result := Result{pb: &pb.CpSolverResponse{
+		Status:   pb.CpSolverStatus_OPTIMAL,
+		Solution: solution,
+	}}
+
+	scheduled, dropped := result.Scheduled(intervals...)
+	require.Equal(t, []Interval{intervals[0], intervals[2], mandatory}, scheduled)
+	require.Equal(t, []Interval{intervals[1]}, dropped)
+	require.Equal(t, int64(4+4+2), result.ScheduledDuration(intervals...))
+
+	// The crew is busy 10 units out of a possible 2*10; the machine is busy for
+	// all of a's 4 units.
+	require.Equal(t, 0.5, result.Utilization(crew))
+	require.Equal(t, 1.0, result.Utilization(machine))
+	require.Equal(t, 0.0, result.Utilization(model.AddDisjunctiveResource("idle")))
+}
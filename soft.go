@@ -0,0 +1,68 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+// softConstraints captures the violation literals (and their penalties) of the
+// soft constraints added to a model; see Model.AddSoftConstraint.
+type softConstraints struct {
+	violations []Literal
+	penalties  []int64
+}
+
+// AddSoftConstraint adds a constraint to the model that's allowed to be
+// violated, at the given (non-negative) penalty. It returns the literal that's
+// true iff the constraint is treated as violated; the constraint is enforced
+// otherwise. The penalties of violated constraints are added to the model's
+// objective when it's finalized (see Model.Finalize): minimization objectives
+// are penalized, maximization ones are discounted. Models without objectives
+// minimize the total penalty.
+//
+// The constraint needs to support enforcement (see Constraint.OnlyEnforceIf),
+// and must not already be enforced by other literals. Model.Objective returns
+// the objective as set, without the penalties.
+func (m *Model) AddSoftConstraint(c Constraint, penalty int64) Literal {
+	if penalty < 0 {
+		m.fail("soft constraint %s: negative penalty %d", c.String(), penalty)
+		return nil
+	}
+
+	violated := m.NewLiteral("")
+	m.AddConstraints(c.OnlyEnforceIf(violated.Not()))
+	if m.soft == nil {
+		m.soft = &softConstraints{}
+	}
+	m.soft.violations = append(m.soft.violations, violated)
+	m.soft.penalties = append(m.soft.penalties, penalty)
+	return violated
+}
+
+// penalize sets the objective proto to the model's objective, if any,
+// including the penalties of its soft constraints. It's a no-op for models
+// without soft constraints.
+func (m *Model) penalize() {
+	if m.soft == nil {
+		return
+	}
+
+	penalty := Dot(m.soft.violations, m.soft.penalties)
+	switch {
+	case m.objective == nil:
+		m.pb.Objective = m.toObjectiveProto(penalty)
+	case m.minimize:
+		m.pb.Objective = m.toObjectiveProto(m.objective.Add(penalty))
+	default:
+		m.pb.Objective = m.toMaximizationProto(m.objective.Sub(penalty))
+	}
+}
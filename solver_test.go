@@ -15,6 +15,7 @@
 package solver
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -24,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/irfansharif/solver/pb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -256,6 +258,143 @@ func TestLinearExprMaximization(t *testing.T) {
 	}
 }
 
+func TestObjective(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+
+	_, _, ok := model.Objective()
+	require.False(t, ok)
+
+	model.Maximize(Sum(x))
+	e, maximize, ok := model.Objective()
+	require.True(t, ok)
+	require.True(t, maximize)
+	require.Equal(t, "x", e.String())
+
+	model.Minimize(Sum(x))
+	_, maximize, ok = model.Objective()
+	require.True(t, ok)
+	require.False(t, maximize)
+
+	model.ClearObjective()
+	_, _, ok = model.Objective()
+	require.False(t, ok)
+	require.NotContains(t, model.String(), "objective")
+	require.Nil(t, model.pb.GetObjective())
+}
+
+func TestMaximizeCoverage(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	b := model.NewLiteral("b")
+	c := model.NewLiteral("c")
+	model.AddConstraints(NewAtMostKConstraint(2, a, b, c))
+	model.MaximizeCoverage([]Literal{a, b, c}, []int64{5, 1, 3})
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(8), result.ObjectiveValue())
+
+	covered, total, uncovered := result.Coverage()
+	require.Equal(t, int64(8), covered)
+	require.Equal(t, int64(9), total)
+	require.Equal(t, []Literal{b}, uncovered)
+
+	model.ClearObjective()
+	require.Panics(t, func() { model.Solve().Coverage() })
+}
+
+func TestCoverageUnsolved(t *testing.T) {
+	model := NewModel("")
+	a, b := model.NewLiteral("a"), model.NewLiteral("b")
+	model.MaximizeCoverage([]Literal{a, b}, []int64{5, 1})
+
+	result := Result{pb: &pb.CpSolverResponse{Status: pb.CpSolverStatus_INFEASIBLE}, coverage: model.coverage}
+	covered, total, uncovered := result.Coverage()
+	require.Equal(t, int64(0), covered)
+	require.Equal(t, int64(6), total)
+	require.Empty(t, uncovered)
+}
+
+func TestLiteralObjective(t *testing.T) {
+	model := NewModel("")
+	a, b, c := model.NewLiteral("a"), model.NewLiteral("b"), model.NewLiteral("c")
+	model.AddConstraints(NewAtMostKConstraint(1, a, b, c))
+
+	model.MaximizeLiterals(map[Literal]int64{c: 2, a.Not(): 3, b: 4, a: 1})
+	e, maximize, _ := model.Objective()
+	require.True(t, maximize)
+	require.Equal(t, "-2a + 4b + 2c + 3", e.String())
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(7), result.ObjectiveValue())
+	require.True(t, result.BooleanValue(b))
+
+	model.MinimizeLiterals(map[Literal]int64{c.Not(): 5, b: 1})
+	result, err = SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(0), result.ObjectiveValue())
+	require.True(t, result.BooleanValue(c))
+}
+
+func TestAlternative(t *testing.T) {
+	model := NewModel("")
+	size := model.NewConstant(4, "size")
+	var modes []Interval
+	for _, name := range []string{"a", "b", "c"} {
+		start := model.NewIntVar(0, 10, fmt.Sprintf("%s.start", name))
+		end := model.NewIntVar(0, 10, fmt.Sprintf("%s.end", name))
+		modes = append(modes, model.NewInterval(start, end, size, name))
+	}
+	b := model.NewLiteral("b")
+	modes[1].OnlyEnforceIf(b)
+
+	presences := model.AddAlternative(modes)
+	require.Len(t, presences, 3)
+	require.Equal(t, b, presences[1])
+	require.Equal(t, "a.present", presences[0].String())
+
+	model.AddConstraints(NewBooleanAndConstraint(presences[2]))
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, []bool{false, false, true}, []bool{
+		result.BooleanValue(presences[0]),
+		result.BooleanValue(presences[1]),
+		result.BooleanValue(presences[2]),
+	})
+}
+
+func TestFixedIntervals(t *testing.T) {
+	model := NewModel("")
+	start := model.NewIntVarFromDomain(NewDomain(0, 2, 5, 6), "start")
+	task := model.NewFixedSizeInterval(start, 3, "task")
+	_, end, size := task.Parameters()
+	require.Equal(t, "task.end in [3, 5] ∪ [8, 9]", end.String())
+	require.Equal(t, "task.size == 3", size.String())
+
+	// The task can't overlap with the fixed window, [1, 6).
+	window := model.NewFixedInterval(1, 6, "window")
+	wstart, wend, wsize := window.Parameters()
+	require.Equal(t, []string{"window.start == 1", "window.end == 6", "window.size == 5"},
+		[]string{wstart.String(), wend.String(), wsize.String()})
+	model.AddConstraints(NewNonOverlappingConstraint(task, window))
+	model.Minimize(Sum(start))
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(6), result.Value(start))
+
+	model = NewModel("", WithConstructionErrors())
+	model.NewFixedInterval(2, 1, "backwards")
+	model.NewFixedSizeInterval(model.NewIntVar(0, 10, "s"), -2, "negative")
+	require.EqualError(t, model.Err(), "2 construction errors: "+
+		"interval backwards: end (1) precedes start (2); "+
+		"interval negative: negative size (-2)")
+}
+
 func TestElement(t *testing.T) {
 	model := NewModel("")
 	var array []IntVar
@@ -274,6 +413,47 @@ func TestElement(t *testing.T) {
 	require.True(t, result.Value(target) == 10*result.Value(index))
 }
 
+func TestLinearMaximumWithConstants(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	target := model.NewIntVar(0, 20, "target")
+
+	// target == max(x + 2, 7); no auxiliary variables needed for the offset or
+	// the constant.
+	model.AddConstraints(
+		NewLinearMaximumConstraint(Sum(target),
+			NewLinearExpr([]IntVar{x}, []int64{1}, 2),
+			NewLinearExpr(nil, nil, 7),
+		),
+		NewMinimumConstraint(y, x, target),
+	)
+	model.Minimize(Sum(target))
+
+	result := model.Solve()
+	require.True(t, result.Optimal(), "expected solver to find solution")
+	require.Equal(t, int64(7), result.Value(target))
+	require.True(t, result.Value(x) <= 5)
+	require.Equal(t, result.Value(x), result.Value(y))
+}
+
+func TestAbs(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(-10, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+
+	model.AddConstraints(
+		NewAbsConstraint(Sum(y), NewLinearExpr([]IntVar{x}, []int64{1}, -3)),
+		NewLinearConstraint(Sum(x), NewDomain(-10, -2)),
+	)
+	model.Minimize(Sum(y))
+
+	result := model.Solve()
+	require.True(t, result.Optimal(), "expected solver to find solution")
+	require.Equal(t, int64(-2), result.Value(x))
+	require.Equal(t, int64(5), result.Value(y))
+}
+
 func TestEnumerateSolutions(t *testing.T) {
 	model := NewModel("")
 
@@ -287,6 +467,209 @@ func TestEnumerateSolutions(t *testing.T) {
 	require.Len(t, results, int(numVals))
 }
 
+func TestEnumerateSolutionsStatistics(t *testing.T) {
+	model := NewModel("")
+
+	var numVals int64 = 3
+	_ = model.NewIntVar(1, numVals, "x")
+
+	var results []Result
+	result := model.Solve(
+		WithEnumeration(func(r Result) { results = append(results, r) }),
+	)
+	require.Len(t, results, int(numVals))
+	for i, r := range results {
+		require.Equal(t, int64(i+1), r.SolutionCount())
+		require.True(t, r.WallTime() >= 0)
+	}
+	require.Equal(t, numVals, result.SolutionCount())
+}
+
+func TestEnumerationValidation(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(1, 3, "x")
+
+	result := model.Solve(
+		WithEnumeration(func(r Result) {}),
+		WithParallelism(4),
+	)
+	require.True(t, result.Invalid())
+	require.True(t, errors.Is(result.Err(), ErrEnumerationWithParallelism))
+
+	result = model.Solve(
+		WithEnumeration(func(r Result) {}),
+		WithCanonicalSolution(),
+	)
+	require.True(t, result.Invalid())
+	require.True(t, errors.Is(result.Err(), ErrEnumerationWithCanonicalSolution))
+
+	model.Minimize(Sum(x))
+	result = model.Solve(WithEnumeration(func(r Result) {}))
+	require.True(t, result.Invalid())
+	require.True(t, errors.Is(result.Err(), ErrEnumerationWithObjective))
+}
+
+func TestHintOnly(t *testing.T) {
+	solve := func(hx, hy int64) (Result, IntVar, IntVar) {
+		model := NewModel("")
+		x := model.NewIntVar(0, 10, "x")
+		y := model.NewIntVar(0, 10, "y")
+		model.AddConstraints(NewAllDifferentConstraint(x, y))
+		model.AddHint(x, hx)
+		model.AddHint(y, hy)
+		return model.Solve(WithHintOnly()), x, y
+	}
+
+	result, x, y := solve(3, 4)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(3), result.Value(x))
+	require.Equal(t, int64(4), result.Value(y))
+
+	result, _, _ = solve(3, 3)
+	require.True(t, result.Infeasible())
+}
+
+func TestWithHintsFixed(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	x := model.NewIntVar(0, 10, "x")
+	model.AddHint(a.Not(), 1)
+	model.AddHint(x, 42)
+
+	fixed := withHintsFixed(model.pb)
+	require.Len(t, fixed.Constraints, 2)
+	require.Len(t, model.pb.Constraints, 0)
+	require.Equal(t, []int32{a.index()}, fixed.Constraints[0].GetLinear().Vars)
+	require.Equal(t, []int64{0, 0}, fixed.Constraints[0].GetLinear().Domain)
+	require.Equal(t, []int64{42, 42}, fixed.Constraints[1].GetLinear().Domain)
+}
+
+func TestObjectiveCutoff(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewLinearConstraint(Sum(x, y), NewDomain(0, 12)))
+
+	result := model.Solve(WithObjectiveCutoff(10, Maximization))
+	require.True(t, result.Invalid())
+	require.True(t, errors.Is(result.Err(), ErrCutoffWithoutObjective))
+
+	model.Maximize(NewLinearExpr([]IntVar{x, y}, []int64{1, 1}, 2))
+	result = model.Solve(WithObjectiveCutoff(10, Minimization))
+	require.True(t, result.Invalid())
+	require.True(t, errors.Is(result.Err(), ErrCutoffSenseMismatch))
+
+	result = model.Solve(WithObjectiveCutoff(10, Maximization))
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(14), result.ObjectiveValue())
+
+	result = model.Solve(WithObjectiveCutoff(14, Maximization))
+	require.True(t, result.Infeasible())
+}
+
+func TestWithObjectiveCutoff(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+
+	model.Maximize(NewLinearExpr([]IntVar{x, y}, []int64{1, 1}, 2))
+	cutoff := withObjectiveCutoff(model.pb, 10)
+	require.Equal(t, []int64{-20, -9}, cutoff.GetObjective().GetDomain())
+	require.Empty(t, model.pb.GetObjective().GetDomain())
+
+	model.Minimize(NewLinearExpr([]IntVar{x, a.Not()}, []int64{2, 3}, 1))
+	cutoff = withObjectiveCutoff(model.pb, 10)
+	require.Equal(t, []int64{0, 8}, cutoff.GetObjective().GetDomain())
+
+	cutoff = withObjectiveCutoff(model.pb, 0)
+	require.Equal(t, []int64{-2, -2}, cutoff.GetObjective().GetDomain())
+}
+
+func TestCanonicalSolution(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	z := model.NewIntVar(0, 10, "z")
+	model.AddConstraints(NewLinearConstraint(Sum(x, y, z), NewDomain(0, 12)))
+	model.Maximize(Sum(x, y, z))
+
+	result := model.Solve(WithCanonicalSolution())
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(12), result.ObjectiveValue())
+	require.Equal(t, int64(0), result.Value(x))
+	require.Equal(t, int64(2), result.Value(y))
+	require.Equal(t, int64(10), result.Value(z))
+}
+
+func TestCanonicalSolutionBudget(t *testing.T) {
+	// Limits apply to the re-solves as a whole, and not each one.
+	var o options
+	WithConflictLimit(100)(&o, nil)
+	WithDeterministicTimeLimit(2.5)(&o, nil)
+	WithTimeout(time.Hour)(&o, nil)
+	b := &budget{params: &o.params, start: time.Now()}
+
+	b.spend(&pb.CpSolverResponse{NumConflicts: 60, DeterministicTime: 1})
+	params, ok := b.remaining()
+	require.True(t, ok)
+	require.Equal(t, int64(40), params.GetMaxNumberOfConflicts())
+	require.Equal(t, 1.5, params.GetMaxDeterministicTime())
+	require.Less(t, params.GetMaxTimeInSeconds(), time.Hour.Seconds())
+	require.Equal(t, int64(100), o.params.GetMaxNumberOfConflicts()) // unchanged
+
+	b.spend(&pb.CpSolverResponse{NumConflicts: 40})
+	_, ok = b.remaining()
+	require.False(t, ok)
+
+	// Unlimited solves remain so.
+	b = &budget{params: &pb.SatParameters{}}
+	b.spend(&pb.CpSolverResponse{NumConflicts: 1e6, DeterministicTime: 1e6})
+	params, ok = b.remaining()
+	require.True(t, ok)
+	require.Nil(t, params.MaxTimeInSeconds)
+	require.Nil(t, params.MaxNumberOfConflicts)
+}
+
+func TestEffortLimits(t *testing.T) {
+	var o options
+	WithConflictLimit(100)(&o, nil)
+	WithDeterministicTimeLimit(2.5)(&o, nil)
+	require.Equal(t, int64(100), o.params.GetMaxNumberOfConflicts())
+	require.Equal(t, 2.5, o.params.GetMaxDeterministicTime())
+}
+
+func TestProfiles(t *testing.T) {
+	var fast options
+	ProfileFast()(&fast, nil)
+	require.Equal(t, float64(1), fast.params.GetMaxTimeInSeconds())
+	require.Equal(t, 0.05, fast.params.GetRelativeGapLimit())
+	require.Equal(t, int32(1), fast.params.GetNumSearchWorkers())
+
+	var balanced options
+	ProfileBalanced()(&balanced, nil)
+	require.Equal(t, float64(10), balanced.params.GetMaxTimeInSeconds())
+	require.Equal(t, int32(8), balanced.params.GetNumSearchWorkers())
+
+	// Options listed after a profile override it.
+	var exhaustive options
+	for _, o := range []Option{ProfileExhaustive(time.Minute), WithParallelism(4)} {
+		o(&exhaustive, nil)
+	}
+	require.Equal(t, float64(60), exhaustive.params.GetMaxTimeInSeconds())
+	require.Equal(t, int32(2), exhaustive.params.GetLinearizationLevel())
+	require.Equal(t, int32(4), exhaustive.params.GetNumSearchWorkers())
+
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.Maximize(Sum(x))
+	for _, profile := range []Option{ProfileFast(), ProfileBalanced(), ProfileExhaustive(time.Minute)} {
+		result := model.Solve(profile)
+		require.True(t, result.Optimal())
+		require.Equal(t, int64(10), result.Value(x))
+	}
+}
+
 func TestNegation(t *testing.T) {
 	model := NewModel("")
 
@@ -306,6 +689,23 @@ func TestNegation(t *testing.T) {
 	}
 }
 
+func TestNegationCached(t *testing.T) {
+	model := NewModel("")
+	A := model.NewLiteral("A")
+
+	notA := A.Not()
+	require.True(t, notA == A.Not(), "expected negation to be cached")
+	require.True(t, A == notA.Not(), "expected double negation to return the original")
+	require.Equal(t, "~A", notA.String())
+	require.Equal(t, "~A", notA.Not().Not().String())
+
+	// Solution lookups reflect negation.
+	result := Result{pb: &pb.CpSolverResponse{Solution: []int64{1}}}
+	require.Equal(t, int64(0), result.Value(notA))
+	require.False(t, result.BooleanValue(notA))
+	require.True(t, result.BooleanValue(notA.Not()))
+}
+
 func TestNegationInfeasible(t *testing.T) {
 	model := NewModel("")
 
@@ -330,6 +730,38 @@ func TestModelValidation(t *testing.T) {
 		"domain do not fall in [kint64min + 2, kint64max - 1]"))
 }
 
+func TestResultHelpers(t *testing.T) {
+	model := NewModel("")
+
+	x := model.NewIntVar(0, 10, "x")
+	A := model.NewLiteral("A")
+	B := model.NewLiteral("B")
+
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x), NewDomain(3, 4)),
+		NewBooleanAndConstraint(A, B.Not()),
+	)
+
+	result := model.Solve()
+	require.True(t, result.Optimal(), "expected solver to find solution")
+	require.True(t, result.IsValueInDomain(x, NewDomain(3, 4)))
+	require.False(t, result.IsValueInDomain(x, NewDomain(5, 10)))
+	require.Equal(t, map[string]bool{"A": true, "B": false, "~B": true},
+		result.AsBoolMap([]Literal{A, B, B.Not()}))
+}
+
+func TestModelSizeEstimates(t *testing.T) {
+	model := NewModel("")
+	emptySize, emptyMemory := model.ProtoSize(), model.EstimatedMemory()
+
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(NewAllDifferentConstraint(x, y))
+
+	require.Greater(t, model.ProtoSize(), emptySize)
+	require.Greater(t, model.EstimatedMemory(), emptyMemory)
+}
+
 func TestAllSame(t *testing.T) {
 	model := NewModel("")
 
@@ -457,3 +889,735 @@ func TestSolverOptions(t *testing.T) {
 		require.True(t, A == B && B == C)
 	}
 }
+
+func TestFinalize(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	var emitted int
+	model.onFinalize(func(m *Model) {
+		emitted++
+		m.onFinalize(func(*Model) { emitted++ }) // deferring from within
+	})
+
+	// Inspecting the model emits deferred constructs into a copy, leaving the
+	// model itself as is.
+	model.ProtoSize()
+	require.Equal(t, 2, emitted)
+	require.Len(t, model.deferred, 1)
+	require.False(t, model.Finalized())
+	model.AddConstraints(NewAllDifferentConstraint(x))
+
+	model.Finalize()
+	require.Equal(t, 4, emitted)
+	require.Len(t, model.deferred, 0)
+	require.True(t, model.Finalized())
+	require.Panics(t, func() { model.NewIntVar(0, 10, "y") })
+	require.Panics(t, func() { model.AddConstraints(NewAllDifferentConstraint(x)) })
+	require.Panics(t, func() { model.Minimize(Sum(x)) })
+	require.Panics(t, func() { model.AddDisjunctiveResource("r") })
+	model.AddHint(x, 4)
+}
+
+func TestModelVariables(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	x := model.NewIntVar(0, 10, "x")
+	c := model.NewConstant(4, "c")
+	require.Equal(t, []IntVar{a, x, c}, model.variables())
+
+	b := NewModelBuilder("")
+	b.NewIntVar(0, 10, "y")
+	vars := b.Model().variables()
+	require.Len(t, vars, 1)
+	require.Equal(t, "y in [0, 10]", vars[0].String())
+}
+
+func TestFixedVariables(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	z := model.NewIntVar(0, 10, "z")
+	model.NewConstant(4, "c")
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x, y), NewDomain(20, 20)),
+		NewLinearConstraint(Sum(z), NewDomain(0, 5)),
+	)
+
+	result := model.Solve(WithTightenedDomains())
+	require.True(t, result.Optimal())
+	vars, values := result.FixedVariables()
+	require.Equal(t, []IntVar{x, y}, vars)
+	require.Equal(t, []int64{10, 10}, values)
+
+	require.Panics(t, func() { model.Solve().FixedVariables() })
+}
+
+func TestTightenedDomains(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	l := model.NewLiteral("l")
+	m := model.NewLiteral("m")
+
+	// Tightened domains, as reported by the solver.
+	result := Result{
+		pb: &pb.CpSolverResponse{TightenedVariables: []*pb.IntegerVariableProto{
+			{Domain: []int64{0, 5}},
+			{Domain: []int64{0, 0}},
+			{Domain: []int64{0, 1}},
+		}},
+		vars: model.variables(),
+	}
+	require.Equal(t, "[0, 5]", result.TightenedDomain(x).String())
+	require.Equal(t, "[1, 1]", result.TightenedDomain(l.Not()).String())
+	require.Equal(t, "[0, 1]", result.TightenedDomain(m.Not()).String())
+
+	vars, domains := result.TightenedDomains()
+	require.Equal(t, []IntVar{x, l}, vars)
+	require.Equal(t, "[0, 5]", domains[0].String())
+	require.Equal(t, "[0, 0]", domains[1].String())
+
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x), NewDomain(0, 5)),
+		NewBooleanAndConstraint(l.Not()),
+	)
+	result = model.Solve(WithTightenedDomains())
+	require.True(t, result.Optimal())
+	vars, _ = result.TightenedDomains()
+	require.Equal(t, []IntVar{x, l}, vars)
+	require.Equal(t, "[1, 1]", result.TightenedDomain(l.Not()).String())
+	require.Panics(t, func() { model.Solve().TightenedDomain(x) })
+}
+
+func TestWithoutIntrospection(t *testing.T) {
+	model := NewModel("m", WithoutIntrospection())
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	l := model.NewLiteral("l")
+	model.NewConstant(42, "c")
+	start := model.NewIntVar(0, 5, "start")
+	model.NewFixedSizeInterval(start, 2, "i")
+	model.AddConstraints(
+		NewLinearConstraint(Sum(x, y), NewDomain(20, 20)),
+		NewBooleanAndConstraint(l),
+	)
+	model.Minimize(Sum(start))
+
+	require.Empty(t, model.vars)
+	require.Empty(t, model.constants)
+	require.Empty(t, model.literals)
+	require.Empty(t, model.intervals)
+	require.Empty(t, model.constraints)
+	require.Equal(t, `model=m
+  variables (num = 7)
+  constraints (num = 3)
+  (built without introspection)
+`, model.String())
+
+	result := model.Solve(WithTightenedDomains())
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(10), result.Value(x))
+	require.True(t, result.BooleanValue(l))
+
+	vars, _ := result.FixedVariables()
+	var names []string
+	for _, v := range vars {
+		names = append(names, v.name())
+	}
+	require.Subset(t, names, []string{"x", "y", "l"})
+}
+
+func TestQuotedNames(t *testing.T) {
+	for _, tc := range []struct {
+		name, quoted string
+	}{
+		{"x", "x"},
+		{"i.start", "i.start"},
+		{"Müller", "Müller"},
+		{"Nurse Ana — Mon", `"Nurse Ana — Mon"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"a,b", `"a,b"`},
+		{"tab\there", `"tab\there"`},
+	} {
+		require.Equal(t, tc.quoted, quoteName(tc.name))
+	}
+
+	model := NewModel("")
+	a := model.NewLiteral("Nurse Ana")
+	require.Equal(t, `~"Nurse Ana"`, a.Not().String())
+
+	result := Result{pb: &pb.CpSolverResponse{Solution: []int64{1}}}
+	require.Equal(t, map[string]bool{"Nurse Ana": true, "~Nurse Ana": false},
+		result.AsBoolMap([]Literal{a, a.Not()}))
+
+	// Distinct literals sharing a name would overwrite one another.
+	b, u, v := model.NewLiteral("Nurse Ana"), model.NewLiteral(""), model.NewLiteral("")
+	result = Result{pb: &pb.CpSolverResponse{Solution: []int64{1, 0, 0, 1}}}
+	require.Equal(t, map[string]bool{"<unnamed>": false, "~Nurse Ana": true},
+		result.AsBoolMap([]Literal{u, b.Not()}))
+	require.Len(t, result.AsBoolMap([]Literal{a, a}), 1)
+	require.Panics(t, func() { result.AsBoolMap([]Literal{a, b}) })
+	require.Panics(t, func() { result.AsBoolMap([]Literal{u, v}) })
+}
+
+func TestRoutes(t *testing.T) {
+	model := NewModel("")
+	// Visit nodes 1 through 3 from the depot (0), minimizing total distance.
+	distance := [][]int64{
+		{0, 4, 5, 3},
+		{4, 0, 2, 6},
+		{5, 2, 0, 4},
+		{3, 6, 4, 0},
+	}
+	var tails, heads []int
+	var literals []Literal
+	var costs []int64
+	for i := range distance {
+		for j := range distance {
+			if i == j {
+				continue
+			}
+			tails, heads = append(tails, i), append(heads, j)
+			literals = append(literals, model.NewLiteral(fmt.Sprintf("%d-%d", i, j)))
+			costs = append(costs, distance[i][j])
+		}
+	}
+	routes := NewRoutesConstraint(tails, heads, literals)
+	require.True(t, strings.HasPrefix(routes.String(), "routes: 0 → 1: 0-1, 0 → 2: 0-2, "))
+	model.AddConstraints(routes)
+	model.Minimize(Dot(literals, costs))
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(4+2+4+3), result.ObjectiveValue())
+	require.Panics(t, func() { NewRoutesConstraint(tails, heads[1:], literals) })
+}
+
+func TestAutomaton(t *testing.T) {
+	model := NewModel("")
+	// Schedule shifts over a week, working (1) no more than two days in a row.
+	// States track the number of consecutive days worked.
+	var days []IntVar
+	for i := 0; i < 7; i++ {
+		days = append(days, model.NewIntVar(0, 1, fmt.Sprintf("d%d", i)))
+	}
+	transitions := []Transition{
+		{Tail: 0, Head: 0, Label: 0},
+		{Tail: 0, Head: 1, Label: 1},
+		{Tail: 1, Head: 0, Label: 0},
+		{Tail: 1, Head: 2, Label: 1},
+		{Tail: 2, Head: 0, Label: 0},
+	}
+	automaton := NewAutomatonConstraint(days, 0, []int64{0, 1, 2}, transitions)
+	require.Equal(t, "automaton: d0, d1, d2, d3, d4, d5, d6 | start: 0, final: 0, 1, 2 | "+
+		"0 → 0: 0, 0 → 1: 1, 1 → 0: 0, 1 → 2: 1, 2 → 0: 0", automaton.String())
+	model.AddConstraints(automaton)
+	model.Maximize(Sum(days...))
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, float64(5), result.ObjectiveValue())
+}
+
+func TestResultStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status pb.CpSolverStatus
+		exp    Status
+		str    string
+	}{
+		{pb.CpSolverStatus_UNKNOWN, Unknown, "Unknown"},
+		{pb.CpSolverStatus_OPTIMAL, Optimal, "Optimal"},
+		{pb.CpSolverStatus_FEASIBLE, Feasible, "Feasible"},
+		{pb.CpSolverStatus_INFEASIBLE, Infeasible, "Infeasible"},
+		{pb.CpSolverStatus_MODEL_INVALID, Invalid, "Invalid"},
+	} {
+		result := Result{pb: &pb.CpSolverResponse{Status: tc.status}}
+		require.Equal(t, tc.exp, result.Status())
+		require.Equal(t, tc.str, result.Status().String())
+	}
+	require.Equal(t, "Status(42)", Status(42).String())
+
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	model.AddConstraints(NewLinearConstraint(Sum(x), NewDomain(11, 12)))
+	require.Equal(t, Infeasible, model.Solve().Status())
+}
+
+func TestMinimaxObjectives(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(3, 7, "y")
+	makespan := model.MinimizeMax(Sum(x), Sum(y), NewLinearExpr([]IntVar{x}, []int64{1}, 5))
+	require.Equal(t, "<unnamed> in [5, 15]", makespan.String())
+	e, maximize, ok := model.Objective()
+	require.True(t, ok)
+	require.False(t, maximize)
+	require.Equal(t, "<unnamed>", e.String())
+	require.Len(t, model.pb.GetConstraints(), 1)
+	require.NotNil(t, model.pb.GetConstraints()[0].GetLinMax())
+
+	least := model.MaximizeMin(Sum(x), Sum(y))
+	require.Equal(t, "<unnamed> in [0, 7]", least.String())
+	_, maximize, _ = model.Objective()
+	require.True(t, maximize)
+	require.NotNil(t, model.pb.GetConstraints()[1].GetLinMin())
+
+	require.PanicsWithValue(t, "minimize-max objective: no expressions", func() {
+		model.MinimizeMax()
+	})
+}
+
+// TestDecompositions solves models using constraints (and objectives) that are
+// decomposed into simpler ones, checking the decompositions against known
+// optimal objective values.
+func TestDecompositions(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		build     func(model *Model)
+		objective float64
+	}{
+		{
+			name: "nvalue",
+			build: func(model *Model) {
+				a, b := model.NewIntVar(1, 3, "a"), model.NewIntVar(2, 4, "b")
+				c := model.NewIntVarFromDomain(NewDomain(1, 1, 4, 4), "c")
+				count := model.NewIntVar(0, 10, "count")
+				model.AddConstraints(NewNValueConstraint(count, a, b, c))
+				model.Minimize(Sum(count))
+			},
+			objective: 2,
+		},
+		{
+			// The alternative closest to the previous solution that differs
+			// from it in at least two positions, while using all values.
+			name: "hamming-distance",
+			build: func(model *Model) {
+				var previous, current []IntVar
+				for i, value := range []int64{3, 1, 2} {
+					previous = append(previous, model.NewConstant(value, fmt.Sprintf("p%d", i)))
+					current = append(current, model.NewIntVar(1, 3, fmt.Sprintf("c%d", i)))
+				}
+				distance := model.NewIntVar(2, 3, "distance")
+				model.AddConstraints(
+					NewHammingDistanceConstraint(distance, previous, current),
+					NewAllDifferentConstraint(current...),
+				)
+				model.Minimize(Sum(distance))
+			},
+			objective: 2,
+		},
+		{
+			// The optional task can't fit within the capacity.
+			name: "energy",
+			build: func(model *Model) {
+				var intervals []Interval
+				var demands []IntVar
+				for i, task := range []struct{ size, demand int64 }{{2, 2}, {3, 1}, {4, 2}} {
+					start := model.NewIntVar(0, 10, fmt.Sprintf("s%d", i))
+					intervals = append(intervals, model.NewFixedSizeInterval(start, task.size, fmt.Sprintf("t%d", i)))
+					demands = append(demands, model.NewConstant(task.demand, fmt.Sprintf("d%d", i)))
+				}
+				present := model.NewLiteral("present")
+				intervals[2].OnlyEnforceIf(present)
+				horizon := model.NewIntVar(0, 6, "horizon")
+				model.AddConstraints(model.NewEnergyConstraint(intervals, demands,
+					NewLinearExpr([]IntVar{horizon}, []int64{2}, 0)))
+				model.Maximize(Sum(present))
+			},
+			objective: 0,
+		},
+		{
+			name: "value-literals",
+			build: func(model *Model) {
+				x := model.NewIntVarFromDomain(NewDomain(1, 2, 5, 5), "x")
+				lits := model.NewValueLiterals(x)
+				model.AddConstraints(NewBooleanOrConstraint(lits[1].Not()))
+				model.Maximize(NewLinearExpr([]IntVar{lits[0], lits[1]}, []int64{1, 2}, 0))
+			},
+			objective: 1,
+		},
+		{
+			// x wants to be at least 8 and at most 3; violating the latter is
+			// cheaper.
+			name: "soft",
+			build: func(model *Model) {
+				x := model.NewIntVar(0, 10, "x")
+				model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(8, 10)), 2)
+				model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(0, 3)), 5)
+				model.Maximize(Sum(x))
+			},
+			objective: 10 - 5,
+		},
+		{
+			name: "reified",
+			build: func(model *Model) {
+				x := model.NewIntVar(0, 10, "x")
+				l := model.NewLiteral("l")
+				model.AddConstraints(
+					Reify(NewLinearConstraint(Sum(x), NewDomain(2, 4)), l),
+					NewBooleanOrConstraint(l),
+				)
+				model.Minimize(Sum(x))
+			},
+			objective: 2,
+		},
+		{
+			name: "domain-literal",
+			build: func(model *Model) {
+				x := model.NewIntVar(0, 10, "x")
+				in := model.NewDomainLiteral(x, NewDomain(2, 4, 8, 9))
+				model.AddConstraints(NewBooleanOrConstraint(in))
+				model.Maximize(Sum(x))
+			},
+			objective: 9,
+		},
+		{
+			name: "conflict-graph",
+			build: func(model *Model) {
+				a, b, c, d := model.NewLiteral("a"), model.NewLiteral("b"), model.NewLiteral("c"), model.NewLiteral("d")
+				model.AddConflictGraph([][2]Literal{{a, b}, {b, c}, {c, d}, {a, c}})
+				model.Maximize(Sum(a, b, c, d))
+			},
+			objective: 2,
+		},
+		{
+			// Each day, shifts can't exceed the limit unless working overtime,
+			// which isn't allowed.
+			name: "template",
+			build: func(model *Model) {
+				limit := model.NewConstant(8, "limit")
+				day := NewTemplate("day", []Domain{NewDomain(0, 10), NewDomain(0, 10), NewDomain(0, 1)},
+					func(params []IntVar) []Constraint {
+						early, late, overtime := params[0], params[1], params[2].(Literal)
+						return []Constraint{
+							NewLessOrEqualConstraint(Sum(early, late), Sum(limit)).OnlyEnforceIf(overtime.Not()),
+							NewBooleanOrConstraint(overtime.Not()),
+						}
+					})
+				var lates []IntVar
+				for i := 0; i < 3; i++ {
+					early := model.NewIntVar(0, 10, fmt.Sprintf("early%d", i))
+					late := model.NewIntVar(0, 10, fmt.Sprintf("late%d", i))
+					model.AddConstraints(day.Instantiate(early, late, model.NewLiteral(fmt.Sprintf("overtime%d", i))))
+					lates = append(lates, late)
+				}
+				model.Maximize(Sum(lates...))
+			},
+			objective: 3 * 8,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			model := NewModel(tc.name)
+			tc.build(model)
+			result, err := SolveAndVerify(model)
+			require.NoError(t, err)
+			require.True(t, result.Optimal())
+			require.Equal(t, tc.objective, result.ObjectiveValue())
+		})
+	}
+}
+
+func TestNValue(t *testing.T) {
+	model := NewModel("")
+	a, b := model.NewIntVar(1, 3, "a"), model.NewIntVar(2, 4, "b")
+	c := model.NewIntVarFromDomain(NewDomain(1, 1, 4, 4), "c")
+	count := model.NewIntVar(0, 10, "count")
+
+	nvalue := NewNValueConstraint(count, a, b, c)
+	require.Equal(t, "nvalue: count == |{a, b, c}|", nvalue.String())
+	var names []string
+	for _, l := range model.literals[len(model.literals)-4:] {
+		names = append(names, l.String())
+	}
+	require.Equal(t, []string{"count.used1", "count.used2", "count.used3", "count.used4"}, names)
+
+	// Without variables, no values are taken on.
+	protos := NewNValueConstraint(count).protos()
+	require.Len(t, protos, 1)
+	require.Equal(t, []int32{count.index()}, protos[0].GetLinear().GetVars())
+	require.Equal(t, []int64{0, 0}, protos[0].GetLinear().GetDomain())
+
+	// Variables taking on the same value share the literal for it.
+	five := model.NewConstant(5, "five")
+	before := len(model.literals)
+	NewNValueConstraint(count, five, five)
+	require.Len(t, model.literals, before+3)
+	require.Equal(t, "count.used5", model.literals[len(model.literals)-1].String())
+}
+
+func TestNValueDomainsTooLarge(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	count := model.NewIntVar(0, 10, "count")
+	huge := model.NewIntVar(0, math.MaxInt64, "huge")
+	a, b := model.NewIntVar(0, 2047, "a"), model.NewIntVar(0, 2048, "b")
+	NewNValueConstraint(count, huge)
+	NewNValueConstraint(count, a, b) // collectively too large
+	require.EqualError(t, model.Err(), "2 construction errors: "+
+		"nvalue: count == |{huge}|: domains too large (more than 4096 values); "+
+		"nvalue: count == |{a, b}|: domains too large (more than 4096 values)")
+	require.Empty(t, model.literals)
+}
+
+func TestHammingDistance(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x, y, z := model.NewIntVar(0, 3, "x"), model.NewIntVar(0, 3, "y"), model.NewIntVar(0, 3, "z")
+	distance := model.NewIntVar(0, 3, "distance")
+
+	hamming := NewHammingDistanceConstraint(distance, []IntVar{x, y}, []IntVar{z, x})
+	require.Equal(t, "hamming-distance: distance == |x, y ≠ z, x|", hamming.String())
+	require.Equal(t, "distance.differs1", model.literals[len(model.literals)-1].String())
+
+	// Empty sequences don't differ anywhere.
+	protos := NewHammingDistanceConstraint(distance, nil, nil).protos()
+	require.Len(t, protos, 1)
+	require.Equal(t, []int64{0, 0}, protos[0].GetLinear().GetDomain())
+
+	// Literals for unnamed targets are left unnamed.
+	NewHammingDistanceConstraint(model.NewIntVar(0, 1, ""), []IntVar{x}, []IntVar{y})
+	require.Equal(t, "<unnamed>", model.literals[len(model.literals)-1].String())
+
+	NewHammingDistanceConstraint(distance, []IntVar{x, y}, []IntVar{z})
+	require.EqualError(t, model.Err(), "hamming-distance: mismatched lengths of a (2: x, y) and b (1: z)")
+}
+
+func TestMinGap(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	start := model.NewIntVar(0, 10, "s")
+	size := model.NewIntVar(1, 3, "size")
+	itv := model.NewInterval(start, model.NewIntVar(0, 13, "e"), size, "i")
+	fixed := model.NewFixedSizeInterval(model.NewIntVar(0, 10, "t"), 2, "j")
+
+	model.NewMinGapConstraint([]Interval{itv, fixed}, -1)
+	require.EqualError(t, model.Err(), "min-gap (i, j): negative gap (-1)")
+
+	// Without a gap, no shadow intervals are needed.
+	require.Equal(t, "non-overlapping: {s, e}, {t, j.end}",
+		model.NewMinGapConstraint([]Interval{itv, fixed}, 0).String())
+	require.Len(t, model.intervals, 2)
+
+	// Shadows of variable-size intervals are sized relative to them, and
+	// those of optional ones are only present alongside them.
+	present := model.NewLiteral("present")
+	itv.OnlyEnforceIf(present)
+	gap := model.NewMinGapConstraint([]Interval{itv}, 2)
+	require.Equal(t, "min-gap: i | 2", gap.String())
+	shadow := model.intervals[len(model.intervals)-1]
+	require.Equal(t, "[s, i.shadow.end | i.shadow.size] if [present]", shadow.String())
+	_, shadowEnd, shadowSize := shadow.Parameters()
+	require.Equal(t, "i.shadow.end in [2, 15]", shadowEnd.String())
+	require.Equal(t, "i.shadow.size in [3, 5]", shadowSize.String())
+	protos := gap.protos()
+	require.Len(t, protos, 2)
+	require.Equal(t, []int32{shadowSize.index(), size.index()}, protos[0].GetLinear().GetVars())
+	require.Equal(t, []int64{2, 2}, protos[0].GetLinear().GetDomain())
+}
+
+func TestEnergy(t *testing.T) {
+	model := NewModel("m")
+	var intervals []Interval
+	var demands []IntVar
+	for i, task := range []struct{ size, demand int64 }{{2, 2}, {3, 1}, {4, 2}} {
+		start := model.NewIntVar(0, 10, fmt.Sprintf("s%d", i))
+		intervals = append(intervals, model.NewFixedSizeInterval(start, task.size, fmt.Sprintf("t%d", i)))
+		demands = append(demands, model.NewConstant(task.demand, fmt.Sprintf("d%d", i)))
+	}
+	intervals[2].OnlyEnforceIf(model.NewLiteral("present"))
+
+	horizon := model.NewIntVar(0, 6, "horizon")
+	capacity := NewLinearExpr([]IntVar{horizon}, []int64{2}, 0)
+	energy := model.NewEnergyConstraint(intervals, demands, capacity)
+	require.Equal(t, "energy: t0: d0, t1: d1, t2: d2 | 2horizon", energy.String())
+
+	protos := energy.protos()
+	require.Len(t, protos, 5) // the optional task's energy is a product of three
+	require.Equal(t, []int64{0, 12 - (4 + 3)}, protos[4].GetLinear().GetDomain())
+	for _, v := range model.vars[len(model.vars)-4:] {
+		require.Contains(t, []string{
+			"t0.energy in [4, 4]", "t1.energy in [3, 3]", "t2.energy in [0, 8]", "t2.energy.partial1 in [8, 8]",
+		}, v.String())
+	}
+
+	// Without intervals, only the capacity is constrained.
+	protos = model.NewEnergyConstraint(nil, nil, capacity).protos()
+	require.Len(t, protos, 1)
+	require.Equal(t, []int64{0, 12}, protos[0].GetLinear().GetDomain())
+
+	require.PanicsWithValue(t,
+		"model m: energy: mismatched lengths of intervals (0: ) and demands (1: d0)",
+		func() { model.NewEnergyConstraint(nil, demands[:1], capacity) })
+	huge := model.NewIntVar(0, math.MaxInt64, "huge")
+	require.PanicsWithValue(t,
+		"model m: energy: possible integer overflow (t0: t0.size * huge)",
+		func() { model.NewEnergyConstraint(intervals[:1], []IntVar{huge}, capacity) })
+}
+
+func TestValueLiterals(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x := model.NewIntVarFromDomain(NewDomain(1, 2, 5, 5), "x")
+	lits := model.NewValueLiterals(x)
+	require.Len(t, lits, 3) // values in holes aren't included
+	require.Equal(t, "x.is5", lits[2].name())
+	require.Len(t, model.pb.GetConstraints(), 3*2+1)
+	require.Equal(t, []int64{2, 2}, model.pb.GetConstraints()[2].GetLinear().GetDomain())
+
+	// Constants have a single value, which they take on.
+	lits = model.NewValueLiterals(model.NewConstant(7, "c"))
+	require.Len(t, lits, 1)
+	require.Equal(t, "c.is7", lits[0].name())
+
+	require.Nil(t, model.NewValueLiterals(model.NewIntVar(0, 1<<20, "y")))
+	require.EqualError(t, model.Err(), "value-literals: y: domain too large (more than 4096 values)")
+}
+
+func TestSoftConstraints(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	atLeast := model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(8, 10)), 2)
+	atMost := model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(0, 3)), 5)
+	require.Len(t, model.pb.GetConstraints(), 2)
+	require.Equal(t, []int32{atLeast.Not().index()}, model.pb.GetConstraints()[0].GetEnforcementLiteral())
+
+	// Without an objective, the total penalty is minimized.
+	model.finalize()
+	require.Equal(t, []int32{atLeast.index(), atMost.index()}, model.pb.GetObjective().GetVars())
+	require.Equal(t, []int64{2, 5}, model.pb.GetObjective().GetCoeffs())
+
+	// Minimization objectives are penalized.
+	model.Minimize(NewLinearExpr([]IntVar{x}, []int64{3}, 0))
+	model.finalize()
+	require.Equal(t, []int64{3, 2, 5}, model.pb.GetObjective().GetCoeffs())
+	require.Equal(t, float64(0), model.pb.GetObjective().GetScalingFactor())
+
+	// Maximization objectives are discounted by the penalties; the objective
+	// as set is left as is.
+	model.Maximize(Sum(x))
+	model.finalize()
+	require.Equal(t, []int32{x.index(), atLeast.index(), atMost.index()}, model.pb.GetObjective().GetVars())
+	require.Equal(t, []int64{-1, 2, 5}, model.pb.GetObjective().GetCoeffs())
+	require.Equal(t, float64(-1), model.pb.GetObjective().GetScalingFactor())
+	e, maximize, ok := model.Objective()
+	require.True(t, ok && maximize)
+	require.Equal(t, "x", e.String())
+
+	model.finalize() // idempotent
+	require.Equal(t, []int64{-1, 2, 5}, model.pb.GetObjective().GetCoeffs())
+}
+
+func TestSoftConstraintPenalties(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x := model.NewIntVar(0, 10, "x")
+
+	// Free violations are allowed, if pointless.
+	require.NotNil(t, model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(0, 5)), 0))
+	require.Nil(t, model.AddSoftConstraint(NewLinearConstraint(Sum(x), NewDomain(8, 10)), -1))
+	require.EqualError(t, model.Err(), "soft constraint linear-constraint: x in [8, 10]: negative penalty -1")
+	require.Len(t, model.pb.GetConstraints(), 1)
+}
+
+func TestReify(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	a, b := model.NewLiteral("a"), model.NewLiteral("b")
+	l := model.NewLiteral("l")
+
+	c := Reify(NewLinearConstraint(Sum(x), NewDomain(2, 4)), l)
+	require.Equal(t, "reified: l ⇔ linear-constraint: x in [2, 4]", c.String())
+	protos := c.protos()
+	require.Len(t, protos, 2)
+	require.Equal(t, []int32{l.index()}, protos[0].GetEnforcementLiteral())
+	require.Equal(t, []int32{l.Not().index()}, protos[1].GetEnforcementLiteral())
+	require.Equal(t, []int64{math.MinInt64, 1, 5, math.MaxInt64}, protos[1].GetLinear().GetDomain())
+
+	c = Reify(NewBooleanAndConstraint(a, b), l)
+	protos = c.protos()
+	require.Equal(t, []int32{a.Not().index(), b.Not().index()}, protos[1].GetBoolOr().GetLiterals())
+
+	c = Reify(NewAtMostKConstraint(1, a, b), l)
+	require.Equal(t, []int64{math.MinInt64, -1, 2, math.MaxInt64}, c.protos()[1].GetLinear().GetDomain())
+}
+
+func TestReifyUnsupported(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	x, y := model.NewIntVar(0, 10, "x"), model.NewIntVar(0, 10, "y")
+	l := model.NewLiteral("l")
+
+	Reify(NewAllDifferentConstraint(x, y), l)
+	Reify(NewAllSameConstraint(x, y), l)
+	Reify(NewLinearConstraint(Sum(x), NewDomain(0, 5)).OnlyEnforceIf(l), l)
+	require.EqualError(t, model.Err(), "3 construction errors: "+
+		"reified: l ⇔ all-different: x, y: constraint can't be negated; "+
+		"reified: l ⇔ all-same: x, y: unsupported constraint; "+
+		"reified: l ⇔ linear-constraint: x in [0, 5] if (l): constraint already enforced")
+}
+
+func TestDomainLiteral(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	in := model.NewDomainLiteral(x, NewDomain(2, 4, 8, 9))
+	require.Equal(t, "x.inDomain", in.name())
+
+	protos := model.pb.GetConstraints()
+	require.Len(t, protos, 2)
+	require.Equal(t, []int32{in.index()}, protos[0].GetEnforcementLiteral())
+	require.Equal(t, []int64{2, 4, 8, 9}, protos[0].GetLinear().GetDomain())
+	require.Equal(t, []int32{in.Not().index()}, protos[1].GetEnforcementLiteral())
+	require.Equal(t, []int64{math.MinInt64, 1, 5, 7, 10, math.MaxInt64}, protos[1].GetLinear().GetDomain())
+}
+
+func TestConflictGraph(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	a, b, c, d := model.NewLiteral("a"), model.NewLiteral("b"), model.NewLiteral("c"), model.NewLiteral("d")
+
+	// A triangle (a, b, c) with a pendant edge (c, d) is covered using two
+	// cliques, instead of four pairwise constraints.
+	cs := model.AddConflictGraph([][2]Literal{{a, b}, {b, c}, {c, d}, {a, c}})
+	require.Len(t, cs, 2)
+	require.Equal(t, "at-most-k: a, b, c | 1", cs[0].String())
+	require.Equal(t, "at-most-k: c, d | 1", cs[1].String())
+	require.Len(t, model.pb.GetConstraints(), 2)
+
+	// Repeated edges, in either direction, are covered once.
+	cs = model.AddConflictGraph([][2]Literal{{a, d}, {d, a}, {a, d}})
+	require.Len(t, cs, 1)
+	require.Equal(t, "at-most-k: a, d | 1", cs[0].String())
+
+	// Self-loops are rejected, though literals may conflict with negations.
+	cs = model.AddConflictGraph([][2]Literal{{a, a}, {a, b.Not()}})
+	require.Len(t, cs, 1)
+	require.Equal(t, "at-most-k: a, ~b | 1", cs[0].String())
+	require.EqualError(t, model.Err(), "conflict graph: self-loop on a")
+	require.Empty(t, model.AddConflictGraph(nil))
+}
+
+func TestTemplate(t *testing.T) {
+	model := NewModel("", WithConstructionErrors())
+	limit := model.NewConstant(8, "limit")
+	day := NewTemplate("day", []Domain{NewDomain(0, 10), NewDomain(0, 10), NewDomain(0, 1)},
+		func(params []IntVar) []Constraint {
+			early, late, overtime := params[0], params[1], params[2].(Literal)
+			return []Constraint{
+				NewLessOrEqualConstraint(Sum(early, late), Sum(limit)).OnlyEnforceIf(overtime.Not()),
+				NewBooleanOrConstraint(overtime.Not()),
+			}
+		})
+	require.Equal(t, "template day($0, $1, $2)", day.String())
+
+	for i := 0; i < 2; i++ {
+		early := model.NewIntVar(0, 10, fmt.Sprintf("early%d", i))
+		late := model.NewIntVar(0, 10, fmt.Sprintf("late%d", i))
+		overtime := model.NewLiteral(fmt.Sprintf("overtime%d", i))
+		c := day.Instantiate(early, late, overtime)
+		require.Equal(t, fmt.Sprintf("day(early%d, late%d, overtime%d)", i, i, i), c.String())
+
+		protos := c.protos()
+		require.Len(t, protos, 2)
+		require.Equal(t, []int32{overtime.Not().index()}, protos[0].GetEnforcementLiteral())
+		require.Equal(t, []int32{early.index(), late.index(), limit.index()}, protos[0].GetLinear().GetVars())
+		require.Equal(t, []int32{overtime.Not().index()}, protos[1].GetBoolOr().GetLiterals())
+	}
+	require.Equal(t, []int32{-placeholderBase - 3}, day.protos[1].GetBoolOr().GetLiterals()) // untouched
+
+	x := model.NewIntVar(0, 10, "x")
+	day.Instantiate(x)
+	require.EqualError(t, model.Err(), "template day(x): mismatched number of placeholders (3) and variables (1)")
+}
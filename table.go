@@ -0,0 +1,136 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Wildcard can be used in place of a value in the assignments given to
+// NewAllowedAssignmentsConstraint and NewForbiddenAssignmentsConstraint, to
+// indicate that the corresponding variable can take on any value ("don't
+// care"). Tables with wildcards are encoded without enumerating the values
+// they stand for. Allowed assignments with wildcards instantiate a literal per
+// row, in the model the variables were instantiated in.
+const Wildcard int64 = math.MinInt64
+
+// hasWildcards returns whether any of the given assignments has a wildcard.
+func hasWildcards(assignments [][]int64) bool {
+	for _, assignment := range assignments {
+		for _, value := range assignment {
+			if value == Wildcard {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newWildcardAssignmentsConstraint encodes a table constraint with wildcard
+// entries.
+//
+// For allowed assignments, each row gets a literal that (when true) forces the
+// row's non-wildcard columns to their listed values; at least one row literal
+// needs to be true. For forbidden assignments, wildcard columns play no part
+// in excluding a row, so rows are grouped by their non-wildcard columns and
+// forbidden over just those variables.
+func newWildcardAssignmentsConstraint(vars []IntVar, assignments [][]int64, negated bool) Constraint {
+	kind := "allowed-assignments"
+	if negated {
+		kind = "forbidden-assignments"
+	}
+	str := fmt.Sprintf("%s: %s | %s", kind, intVarList(vars).names(), printAssignments(assignments))
+
+	var cs []Constraint
+	if !negated {
+		m := modelFor(vars...)
+		if m == nil {
+			// Without a model to instantiate the row literals in, we can't
+			// encode wildcards.
+			return invalidConstraint(vars, "%s: variables not instantiated in a model", str)
+		}
+		var rows []Literal
+		for _, assignment := range assignments {
+			row := m.newAuxLiteral("")
+			for j, value := range assignment {
+				if value == Wildcard {
+					continue
+				}
+				cs = append(cs, NewLinearConstraint(Sum(vars[j]), NewDomain(value, value)).OnlyEnforceIf(row))
+			}
+			rows = append(rows, row)
+		}
+		cs = append(cs, NewBooleanOrConstraint(rows...))
+		return constraints{cs: cs, str: str}
+	}
+
+	var masks []string // ordered for determinism
+	groups := make(map[string][][]int64)
+	columns := make(map[string][]int)
+	for _, assignment := range assignments {
+		var mask strings.Builder
+		var projected []int64
+		var cols []int
+		for j, value := range assignment {
+			if value == Wildcard {
+				mask.WriteByte('*')
+				continue
+			}
+			mask.WriteByte('.')
+			projected = append(projected, value)
+			cols = append(cols, j)
+		}
+		key := mask.String()
+		if _, ok := groups[key]; !ok {
+			masks = append(masks, key)
+			columns[key] = cols
+		}
+		groups[key] = append(groups[key], projected)
+	}
+	for _, key := range masks {
+		if len(columns[key]) == 0 {
+			// A row made up entirely of wildcards forbids every assignment;
+			// we're infeasible regardless.
+			cs = append(cs, NewLinearConstraint(Sum(), NewDomain(1, 1)))
+			continue
+		}
+		var projected []IntVar
+		for _, j := range columns[key] {
+			projected = append(projected, vars[j])
+		}
+		cs = append(cs, newAssignmentsConstraintInternal(projected, groups[key], true))
+	}
+	return constraints{cs: cs, str: str}
+}
+
+// printAssignments prints out the given assignments, rendering wildcards as
+// "*".
+func printAssignments(assignments [][]int64) string {
+	var rows []string
+	for _, assignment := range assignments {
+		var values []string
+		for _, value := range assignment {
+			if value == Wildcard {
+				values = append(values, "*")
+			} else {
+				values = append(values, fmt.Sprintf("%d", value))
+			}
+		}
+		rows = append(rows, fmt.Sprintf("[%s]", strings.Join(values, ", ")))
+	}
+	return strings.Join(rows, ", ")
+}
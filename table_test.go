@@ -0,0 +1,97 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWildcardAssignments(t *testing.T) {
+	model := NewModel("")
+	x, y, z := model.NewIntVar(0, 5, "x"), model.NewIntVar(0, 5, "y"), model.NewIntVar(0, 5, "z")
+	vars := []IntVar{x, y, z}
+
+	// Tables without wildcards are left as is.
+	require.NotNil(t, NewAllowedAssignmentsConstraint(vars, [][]int64{{0, 1, 2}}).protos()[0].GetTable())
+
+	allowed := NewAllowedAssignmentsConstraint(vars, [][]int64{
+		{0, Wildcard, 2},
+		{Wildcard, Wildcard, 5},
+	})
+	require.Equal(t, "allowed-assignments: x, y, z | [0, *, 2], [*, *, 5]", allowed.String())
+	protos := allowed.protos()
+	require.Len(t, protos, 4) // two linear constraints for the first row, one for the second, and the disjunction
+	require.Equal(t, []int32{3}, protos[0].GetEnforcementLiteral())
+	require.Equal(t, []int64{0, 0}, protos[0].GetLinear().GetDomain())
+	require.Equal(t, []int32{4}, protos[2].GetEnforcementLiteral())
+	require.Equal(t, []int32{z.index()}, protos[2].GetLinear().GetVars())
+	require.Equal(t, []int32{3, 4}, protos[3].GetBoolOr().GetLiterals())
+
+	forbidden := NewForbiddenAssignmentsConstraint(vars, [][]int64{
+		{0, Wildcard, 2},
+		{1, Wildcard, 3},
+		{Wildcard, 4, Wildcard},
+	})
+	require.Equal(t, "forbidden-assignments: x, y, z | [0, *, 2], [1, *, 3], [*, 4, *]", forbidden.String())
+	protos = forbidden.protos()
+	require.Len(t, protos, 2)
+	require.Equal(t, []int32{x.index(), z.index()}, protos[0].GetTable().GetVars())
+	require.Equal(t, []int64{0, 2, 1, 3}, protos[0].GetTable().GetValues())
+	require.True(t, protos[0].GetTable().GetNegated())
+	require.Equal(t, []int32{y.index()}, protos[1].GetTable().GetVars())
+	require.Equal(t, []int64{4}, protos[1].GetTable().GetValues())
+
+	require.Equal(t, []int64{1, 1}, NewForbiddenAssignmentsConstraint(vars, [][]int64{
+		{Wildcard, Wildcard, Wildcard},
+	}).protos()[0].GetLinear().GetDomain())
+}
+
+func TestWildcardAssignmentsWithoutModel(t *testing.T) {
+	// Variables not tied to a model have nowhere to instantiate row literals
+	// in.
+	vars := []IntVar{
+		newIntVar(NewDomain(0, 5), 0, false, false, "x"),
+		newIntVar(NewDomain(0, 5), 1, false, false, "y"),
+	}
+	require.PanicsWithValue(t, "allowed-assignments: x, y | [*, 3]: variables not instantiated in a model", func() {
+		NewAllowedAssignmentsConstraint(vars, [][]int64{{Wildcard, 3}})
+	})
+
+	// Forbidden assignments don't need any.
+	forbidden := NewForbiddenAssignmentsConstraint(vars, [][]int64{{Wildcard, 3}})
+	require.Equal(t, []int64{3}, forbidden.protos()[0].GetTable().GetValues())
+}
+
+func TestWildcardAssignmentsSolve(t *testing.T) {
+	model := NewModel("")
+	x, y := model.NewIntVar(0, 5, "x"), model.NewIntVar(0, 5, "y")
+	model.AddConstraints(
+		NewAllowedAssignmentsConstraint([]IntVar{x, y}, [][]int64{
+			{Wildcard, 3},
+			{4, Wildcard},
+		}),
+		NewForbiddenAssignmentsConstraint([]IntVar{x, y}, [][]int64{
+			{Wildcard, 3},
+		}),
+	)
+	model.Maximize(Sum(y))
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(4), result.Value(x))
+	require.Equal(t, int64(5), result.Value(y))
+}
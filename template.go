@@ -0,0 +1,182 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/irfansharif/solver/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// placeholderBase is the index of the first placeholder variable in a
+// template. Placeholders aren't instantiated in any model; we use indexes well
+// past those of real variables to tell them apart from the model variables
+// templates may also refer to.
+const placeholderBase = 1 << 30
+
+// Template is a parameterized pattern of constraints, defined once over
+// placeholder variables and instantiated any number of times with different
+// variables bound to the placeholders. Instantiating it only involves copying
+// the underlying protos.
+type Template struct {
+	name   string
+	params []IntVar
+	protos []*pb.ConstraintProto
+}
+
+// NewTemplate returns a template with a placeholder for each of the given
+// domains, defined using the constraints returned by the given function over
+// the placeholders. Placeholders with domain [0, 1] can be used as literals
+// (through a type assertion). The constraints can refer to variables already
+// instantiated in a model alongside the placeholders, but they can't
+// instantiate variables of their own, and can't refer to intervals.
+func NewTemplate(name string, domains []Domain, f func(params []IntVar) []Constraint) *Template {
+	t := &Template{name: name}
+	for i, d := range domains {
+		ls := d.list(0)
+		literal := len(ls) == 2 && ls[0] == 0 && ls[1] == 1
+		t.params = append(t.params, newIntVar(d, placeholderBase+int32(i), literal, false, fmt.Sprintf("$%d", i)))
+	}
+	for _, c := range f(t.params) {
+		t.protos = append(t.protos, c.protos()...)
+	}
+	return t
+}
+
+// Instantiate returns the template's constraints with the given variables
+// bound to its placeholders, in order. The variables' domains are expected to
+// lie within those of the placeholders, which the template's constraints may
+// have been constructed to rely on. The returned constraint still needs to be
+// added to the model.
+func (t *Template) Instantiate(vars ...IntVar) Constraint {
+	str := fmt.Sprintf("%s(%s)", t.name, intVarList(vars).names())
+	if len(vars) != len(t.params) {
+		return invalidConstraint(vars, "template %s: mismatched number of placeholders (%d) and variables (%d)",
+			str, len(t.params), len(vars))
+	}
+
+	remap := func(ref int32) int32 {
+		switch {
+		case ref >= placeholderBase:
+			return vars[ref-placeholderBase].index()
+		case ref < -placeholderBase:
+			return -vars[-ref-1-placeholderBase].index() - 1
+		default:
+			return ref
+		}
+	}
+	var cs []Constraint
+	for _, ct := range t.protos {
+		ct = proto.Clone(ct).(*pb.ConstraintProto)
+		remapReferences(ct, remap)
+		cs = append(cs, &constraint{pb: ct, str: str})
+	}
+	return constraints{cs: cs, str: str}
+}
+
+// String returns a printable representation of the template.
+func (t *Template) String() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("template %s(", t.name))
+	printVars(&b, t.params...)
+	b.WriteString(")")
+	return b.String()
+}
+
+// remapReferences rewrites, in place, the variables and literals referenced by
+// the given constraint proto using the given function. It mirrors
+// referencesOf.
+func remapReferences(ct *pb.ConstraintProto, f func(ref int32) int32) {
+	refs := func(rs []int32) {
+		for i := range rs {
+			rs[i] = f(rs[i])
+		}
+	}
+	ref := func(r *int32) {
+		*r = f(*r)
+	}
+	linear := func(exprs ...*pb.LinearExpressionProto) {
+		for _, e := range exprs {
+			if e != nil {
+				refs(e.Vars)
+			}
+		}
+	}
+
+	refs(ct.EnforcementLiteral)
+	switch c := ct.GetConstraint().(type) {
+	case *pb.ConstraintProto_BoolOr:
+		refs(c.BoolOr.Literals)
+	case *pb.ConstraintProto_BoolAnd:
+		refs(c.BoolAnd.Literals)
+	case *pb.ConstraintProto_AtMostOne:
+		refs(c.AtMostOne.Literals)
+	case *pb.ConstraintProto_ExactlyOne:
+		refs(c.ExactlyOne.Literals)
+	case *pb.ConstraintProto_BoolXor:
+		refs(c.BoolXor.Literals)
+	case *pb.ConstraintProto_IntDiv:
+		ref(&c.IntDiv.Target)
+		refs(c.IntDiv.Vars)
+	case *pb.ConstraintProto_IntMod:
+		ref(&c.IntMod.Target)
+		refs(c.IntMod.Vars)
+	case *pb.ConstraintProto_IntMax:
+		ref(&c.IntMax.Target)
+		refs(c.IntMax.Vars)
+	case *pb.ConstraintProto_IntMin:
+		ref(&c.IntMin.Target)
+		refs(c.IntMin.Vars)
+	case *pb.ConstraintProto_IntProd:
+		ref(&c.IntProd.Target)
+		refs(c.IntProd.Vars)
+	case *pb.ConstraintProto_LinMax:
+		linear(append([]*pb.LinearExpressionProto{c.LinMax.Target}, c.LinMax.Exprs...)...)
+	case *pb.ConstraintProto_LinMin:
+		linear(append([]*pb.LinearExpressionProto{c.LinMin.Target}, c.LinMin.Exprs...)...)
+	case *pb.ConstraintProto_Linear:
+		refs(c.Linear.Vars)
+	case *pb.ConstraintProto_AllDiff:
+		refs(c.AllDiff.Vars)
+	case *pb.ConstraintProto_Element:
+		ref(&c.Element.Index)
+		ref(&c.Element.Target)
+		refs(c.Element.Vars)
+	case *pb.ConstraintProto_Circuit:
+		refs(c.Circuit.Literals)
+	case *pb.ConstraintProto_Routes:
+		refs(c.Routes.Literals)
+	case *pb.ConstraintProto_Table:
+		refs(c.Table.Vars)
+	case *pb.ConstraintProto_Automaton:
+		refs(c.Automaton.Vars)
+	case *pb.ConstraintProto_Inverse:
+		refs(c.Inverse.FDirect)
+		refs(c.Inverse.FInverse)
+	case *pb.ConstraintProto_Reservoir:
+		refs(c.Reservoir.Times)
+		refs(c.Reservoir.Actives)
+	case *pb.ConstraintProto_Interval:
+		ref(&c.Interval.Start)
+		ref(&c.Interval.End)
+		ref(&c.Interval.Size)
+		linear(c.Interval.StartView, c.Interval.EndView, c.Interval.SizeView)
+	case *pb.ConstraintProto_Cumulative:
+		ref(&c.Cumulative.Capacity)
+		refs(c.Cumulative.Demands)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package timeutil maps wall-clock times and durations onto the integers the
+// solver works with, for use in scheduling models. Times are expressed as the
+// number of ticks (of a configurable granularity) since an epoch.
+//
+// Times that don't fall on tick boundaries are rounded conservatively: the
+// earliest time something may start is rounded up, the latest time it may end
+// is rounded down, and durations are rounded up. Solutions therefore never
+// violate the windows and durations specified in wall-clock terms.
+package timeutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/irfansharif/solver"
+)
+
+// Timeline maps times and durations to and from ticks, the solver's unit of
+// time.
+type Timeline struct {
+	epoch       time.Time
+	granularity time.Duration
+}
+
+// NewTimeline returns a timeline counting ticks of the given granularity
+// (time.Millisecond, say) since the given epoch.
+func NewTimeline(epoch time.Time, granularity time.Duration) *Timeline {
+	if granularity <= 0 {
+		panic(fmt.Sprintf("invalid granularity: expected > 0, found %s", granularity))
+	}
+	return &Timeline{epoch: epoch, granularity: granularity}
+}
+
+// Floor returns the number of ticks since the epoch, for the latest tick at
+// or before the given time.
+func (t *Timeline) Floor(tm time.Time) int64 {
+	return floorDiv(int64(tm.Sub(t.epoch)), int64(t.granularity))
+}
+
+// Ceil returns the number of ticks since the epoch, for the earliest tick at
+// or after the given time.
+func (t *Timeline) Ceil(tm time.Time) int64 {
+	return -floorDiv(-int64(tm.Sub(t.epoch)), int64(t.granularity))
+}
+
+// Ticks returns the number of ticks making up the given duration, rounding up.
+func (t *Timeline) Ticks(d time.Duration) int64 {
+	return -floorDiv(-int64(d), int64(t.granularity))
+}
+
+// Time returns the time corresponding to the given number of ticks since the
+// epoch.
+func (t *Timeline) Time(ticks int64) time.Time {
+	return t.epoch.Add(time.Duration(ticks) * t.granularity)
+}
+
+// NewTimeVar adds a new variable to the model, one that represents a time
+// within the given (inclusive) window.
+func (t *Timeline) NewTimeVar(m *solver.Model, earliest, latest time.Time, name string) solver.IntVar {
+	return m.NewIntVar(t.Ceil(earliest), t.Floor(latest), name)
+}
+
+// NewInterval adds a new interval of the given duration to the model, one
+// that lies within the given window: it starts no earlier than the window's
+// start, and ends no later than the window's end.
+func (t *Timeline) NewInterval(m *solver.Model, windowStart, windowEnd time.Time, d time.Duration, name string) solver.Interval {
+	size := t.Ticks(d)
+	start := m.NewIntVar(t.Ceil(windowStart), t.Floor(windowEnd)-size, derivedName(name, "start"))
+	return m.NewFixedSizeInterval(start, size, name)
+}
+
+// NewFixedInterval adds a new interval to the model, one that spans the given
+// times. Since it typically captures unavailability (a machine under
+// maintenance, say), it's rounded outwards to cover the times given.
+func (t *Timeline) NewFixedInterval(m *solver.Model, start, end time.Time, name string) solver.Interval {
+	return m.NewFixedInterval(t.Floor(start), t.Ceil(end), name)
+}
+
+// Value returns the time corresponding to the decided value of the given
+// variable. This is only valid to use if the result is optimal or feasible.
+func (t *Timeline) Value(r solver.Result, iv solver.IntVar) time.Time {
+	return t.Time(r.Value(iv))
+}
+
+// floorDiv returns a/b, rounded towards negative infinity; b is positive.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// derivedName mirrors how the solver names auxiliary variables.
+func derivedName(name, suffix string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", name, suffix)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/irfansharif/solver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRounding(t *testing.T) {
+	epoch := time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)
+	tl := NewTimeline(epoch, 15*time.Minute)
+
+	for _, tc := range []struct {
+		offset      time.Duration
+		floor, ceil int64
+	}{
+		{0, 0, 0},
+		{time.Millisecond, 0, 1},
+		{15 * time.Minute, 1, 1},
+		{15*time.Minute - time.Millisecond, 0, 1},
+		{-time.Millisecond, -1, 0},
+		{-15 * time.Minute, -1, -1},
+	} {
+		tm := epoch.Add(tc.offset)
+		require.Equal(t, tc.floor, tl.Floor(tm), tc.offset)
+		require.Equal(t, tc.ceil, tl.Ceil(tm), tc.offset)
+	}
+
+	require.Equal(t, int64(2), tl.Ticks(30*time.Minute))
+	require.Equal(t, int64(3), tl.Ticks(31*time.Minute))
+	require.Equal(t, epoch.Add(45*time.Minute), tl.Time(3))
+	require.Equal(t, epoch.Add(-15*time.Minute), tl.Time(-1))
+
+	require.Panics(t, func() { NewTimeline(epoch, 0) })
+}
+
+func TestScheduling(t *testing.T) {
+	epoch := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tl := NewTimeline(epoch, time.Millisecond)
+	at := func(hour, min int) time.Time {
+		return epoch.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+
+	model := solver.NewModel("")
+	meeting := tl.NewInterval(model, at(9, 0), at(13, 0), 90*time.Minute, "meeting")
+	standup := tl.NewFixedInterval(model, at(9, 0), at(10, 30).Add(time.Nanosecond), "standup")
+	model.AddConstraints(solver.NewNonOverlappingConstraint(meeting, standup))
+
+	start, end, _ := meeting.Parameters()
+	model.Minimize(solver.Sum(start))
+
+	result := model.Solve()
+	require.True(t, result.Optimal())
+	// The fixed interval is rounded outwards, to 10:30:00.001.
+	require.Equal(t, at(10, 30).Add(time.Millisecond), tl.Value(result, start))
+	require.Equal(t, at(12, 0).Add(time.Millisecond), tl.Value(result, end))
+}
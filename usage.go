@@ -0,0 +1,91 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "sort"
+
+// Usage is the number of constraints referring to a variable.
+type Usage struct {
+	Var   IntVar
+	Count int
+}
+
+// Usages is a list of per-variable usage counts, as returned by
+// Model.UsageCounts.
+type Usages []Usage
+
+// UsageCounts returns the number of constraints referring to each of the
+// model's variables, ordered by variable index. Variables used in intervals
+// count towards constraints referring to those intervals, and a constraint
+// referring to the same variable more than once (including through its
+// negation) counts once. The objective and hints aren't constraints, and don't
+// count.
+func (m *Model) UsageCounts() Usages {
+	vars := m.variables()
+	usages := make(Usages, len(vars))
+	for i, iv := range vars {
+		usages[i].Var = iv
+	}
+
+	// Deferred constructs (resources, for example) don't introduce variables of
+	// their own, but they do refer to them.
+	constraints := m.finalized().pb.GetConstraints()
+	for _, ct := range constraints {
+		refs := referencesOf(ct)
+		idxs := append(append([]int32(nil), refs.variables...), refs.literals...)
+		for _, i := range refs.intervals {
+			irefs := referencesOf(constraints[i])
+			idxs = append(append(idxs, irefs.variables...), irefs.literals...)
+		}
+
+		seen := make(map[int32]struct{}, len(idxs))
+		for _, idx := range idxs {
+			if idx < 0 {
+				idx = -idx - 1
+			}
+			if _, ok := seen[idx]; ok {
+				continue
+			}
+			seen[idx] = struct{}{}
+			usages[idx].Count++
+		}
+	}
+	return usages
+}
+
+// Free returns the variables no constraint refers to, ignoring fixed ones
+// (constants, for example).
+func (us Usages) Free() []IntVar {
+	var free []IntVar
+	for _, u := range us {
+		if lb, ub := Bounds(u.Var); u.Count == 0 && lb != ub {
+			free = append(free, u.Var)
+		}
+	}
+	return free
+}
+
+// Hubs returns the (at most) k most used variables, in decreasing order of
+// usage. Ties are broken by variable index.
+func (us Usages) Hubs(k int) Usages {
+	hubs := append(Usages(nil), us...)
+	sort.SliceStable(hubs, func(i, j int) bool {
+		return hubs[i].Count > hubs[j].Count
+	})
+	if len(hubs) > k {
+		hubs = hubs[:k]
+	}
+	return hubs
+}
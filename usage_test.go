@@ -0,0 +1,54 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageCounts(t *testing.T) {
+	model := NewModel("")
+	a := model.NewLiteral("a")
+	b := model.NewLiteral("b")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	z := model.NewIntVar(0, 10, "z")
+	size := model.NewConstant(2, "size")
+	i := model.NewInterval(x, y, size, "i")
+	model.AddConstraints(
+		NewBooleanOrConstraint(a, a.Not()),
+		NewNonOverlappingConstraint(i),
+		NewLinearConstraint(Sum(x, y), NewDomain(0, 5)).OnlyEnforceIf(a),
+		NewAllDifferentConstraint(x, y),
+	)
+	model.Minimize(Sum(z, b))
+
+	var counts []int
+	usages := model.UsageCounts()
+	for _, u := range usages {
+		counts = append(counts, u.Count)
+	}
+	// The interval constraint and the non-overlapping constraint referring to
+	// it both count towards x, y and size.
+	require.Equal(t, []int{2, 0, 4, 4, 0, 2}, counts)
+	require.Equal(t, []IntVar{b, z}, usages.Free())
+
+	hubs := usages.Hubs(3)
+	require.Len(t, hubs, 3)
+	require.Equal(t, []IntVar{x, y, a}, []IntVar{hubs[0].Var, hubs[1].Var, hubs[2].Var})
+	require.Len(t, usages.Hubs(10), 6)
+}
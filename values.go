@@ -0,0 +1,78 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import "fmt"
+
+// maxEncodedDomainSize is the largest (collective) size of the domains of the
+// variables we're willing to encode with a literal per value, as done in
+// NewNValueConstraint and Model.NewValueLiterals.
+const maxEncodedDomainSize = 1 << 12
+
+// NewValueLiterals returns a literal per value in the given variable's domain,
+// in increasing order of values, each true iff the variable takes on that
+// value. Exactly one of them is true. It lets integer and boolean views of the
+// same decision be mixed. The domain should be small: it fails if it contains
+// more than 4096 values (see WithConstructionErrors for an alternative to
+// panicking).
+func (m *Model) NewValueLiterals(x IntVar) []Literal {
+	ls := x.domain().list(0)
+	var size uint64
+	for i := 0; i < len(ls); i += 2 {
+		span := uint64(ls[i+1]) - uint64(ls[i]) // can't overflow, unlike int64s
+		if span >= maxEncodedDomainSize || size+span+1 > maxEncodedDomainSize {
+			m.fail("value-literals: %s: domain too large (more than %d values)", x.name(), maxEncodedDomainSize)
+			return nil
+		}
+		size += span + 1
+	}
+
+	lits, cs := m.valueLiterals(x, domainValues(x.domain()))
+	m.AddConstraints(cs...)
+	return lits
+}
+
+// domainValues returns all the values in the given domain, in increasing
+// order.
+func domainValues(d Domain) []int64 {
+	var values []int64
+	ls := d.list(0)
+	for i := 0; i < len(ls); i += 2 {
+		for v := ls[i]; ; v++ {
+			values = append(values, v)
+			if v == ls[i+1] {
+				break
+			}
+		}
+	}
+	return values
+}
+
+// valueLiterals instantiates a literal per given value, indicating whether the
+// variable takes it on. It returns the literals alongside the constraints
+// channeling them with the variable, which still need to be added to the
+// model.
+func (m *Model) valueLiterals(v IntVar, values []int64) ([]Literal, []Constraint) {
+	name := protoName(v)
+	var cs []Constraint
+	var lits []Literal
+	for _, value := range values {
+		lit := m.newAuxLiteral(derivedName(name, fmt.Sprintf("is%d", value)))
+		cs = append(cs, Reify(NewLinearConstraint(Sum(v), NewDomain(value, value)), lit))
+		lits = append(lits, lit)
+	}
+	cs = append(cs, NewExactlyKConstraint(1, lits...))
+	return lits, cs
+}
@@ -0,0 +1,396 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/irfansharif/solver/pb"
+)
+
+// ErrSolutionViolatesModel is returned by SolveAndVerify when the solution
+// decided by the underlying solver doesn't actually satisfy the model.
+var ErrSolutionViolatesModel = errors.New("solution violates model")
+
+// SolveAndVerify solves the model (see Model.Solve) and, if a solution is
+// found, double checks it against the model's variables and constraints. This
+// is done independently of the underlying solver, guarding against bugs in how
+// models and solutions are mapped to and from it. If the solution is found to
+// be in violation, the returned error wraps ErrSolutionViolatesModel.
+func SolveAndVerify(m *Model, opts ...Option) (Result, error) {
+	result := m.Solve(opts...)
+	if !result.Optimal() && !result.Feasible() {
+		return result, nil
+	}
+	if err := m.verify(result.pb.GetSolution()); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// verify checks that the given solution satisfies the model.
+func (m *Model) verify(solution []int64) error {
+	variables := m.pb.GetVariables()
+	if len(solution) != len(variables) {
+		return fmt.Errorf("%w: expected %d values, got %d",
+			ErrSolutionViolatesModel, len(variables), len(solution))
+	}
+
+	v := &verifier{model: m.pb, solution: solution}
+	for i, variable := range variables {
+		if d := (&domain{intervals: variable.GetDomain()}); !d.contains(solution[i]) {
+			return fmt.Errorf("%w: %s%s = %d lies outside its domain %s",
+				ErrSolutionViolatesModel, variable.GetName(), m.variableLocation(int32(i)), solution[i], d)
+		}
+	}
+	for i, ct := range m.pb.GetConstraints() {
+		if !v.enforced(ct) {
+			continue
+		}
+		ok, err := v.satisfied(ct)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: constraint #%d%s (%s) isn't satisfied",
+				ErrSolutionViolatesModel, i, m.constraintLocation(i), constraintDescription(ct))
+		}
+	}
+	return nil
+}
+
+// verifier checks constraints against a solution.
+type verifier struct {
+	model    *pb.CpModelProto
+	solution []int64
+}
+
+// value returns the value of the variable with the given index, negating it
+// if it refers to a negated literal.
+func (v *verifier) value(ref int32) int64 {
+	if ref < 0 {
+		return 1 - v.solution[-ref-1]
+	}
+	return v.solution[ref]
+}
+
+// values returns the values of the variables with the given indexes.
+func (v *verifier) values(refs []int32) []int64 {
+	var values []int64
+	for _, ref := range refs {
+		values = append(values, v.value(ref))
+	}
+	return values
+}
+
+// linear evaluates the given linear expression.
+func (v *verifier) linear(e *pb.LinearExpressionProto) int64 {
+	sum := e.GetOffset()
+	for i, ref := range e.GetVars() {
+		sum += e.GetCoeffs()[i] * v.value(ref)
+	}
+	return sum
+}
+
+// enforced returns whether all of the constraint's enforcement literals are
+// true.
+func (v *verifier) enforced(ct *pb.ConstraintProto) bool {
+	for _, lit := range ct.GetEnforcementLiteral() {
+		if v.value(lit) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// span is the [start, end) span of an interval, if present.
+type span struct {
+	start, end int64
+	present    bool
+}
+
+// interval returns the span of the interval with the given (constraint)
+// index.
+func (v *verifier) interval(idx int32) span {
+	ct := v.model.GetConstraints()[idx]
+	itv := ct.GetInterval()
+	return span{
+		start:   v.value(itv.GetStart()),
+		end:     v.value(itv.GetEnd()),
+		present: v.enforced(ct),
+	}
+}
+
+// satisfied returns whether the given (enforced) constraint is satisfied.
+func (v *verifier) satisfied(ct *pb.ConstraintProto) (bool, error) {
+	switch ct.GetConstraint().(type) {
+	case *pb.ConstraintProto_Interval:
+		arg := ct.GetInterval()
+		size := v.value(arg.GetSize())
+		return size >= 0 && v.value(arg.GetStart())+size == v.value(arg.GetEnd()), nil
+	case *pb.ConstraintProto_AllDiff:
+		seen := make(map[int64]bool)
+		for _, value := range v.values(ct.GetAllDiff().GetVars()) {
+			if seen[value] {
+				return false, nil
+			}
+			seen[value] = true
+		}
+		return true, nil
+	case *pb.ConstraintProto_AtMostOne:
+		return sum(v.values(ct.GetAtMostOne().GetLiterals())) <= 1, nil
+	case *pb.ConstraintProto_ExactlyOne:
+		return sum(v.values(ct.GetExactlyOne().GetLiterals())) == 1, nil
+	case *pb.ConstraintProto_BoolAnd:
+		literals := ct.GetBoolAnd().GetLiterals()
+		return sum(v.values(literals)) == int64(len(literals)), nil
+	case *pb.ConstraintProto_BoolOr:
+		return sum(v.values(ct.GetBoolOr().GetLiterals())) >= 1, nil
+	case *pb.ConstraintProto_BoolXor:
+		return sum(v.values(ct.GetBoolXor().GetLiterals()))%2 == 1, nil
+	case *pb.ConstraintProto_IntDiv:
+		arg := ct.GetIntDiv()
+		operands := v.values(arg.GetVars())
+		if operands[1] == 0 {
+			return false, nil
+		}
+		return v.value(arg.GetTarget()) == operands[0]/operands[1], nil
+	case *pb.ConstraintProto_IntMod:
+		arg := ct.GetIntMod()
+		operands := v.values(arg.GetVars())
+		if operands[1] == 0 {
+			return false, nil
+		}
+		return v.value(arg.GetTarget()) == operands[0]%operands[1], nil
+	case *pb.ConstraintProto_IntProd:
+		arg := ct.GetIntProd()
+		product := int64(1)
+		for _, value := range v.values(arg.GetVars()) {
+			product *= value
+		}
+		return v.value(arg.GetTarget()) == product, nil
+	case *pb.ConstraintProto_LinMax:
+		arg := ct.GetLinMax()
+		max := v.linear(arg.GetExprs()[0])
+		for _, e := range arg.GetExprs()[1:] {
+			if value := v.linear(e); value > max {
+				max = value
+			}
+		}
+		return v.linear(arg.GetTarget()) == max, nil
+	case *pb.ConstraintProto_LinMin:
+		arg := ct.GetLinMin()
+		min := v.linear(arg.GetExprs()[0])
+		for _, e := range arg.GetExprs()[1:] {
+			if value := v.linear(e); value < min {
+				min = value
+			}
+		}
+		return v.linear(arg.GetTarget()) == min, nil
+	case *pb.ConstraintProto_Linear:
+		arg := ct.GetLinear()
+		var total int64
+		for i, value := range v.values(arg.GetVars()) {
+			total += arg.GetCoeffs()[i] * value
+		}
+		d := &domain{intervals: arg.GetDomain()}
+		return d.contains(total), nil
+	case *pb.ConstraintProto_Element:
+		arg := ct.GetElement()
+		index := v.value(arg.GetIndex())
+		if index < 0 || index >= int64(len(arg.GetVars())) {
+			return false, nil
+		}
+		return v.value(arg.GetTarget()) == v.value(arg.GetVars()[index]), nil
+	case *pb.ConstraintProto_Table:
+		arg := ct.GetTable()
+		values := v.values(arg.GetVars())
+		found := false
+		for i, n := 0, len(values); n > 0 && i+n <= len(arg.GetValues()); i += n {
+			if equal(values, arg.GetValues()[i:i+n]) {
+				found = true
+				break
+			}
+		}
+		return found != arg.GetNegated(), nil
+	case *pb.ConstraintProto_NoOverlap:
+		var spans []span
+		for _, idx := range ct.GetNoOverlap().GetIntervals() {
+			if s := v.interval(idx); s.present && s.end > s.start {
+				spans = append(spans, s)
+			}
+		}
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		for i := 1; i < len(spans); i++ {
+			if spans[i].start < spans[i-1].end {
+				return false, nil
+			}
+		}
+		return true, nil
+	case *pb.ConstraintProto_NoOverlap_2D:
+		arg := ct.GetNoOverlap_2D()
+		type box struct{ x, y span }
+		var boxes []box
+		for i, idx := range arg.GetXIntervals() {
+			b := box{x: v.interval(idx), y: v.interval(arg.GetYIntervals()[i])}
+			if !b.x.present || !b.y.present {
+				continue
+			}
+			if arg.GetBoxesWithNullAreaCanOverlap() && (b.x.end == b.x.start || b.y.end == b.y.start) {
+				continue
+			}
+			boxes = append(boxes, b)
+		}
+		for i := range boxes {
+			for j := i + 1; j < len(boxes); j++ {
+				if overlaps(boxes[i].x, boxes[j].x) && overlaps(boxes[i].y, boxes[j].y) {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	case *pb.ConstraintProto_Cumulative:
+		arg := ct.GetCumulative()
+		capacity := v.value(arg.GetCapacity())
+		var spans []span
+		var demands []int64
+		for i, idx := range arg.GetIntervals() {
+			if s := v.interval(idx); s.present && s.end > s.start {
+				spans = append(spans, s)
+				demands = append(demands, v.value(arg.GetDemands()[i]))
+			}
+		}
+		// The load only ever increases at the start of an interval, so it
+		// suffices to check there.
+		for _, s := range spans {
+			var load int64
+			for i, other := range spans {
+				if other.start <= s.start && s.start < other.end {
+					load += demands[i]
+				}
+			}
+			if load > capacity {
+				return false, nil
+			}
+		}
+		return true, nil
+	case *pb.ConstraintProto_Routes:
+		arg := ct.GetRoutes()
+		return routes(arg.GetTails(), arg.GetHeads(), v.values(arg.GetLiterals())), nil
+	case *pb.ConstraintProto_Automaton:
+		arg := ct.GetAutomaton()
+		state := arg.GetStartingState()
+		for _, value := range v.values(arg.GetVars()) {
+			next, ok := int64(0), false
+			for i, tail := range arg.GetTransitionTail() {
+				if tail == state && arg.GetTransitionLabel()[i] == value {
+					next, ok = arg.GetTransitionHead()[i], true
+					break
+				}
+			}
+			if !ok {
+				return false, nil
+			}
+			state = next
+		}
+		for _, final := range arg.GetFinalStates() {
+			if state == final {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unable to verify constraint type: %T", ct.GetConstraint())
+	}
+}
+
+// constraintDescription returns a short description of the given constraint,
+// for use in error messages.
+func constraintDescription(ct *pb.ConstraintProto) string {
+	if name := ct.GetName(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", ct.GetConstraint())
+}
+
+// overlaps returns whether the given (half-open) spans overlap.
+func overlaps(a, b span) bool {
+	return a.start < b.end && b.start < a.end
+}
+
+// routes returns whether the arcs that are present (going from tails[i] to
+// heads[i]) form routes through the depot, node 0. See NewRoutesConstraint.
+func routes(tails, heads []int32, present []int64) bool {
+	nodes := make(map[int32]bool)
+	in, out := make(map[int32]int), make(map[int32]int)
+	next := make(map[int32]int32)
+	for i := range tails {
+		tail, head := tails[i], heads[i]
+		nodes[tail], nodes[head] = true, true
+		if present[i] != 1 {
+			continue
+		}
+		if tail == 0 && head == 0 {
+			return false // self-arcs aren't allowed for the depot
+		}
+		out[tail]++
+		in[head]++
+		if tail != head {
+			next[tail] = head
+		}
+	}
+	for node := range nodes {
+		if node == 0 {
+			if in[node] != out[node] {
+				return false
+			}
+		} else if in[node] != 1 || out[node] != 1 {
+			return false
+		}
+	}
+	// With the degrees checked, it suffices to check that every node on a
+	// route leads back to the depot.
+	for node := range next {
+		for cur, steps := node, 0; cur != 0; steps++ {
+			if steps > len(nodes) {
+				return false
+			}
+			cur = next[cur]
+		}
+	}
+	return true
+}
+
+func sum(values []int64) int64 {
+	var total int64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
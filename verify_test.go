@@ -0,0 +1,166 @@
+// Copyright 2021 Irfan Sharif.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package solver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	a := model.NewLiteral("a")
+	model.AddConstraints(
+		NewAllDifferentConstraint(x, y),
+		NewLinearConstraint(Sum(x, y), NewDomain(5, 5)).OnlyEnforceIf(a),
+		NewModuloConstraint(model.NewConstant(1, "one"), x, model.NewConstant(3, "three")),
+	)
+
+	for _, tc := range []struct {
+		solution []int64 // x, y, a, one, three
+		err      string
+	}{
+		{solution: []int64{1, 4, 1, 1, 3}},
+		{solution: []int64{4, 6, 0, 1, 3}},
+		{solution: []int64{4, 4, 0, 1, 3}, err: "solution violates model: constraint #0 (*pb.ConstraintProto_AllDiff) isn't satisfied"},
+		{solution: []int64{4, 6, 1, 1, 3}, err: "solution violates model: constraint #1 (*pb.ConstraintProto_Linear) isn't satisfied"},
+		{solution: []int64{3, 6, 0, 1, 3}, err: "solution violates model: constraint #2 (*pb.ConstraintProto_IntMod) isn't satisfied"},
+		{solution: []int64{1, 11, 0, 1, 3}, err: "solution violates model: y = 11 lies outside its domain [0, 10]"},
+		{solution: []int64{1, 4}, err: "solution violates model: expected 5 values, got 2"},
+	} {
+		err := model.verify(tc.solution)
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+		require.True(t, errors.Is(err, ErrSolutionViolatesModel))
+	}
+}
+
+func TestVerifyIntervals(t *testing.T) {
+	model := NewModel("")
+	s1, e1 := model.NewIntVar(0, 10, "s1"), model.NewIntVar(0, 10, "e1")
+	s2, e2 := model.NewIntVar(0, 10, "s2"), model.NewIntVar(0, 10, "e2")
+	size := model.NewConstant(3, "size")
+	a := model.NewLiteral("a")
+	i1 := model.NewInterval(s1, e1, size, "i1")
+	i2 := model.NewInterval(s2, e2, size, "i2")
+	i2.OnlyEnforceIf(a)
+	model.AddConstraints(
+		NewNonOverlappingConstraint(i1, i2),
+		NewCumulativeConstraint(size, []Interval{i1, i2}, []IntVar{size, size}),
+	)
+
+	for _, tc := range []struct {
+		solution []int64 // s1, e1, s2, e2, size, a
+		err      string
+	}{
+		{solution: []int64{0, 3, 3, 6, 3, 1}},
+		{solution: []int64{0, 3, 1, 4, 3, 0}}, // i2 isn't present
+		{solution: []int64{0, 3, 1, 4, 3, 1}, err: "solution violates model: constraint #2 (*pb.ConstraintProto_NoOverlap) isn't satisfied"},
+		{solution: []int64{0, 4, 5, 8, 3, 1}, err: "solution violates model: constraint #0 (i1) isn't satisfied"},
+	} {
+		err := model.verify(tc.solution)
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestVerifyRoutes(t *testing.T) {
+	model := NewModel("")
+	var literals []Literal
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		literals = append(literals, model.NewLiteral(name))
+	}
+	// Arcs between the depot (0) and nodes 1 and 2, with a self-arc to skip
+	// node 2.
+	tails := []int{0, 1, 0, 2, 1, 2, 2}
+	heads := []int{1, 0, 2, 0, 2, 1, 2}
+	model.AddConstraints(NewRoutesConstraint(tails, heads, literals))
+
+	for _, tc := range []struct {
+		solution []int64 // a, b, c, d, e, f, g
+		ok       bool
+	}{
+		{solution: []int64{1, 0, 0, 1, 1, 0, 0}, ok: true}, // 0 → 1 → 2 → 0
+		{solution: []int64{1, 1, 1, 1, 0, 0, 0}, ok: true}, // 0 → 1 → 0, 0 → 2 → 0
+		{solution: []int64{1, 1, 0, 0, 0, 0, 1}, ok: true}, // 0 → 1 → 0, 2 skipped
+		{solution: []int64{1, 1, 0, 0, 0, 0, 0}},           // 2 isn't visited
+		{solution: []int64{0, 0, 0, 0, 1, 1, 0}},           // 1 → 2 → 1, bypassing the depot
+	} {
+		err := model.verify(tc.solution)
+		if tc.ok {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, "solution violates model: constraint #0 (*pb.ConstraintProto_Routes) isn't satisfied")
+	}
+}
+
+func TestVerifyAutomaton(t *testing.T) {
+	model := NewModel("")
+	a, b, c := model.NewIntVar(0, 2, "a"), model.NewIntVar(0, 2, "b"), model.NewIntVar(0, 2, "c")
+	// Accepts sequences that end in 2, with 1s never following 2s.
+	transitions := []Transition{
+		{Tail: 0, Head: 0, Label: 0},
+		{Tail: 0, Head: 0, Label: 1},
+		{Tail: 0, Head: 1, Label: 2},
+		{Tail: 1, Head: 0, Label: 0},
+		{Tail: 1, Head: 1, Label: 2},
+	}
+	model.AddConstraints(NewAutomatonConstraint([]IntVar{a, b, c}, 0, []int64{1}, transitions))
+
+	for _, tc := range []struct {
+		solution []int64 // a, b, c
+		ok       bool
+	}{
+		{solution: []int64{0, 1, 2}, ok: true},
+		{solution: []int64{2, 0, 2}, ok: true},
+		{solution: []int64{2, 2, 2}, ok: true},
+		{solution: []int64{0, 2, 0}}, // doesn't end in a final state
+		{solution: []int64{2, 1, 2}}, // no transition for 1 after 2
+	} {
+		err := model.verify(tc.solution)
+		if tc.ok {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, "solution violates model: constraint #0 (*pb.ConstraintProto_Automaton) isn't satisfied")
+	}
+}
+
+func TestSolveAndVerify(t *testing.T) {
+	model := NewModel("")
+	x := model.NewIntVar(0, 10, "x")
+	y := model.NewIntVar(0, 10, "y")
+	model.AddConstraints(
+		NewAllDifferentConstraint(x, y),
+		NewLinearConstraint(Sum(x, y), NewDomain(7, 7)),
+	)
+	model.Maximize(Sum(x))
+
+	result, err := SolveAndVerify(model)
+	require.NoError(t, err)
+	require.True(t, result.Optimal())
+	require.Equal(t, int64(7), result.Value(x))
+}